@@ -0,0 +1,41 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jucardi/go-titan/configx"
+)
+
+// RemoteConfigCheckName is the name of the check registered by `RegisterRemoteConfig`
+const RemoteConfigCheckName = "remote-config"
+
+// RegisterRemoteConfig registers an optional readiness check that fails if the configuration is loaded
+// from a remote URL and it was not successfully pulled within the configured `remote_config_max_age`
+func RegisterRemoteConfig() {
+	Register(Check{
+		Name:     RemoteConfigCheckName,
+		Fn:       RemoteConfig,
+		Optional: true,
+	})
+}
+
+// RemoteConfig checks the last remote configuration pull. Passes if the configuration is not remote.
+func RemoteConfig(_ context.Context) error {
+	status := configx.Remote()
+	if !status.Enabled {
+		return nil
+	}
+	maxAge := millis(getConfig().RemoteConfigMaxAge)
+	if status.Frequency > maxAge {
+		maxAge = 2 * status.Frequency
+	}
+	if status.Frequency == 0 || time.Since(status.LastSuccess) <= maxAge {
+		return nil
+	}
+	if status.LastError != "" {
+		return fmt.Errorf("last remote config pull at %s failed, %s", status.LastAttempt.Format(time.RFC3339), status.LastError)
+	}
+	return fmt.Errorf("remote config was not pulled since %s", status.LastSuccess.Format(time.RFC3339))
+}
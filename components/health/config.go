@@ -0,0 +1,70 @@
+package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/logx"
+)
+
+const (
+	configKey  = "health"
+	configName = "health-cfg"
+)
+
+// Config is the configuration of the health checks. Durations are in milliseconds.
+type Config struct {
+	// Timeout is the default maximum duration of a check. Default is 5 seconds
+	Timeout int64 `json:"timeout" yaml:"timeout" default:"5000"`
+
+	// CacheTTL is the default duration the result of a check is reused. Default is 1 second
+	CacheTTL int64 `json:"cache_ttl" yaml:"cache_ttl" default:"1000"`
+
+	// Interval is the interval at which the checks run in the background. When set, the probes report the
+	// results of the latest background run instead of running the checks. Zero runs the checks on demand.
+	Interval int64 `json:"interval" yaml:"interval"`
+
+	// RemoteConfigMaxAge is the maximum age of the last successful remote configuration pull before the
+	// `remote-config` check fails. Default is 5 minutes
+	RemoteConfigMaxAge int64 `json:"remote_config_max_age" yaml:"remote_config_max_age" default:"300000"`
+}
+
+var (
+	cfg    = defaultConfig()
+	cfgMux sync.RWMutex
+)
+
+func init() {
+	configx.AddOnReloadCallback(func(c configx.IConfig) {
+		config := defaultConfig()
+
+		logx.WithObj(
+			c.MapToObj(configKey, config),
+		).Fatal("unable to map health configuration")
+
+		cfgMux.Lock()
+		cfg = config
+		cfgMux.Unlock()
+
+		setInterval(millis(config.Interval))
+	}, configName)
+}
+
+func getConfig() *Config {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+	return cfg
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Timeout:            5000,
+		CacheTTL:           1000,
+		RemoteConfigMaxAge: 300000,
+	}
+}
+
+func millis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
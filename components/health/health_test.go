@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-testx/assert"
+)
+
+func reset() {
+	mux.Lock()
+	checks = map[string]*entry{}
+	mux.Unlock()
+}
+
+func TestReport(t *testing.T) {
+	reset()
+	Register(Check{Name: "db", Fn: func(context.Context) error { return nil }})
+	Register(Check{Name: "cache", Optional: true, Fn: func(context.Context) error { return errors.New("unreachable") }})
+	Register(Check{Name: "process", Scope: ScopeLiveness, Fn: func(context.Context) error { return nil }})
+
+	r := Readiness(context.Background())
+	assert.Equal(t, StatusDegraded, r.Status)
+	assert.Equal(t, 2, len(r.Checks))
+	assert.Equal(t, StatusUp, r.Checks["db"].Status)
+	assert.Equal(t, StatusDown, r.Checks["cache"].Status)
+	assert.Equal(t, "unreachable", r.Checks["cache"].Error)
+
+	l := Liveness(context.Background())
+	assert.Equal(t, StatusUp, l.Status)
+	assert.Equal(t, 1, len(l.Checks))
+
+	Register(Check{Name: "queue", Scope: ScopeAll, Fn: func(context.Context) error { panic("boom") }})
+	assert.Equal(t, StatusDown, Readiness(context.Background()).Status)
+	assert.Equal(t, StatusDown, Liveness(context.Background()).Status)
+}
+
+func TestTimeoutAndCache(t *testing.T) {
+	reset()
+	var calls int32
+	Register(Check{
+		Name:     "slow",
+		Timeout:  10 * time.Millisecond,
+		CacheTTL: time.Minute,
+		Fn: func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(time.Second)
+			return nil
+		},
+	})
+
+	start := time.Now()
+	r := Readiness(context.Background())
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+	assert.Equal(t, StatusDown, r.Status)
+	assert.Contains(t, r.Checks["slow"].Error, "timed out")
+
+	Readiness(context.Background())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	Register(Check{Name: "uncached", CacheTTL: -1, Fn: func(context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}})
+	Unregister("slow")
+	Readiness(context.Background())
+	Readiness(context.Background())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
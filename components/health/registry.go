@@ -0,0 +1,218 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/components/monitor"
+)
+
+var (
+	checks     = map[string]*entry{}
+	background monitor.IMonitor
+	interval   time.Duration
+	mux        sync.RWMutex
+)
+
+type entry struct {
+	check  Check
+	result *Result
+	mux    sync.Mutex
+}
+
+// Register registers a health check. Registering a check with an existing name replaces it.
+func Register(check Check) {
+	if check.Scope == "" {
+		check.Scope = ScopeReadiness
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	checks[check.Name] = &entry{check: check}
+}
+
+// Unregister removes the health check with the provided name
+func Unregister(name string) {
+	mux.Lock()
+	defer mux.Unlock()
+	delete(checks, name)
+}
+
+// Liveness evaluates the liveness checks
+func Liveness(ctx context.Context) *Report {
+	return evaluate(ctx, ScopeLiveness)
+}
+
+// Readiness evaluates the readiness checks
+func Readiness(ctx context.Context) *Report {
+	return evaluate(ctx, ScopeReadiness)
+}
+
+// Names returns the names of the registered checks
+func Names() []string {
+	mux.RLock()
+	defer mux.RUnlock()
+	var ret []string
+	for k := range checks {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func evaluate(ctx context.Context, scope Scope) *Report {
+	var entries []*entry
+	mux.RLock()
+	for _, e := range checks {
+		if e.check.Scope == scope || e.check.Scope == ScopeAll {
+			entries = append(entries, e)
+		}
+	}
+	bg := interval
+	mux.RUnlock()
+
+	ret := &Report{Status: StatusUp, Checks: map[string]*Result{}}
+	results := make([]*Result, len(entries))
+
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.evaluate(ctx, bg)
+		}(i, e)
+	}
+	wg.Wait()
+
+	for i, e := range entries {
+		r := results[i]
+		ret.Checks[e.check.Name] = r
+		if r.Status == StatusUp {
+			continue
+		}
+		if !e.check.Optional {
+			ret.Status = StatusDown
+		} else if ret.Status == StatusUp {
+			ret.Status = StatusDegraded
+		}
+	}
+	return ret
+}
+
+// evaluate returns the cached result of the check if still valid, otherwise runs the check. In background
+// mode results are valid until the next background run is expected to complete.
+func (e *entry) evaluate(ctx context.Context, bg time.Duration) *Result {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	cfg := getConfig()
+	timeout := e.check.Timeout
+	if timeout <= 0 {
+		timeout = millis(cfg.Timeout)
+	}
+	ttl := e.check.CacheTTL
+	if ttl == 0 {
+		ttl = millis(cfg.CacheTTL)
+	}
+	if bg > 0 && ttl >= 0 && ttl < bg+timeout {
+		ttl = bg + timeout
+	}
+
+	if e.result != nil && ttl > 0 && time.Since(e.result.CheckedAt) < ttl {
+		return e.result
+	}
+	e.result = e.run(ctx, timeout)
+	return e.result
+}
+
+func (e *entry) run(ctx context.Context, timeout time.Duration) *Result {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked, %v", r)
+			}
+		}()
+		done <- e.check.Fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", time.Since(start).Round(time.Millisecond))
+	}
+
+	ret := &Result{
+		Status:    StatusUp,
+		Optional:  e.check.Optional,
+		Duration:  time.Since(start).Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		ret.Status = StatusDown
+		ret.Error = err.Error()
+	}
+	return ret
+}
+
+// watcher runs all the checks in the background, refreshing the cached results
+type watcher struct{}
+
+func (watcher) Run() {
+	mux.RLock()
+	var entries []*entry
+	for _, e := range checks {
+		entries = append(entries, e)
+	}
+	mux.RUnlock()
+
+	timeout := millis(getConfig().Timeout)
+	for _, e := range entries {
+		go func(e *entry) {
+			t := e.check.Timeout
+			if t <= 0 {
+				t = timeout
+			}
+			r := e.run(context.Background(), t)
+			e.mux.Lock()
+			e.result = r
+			e.mux.Unlock()
+		}(e)
+	}
+}
+
+// setInterval starts, restarts or stops the background checks
+func setInterval(d time.Duration) {
+	mux.Lock()
+	if d == interval {
+		mux.Unlock()
+		return
+	}
+	previous := background
+	background = nil
+	interval = d
+	if d > 0 {
+		background = monitor.New(d)
+		background.AddWatcher(watcher{})
+	}
+	current := background
+	mux.Unlock()
+
+	// Stopping outside of the lock, the watcher may be waiting for it
+	if previous != nil {
+		previous.Stop()
+	}
+	if current != nil {
+		current.StartAsync()
+	}
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// Status of a check or of a report
+type Status string
+
+const (
+	// StatusUp indicates the check passed, or that all the checks of a report passed
+	StatusUp Status = "UP"
+
+	// StatusDown indicates the check failed, or that a critical check of a report failed
+	StatusDown Status = "DOWN"
+
+	// StatusDegraded indicates that only optional checks of a report failed
+	StatusDegraded Status = "DEGRADED"
+)
+
+// Scope indicates which probes evaluate a check
+type Scope string
+
+const (
+	// ScopeReadiness checks are evaluated by the readiness probe, which indicates whether the service can
+	// handle requests, e.g. its dependencies are available
+	ScopeReadiness Scope = "readiness"
+
+	// ScopeLiveness checks are evaluated by the liveness probe, which indicates whether the service is
+	// running properly or should be restarted
+	ScopeLiveness Scope = "liveness"
+
+	// ScopeAll checks are evaluated by both probes
+	ScopeAll Scope = "all"
+)
+
+// CheckFunc verifies the health of a component. Returns an error if the component is unhealthy. The
+// context is done when the check times out.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named health check
+type Check struct {
+	// Name uniquely identifies the check, e.g. `mongo:default`
+	Name string
+
+	// Fn is the check function
+	Fn CheckFunc
+
+	// Scope indicates which probes evaluate the check. Default is `readiness`
+	Scope Scope
+
+	// Optional indicates that a failure of the check degrades the service but does not fail the probe
+	Optional bool
+
+	// Timeout is the maximum duration of the check. Zero uses the configured timeout
+	Timeout time.Duration
+
+	// CacheTTL is the duration the result of the check is reused. Zero uses the configured cache TTL,
+	// negative runs the check every time, even in background mode
+	CacheTTL time.Duration
+}
+
+// Result is the result of a check
+type Result struct {
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Optional  bool      `json:"optional,omitempty"`
+	Duration  int64     `json:"duration_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the aggregated result of the checks of a probe
+type Report struct {
+	Status Status             `json:"status"`
+	Checks map[string]*Result `json:"checks,omitempty"`
+}
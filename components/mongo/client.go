@@ -1,13 +1,19 @@
 package mongo
 
 import (
+	"context"
+	"errors"
+
 	"github.com/jucardi/go-strings/stringx"
+	"github.com/jucardi/go-titan/components/resilience"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
 )
 
 type mgoClient struct {
 	client *mongo.Client
 	dbName string
+	name   string
 }
 
 func (c *mgoClient) Client() *mongo.Client {
@@ -22,3 +28,27 @@ func (c *mgoClient) DB(name ...string) (mongo.Session, *mongo.Database, error) {
 	dbName := stringx.GetOrDefault(c.dbName, name...)
 	return session, session.Client().Database(dbName), nil
 }
+
+func (c *mgoClient) Execute(ctx context.Context, fn func(ctx context.Context, db *mongo.Database) error) error {
+	return resilience.Execute(ctx, "mongo:"+c.name, func(ctx context.Context) error {
+		return fn(ctx, c.client.Database(c.dbName))
+	}, isFailure)
+}
+
+// isFailure indicates whether an error returned by an operation means the database is unavailable: network
+// errors, timeouts, server selection failures and errors labeled as network or retryable write errors. Any
+// other error, e.g. a missing document or a business error returned by the callback, is not a failure.
+func isFailure(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) || errors.Is(err, mongo.ErrClientDisconnected) {
+		return true
+	}
+	var selectionErr topology.ServerSelectionError
+	if errors.As(err, &selectionErr) || errors.Is(err, topology.ErrServerSelectionTimeout) {
+		return true
+	}
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.HasErrorLabel("RetryableWriteError")
+}
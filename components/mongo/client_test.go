@@ -0,0 +1,27 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+func TestIsFailure(t *testing.T) {
+	assert.False(t, isFailure(mongo.ErrNoDocuments))
+	assert.False(t, isFailure(errorx.NewNotFound("order not found")))
+	assert.False(t, isFailure(errors.New("invalid order")))
+	assert.False(t, isFailure(context.Canceled))
+	assert.False(t, isFailure(mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121}}}))
+	assert.False(t, isFailure(mongo.CommandError{Code: 2, Name: "BadValue"}))
+
+	assert.True(t, isFailure(context.DeadlineExceeded))
+	assert.True(t, isFailure(mongo.ErrClientDisconnected))
+	assert.True(t, isFailure(topology.ServerSelectionError{Wrapped: errors.New("no reachable servers")}))
+	assert.True(t, isFailure(mongo.CommandError{Code: 6, Labels: []string{"NetworkError"}}))
+	assert.True(t, isFailure(mongo.CommandError{Code: 189, Labels: []string{"RetryableWriteError"}}))
+}
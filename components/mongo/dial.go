@@ -2,9 +2,11 @@ package mongo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/jucardi/go-beans/beans"
 	"github.com/jucardi/go-strings/stringx"
+	"github.com/jucardi/go-titan/components/health"
 	"github.com/jucardi/go-titan/logx"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -29,16 +31,17 @@ func Dial(cfg ...*Config) (IClient, error) {
 		return nil, err
 	}
 
-	ret := &mgoClient{
-		client: client,
-		dbName: c.dbName(),
-	}
-
 	name := defaultClient
 	if c.Name != "" {
 		name = c.Name
 	}
 
+	ret := &mgoClient{
+		client: client,
+		dbName: c.dbName(),
+		name:   name,
+	}
+
 	current := Get(name)
 
 	if err := beans.Register(ref, name, ret); err != nil {
@@ -48,6 +51,17 @@ func Dial(cfg ...*Config) (IClient, error) {
 		return nil, err
 	}
 
+	health.Register(health.Check{
+		Name: "mongo:" + name,
+		Fn: func(ctx context.Context) error {
+			c := Get(name)
+			if c == nil {
+				return errors.New("the connection is closed")
+			}
+			return c.Client().Ping(ctx, nil)
+		},
+	})
+
 	// If overrides are allowed and another connection with the same name existed, closes that connection.
 	if current != nil {
 		logx.WithObj(
@@ -1,6 +1,8 @@
 package mongo
 
 import (
+	"context"
+
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -9,4 +11,9 @@ type IClient interface {
 	DB(name ...string) (mongo.Session, *mongo.Database, error)
 	// Client returns the underlying mongo client contained by this wrapper
 	Client() *mongo.Client
+	// Execute runs the provided operation against the default database, protected by the circuit breaker
+	// and bulkhead of this connection (named `mongo:{connection name}`). Only network errors, timeouts and
+	// server selection failures count as failures for the circuit breaker, any other error returned by the
+	// operation, such as `mongo.ErrNoDocuments` or a validation error, does not.
+	Execute(ctx context.Context, fn func(ctx context.Context, db *mongo.Database) error) error
 }
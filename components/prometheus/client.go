@@ -3,106 +3,120 @@ package prometheus
 import (
 	"context"
 	"os"
-	"sync"
-
-	"github.com/prometheus/client_golang/prometheus"
 )
 
-var once sync.Once
+// legacyLabels are the labels added by the `PrometheusClient` to the values of its metrics
+var legacyLabels = []string{"endpointName", "hostname", "env", "taskSlot"}
 
 var (
-	metricClientInstance = &PrometheusClient{}
-)
-
-func GetSingleton() *PrometheusClient {
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = "unknown"
-	}
-
-	once.Do(func() {
-		metricClientInstance = &PrometheusClient{
-			ctx:      context.Background(),
-			hostname: hostname,
-			env:      os.Getenv("EXECUTION_ENV"),
-			taskSlot: os.Getenv("TASK_SLOT"),
-		}
+	requestTimeGauge = Gauge(Opts{
+		Name:   string(GaugeMetricNameRequestTime),
+		Help:   "Request time of each request in milliseconds",
+		Labels: legacyLabels,
 	})
-	return metricClientInstance
-}
-
-// ==== Start: Metric client ====
 
-var RequestTimeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
-	Name: string(GaugeMetricNameRequestTime),
-	Help: "Request time of each request in milliseconds",
-}, []string{"endpointName", "hostname", "env", "taskSlot"})
+	requestTimeHistogram = Histogram(Opts{
+		Name:    string(HistogramMetricNameRequestTime),
+		Help:    "Request time of each request in milliseconds",
+		Buckets: []float64{50, 100, 200, 300, 500, 800, 1300, 2100, 3400, 5500},
+		Labels:  legacyLabels,
+	})
 
-var RequestTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-	Name:    string(HistogramMetricNameRequestTime),
-	Help:    "Request time of each request in milliseconds",
-	Buckets: []float64{50, 100, 200, 300, 500, 800, 1300, 2100, 3400, 5500},
-}, []string{"endpointName", "hostname", "env", "taskSlot"})
+	apiErrorCounter = Counter(Opts{
+		Name:   string(CounterApiErrors),
+		Help:   "Total number of response errors in API",
+		Labels: append(legacyLabels, "statusCode"),
+	})
 
-var ApiErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-	Name: string(CounterApiErrors),
-	Help: "Total number of response errors in API",
-}, []string{"endpointName", "hostname", "env", "taskSlot", "statusCode"})
+	// RequestTimeGauge is the gauge of the request times recorded by `PrometheusClient.SetGaugeValue`
+	//
+	// Deprecated: declare the metrics with `Gauge` instead. Will be removed in the next release.
+	RequestTimeGauge = requestTimeGauge.Vec()
+
+	// RequestTimeHistogram is the histogram of the request times recorded by
+	// `PrometheusClient.ObserveHistogramValue`
+	//
+	// Deprecated: declare the metrics with `Histogram` instead. Will be removed in the next release.
+	RequestTimeHistogram = requestTimeHistogram.Vec()
+
+	// ApiErrorCounter is the counter of the API errors recorded by `PrometheusClient.IncreaseCounter`
+	//
+	// Deprecated: declare the metrics with `Counter` instead. Will be removed in the next release.
+	ApiErrorCounter = apiErrorCounter.Vec()
+
+	metricClientInstance = &PrometheusClient{
+		ctx:      context.Background(),
+		registry: defaultRegistry,
+		defaults: map[string]string{
+			"hostname": hostname,
+			"env":      os.Getenv("EXECUTION_ENV"),
+			"taskSlot": os.Getenv("TASK_SLOT"),
+		},
+	}
+)
 
-func init() {
-	prometheus.MustRegister(RequestTimeGauge)
-	prometheus.MustRegister(RequestTimeHistogram)
-	prometheus.MustRegister(ApiErrorCounter)
+// GetSingleton returns the client of the metrics of the default registry
+//
+// Deprecated: declare the metrics with `Counter`, `Gauge`, `Histogram` and `Summary` instead, and
+// configure the `hostname` and `const_labels` of the `prometheus` configuration instead of the `hostname`,
+// `env` and `taskSlot` labels added by the client. Will be removed in the next release.
+func GetSingleton() *PrometheusClient {
+	return metricClientInstance
 }
 
-// ==== End: Metric client ====
-
+// PrometheusClient records values of the metrics of a registry by metric name, with the label values
+// provided by label name. The `hostname`, `env` (from `EXECUTION_ENV`) and `taskSlot` (from `TASK_SLOT`)
+// labels are added if not provided. Values of metrics which are not declared, or of another type, are
+// ignored.
+//
+// Deprecated: use the metrics declared with `Counter`, `Gauge`, `Histogram` and `Summary` instead.
 type PrometheusClient struct {
 	ctx      context.Context
-	hostname string
-	env      string
-	taskSlot string
+	registry *Registry
+	defaults map[string]string
 }
 
+// WithCtx returns a copy of the client with the provided context
 func (c *PrometheusClient) WithCtx(ctx context.Context) *PrometheusClient {
-	return &PrometheusClient{
-		ctx: ctx,
-	}
-}
-
-func addDefaultLabels(labels map[string]string, c *PrometheusClient) map[string]string {
-	labels["hostname"] = c.hostname
-	labels["env"] = c.env
-	labels["taskSlot"] = c.taskSlot
-	return labels
+	ret := *c
+	ret.ctx = ctx
+	return &ret
 }
 
 func (c *PrometheusClient) SetGaugeValue(metricName GaugeMetricName, value float64, labels map[string]string) {
-	labels = addDefaultLabels(labels, c)
-	switch metricName {
-	case GaugeMetricNameRequestTime:
-		RequestTimeGauge.With(labels).Set(value)
-	default:
-		break
+	if m, values := c.lookup(kindGauge, string(metricName), labels); m != nil {
+		(&GaugeMetric{m}).Set(value, values...)
 	}
 }
 
 func (c *PrometheusClient) ObserveHistogramValue(metricName HistogramMetricName, value float64, labels map[string]string) {
-	labels = addDefaultLabels(labels, c)
-	switch metricName {
-	case HistogramMetricNameRequestTime:
-		RequestTimeHistogram.With(labels).Observe(value)
-	default:
-		break
+	if m, values := c.lookup(kindHistogram, string(metricName), labels); m != nil {
+		(&HistogramMetric{m}).Observe(value, values...)
 	}
 }
 
 func (c *PrometheusClient) IncreaseCounter(metricName CounterMetricName, labels map[string]string) {
-	labels = addDefaultLabels(labels, c)
-	switch metricName {
-	case CounterApiErrors:
-		ApiErrorCounter.With(labels).Inc()
-	default:
-		break
+	if m, values := c.lookup(kindCounter, string(metricName), labels); m != nil {
+		(&CounterMetric{m}).Inc(values...)
+	}
+}
+
+// lookup returns the declared metric with the provided name and type, and the label values in the order
+// of its declared labels
+func (c *PrometheusClient) lookup(k kind, name string, labels map[string]string) (*metric, []string) {
+	c.registry.mux.Lock()
+	m, ok := c.registry.metrics[name]
+	c.registry.mux.Unlock()
+	if !ok || m.kind != k {
+		return nil, nil
+	}
+	values := make([]string, len(m.opts.Labels))
+	for i, l := range m.opts.Labels {
+		v, ok := labels[l]
+		if !ok {
+			v = c.defaults[l]
+		}
+		values[i] = v
 	}
+	return m, values
 }
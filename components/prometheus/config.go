@@ -0,0 +1,60 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/logx"
+)
+
+const (
+	configKey  = "prometheus"
+	configName = "prometheus-cfg"
+)
+
+// Config is the configuration of the metrics registries
+type Config struct {
+	// ConstLabels are labels added to all the metrics exposed by the registry, e.g. `env` or `region`
+	ConstLabels map[string]string `json:"const_labels,omitempty" yaml:"const_labels,omitempty"`
+
+	// Hostname adds the `hostname` label with the host name to all the metrics exposed by the registry
+	Hostname bool `json:"hostname" yaml:"hostname"`
+
+	// Buckets are the default buckets of the histograms which do not declare their own. Default is the
+	// Prometheus default buckets, suited to durations in seconds
+	Buckets []float64 `json:"buckets,omitempty" yaml:"buckets,omitempty"`
+
+	// MetricBuckets overrides the buckets of specific histograms, by metric name
+	MetricBuckets map[string][]float64 `json:"metric_buckets,omitempty" yaml:"metric_buckets,omitempty"`
+
+	// DisableRuntimeMetrics disables the Go runtime and process collectors
+	DisableRuntimeMetrics bool `json:"disable_runtime_metrics" yaml:"disable_runtime_metrics"`
+}
+
+var (
+	cfg    = &Config{}
+	cfgMux sync.RWMutex
+)
+
+func init() {
+	configx.AddOnReloadCallback(func(c configx.IConfig) {
+		config := &Config{}
+
+		logx.WithObj(
+			c.MapToObj(configKey, config),
+		).Fatal("unable to map prometheus configuration")
+
+		cfgMux.Lock()
+		cfg = config
+		cfgMux.Unlock()
+
+		defaultRegistry.setRuntimeMetrics(!config.DisableRuntimeMetrics)
+	}, configName)
+}
+
+// GetConfig returns the configuration of the default registry
+func GetConfig() *Config {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+	return cfg
+}
@@ -0,0 +1,154 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type kind string
+
+const (
+	kindCounter   kind = "counter"
+	kindGauge     kind = "gauge"
+	kindHistogram kind = "histogram"
+	kindSummary   kind = "summary"
+)
+
+// Opts are the options of a metric
+type Opts struct {
+	// Name is the fully qualified name of the metric, e.g. `http_server_requests_total`
+	Name string
+
+	// Help describes the metric
+	Help string
+
+	// Labels are the names of the variable labels of the metric, whose values are provided, in the same
+	// order, when recording a value
+	Labels []string
+
+	// Buckets are the upper bounds of the buckets of a histogram, see `Registry.Histogram`
+	Buckets []float64
+
+	// Objectives are the quantiles of a summary with their absolute error, e.g. `{0.5: 0.05, 0.99: 0.001}`
+	Objectives map[float64]float64
+}
+
+// metric is a declared metric, created and registered on first use
+type metric struct {
+	registry  *Registry
+	kind      kind
+	opts      Opts
+	collector prometheus.Collector
+	once      sync.Once
+}
+
+func (m *metric) get() prometheus.Collector {
+	m.once.Do(func() {
+		opts := m.opts
+		switch m.kind {
+		case kindCounter:
+			m.collector = prometheus.NewCounterVec(prometheus.CounterOpts{Name: opts.Name, Help: opts.Help}, opts.Labels)
+		case kindGauge:
+			m.collector = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: opts.Name, Help: opts.Help}, opts.Labels)
+		case kindHistogram:
+			m.collector = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    opts.Name,
+				Help:    opts.Help,
+				Buckets: m.registry.buckets(opts),
+			}, opts.Labels)
+		case kindSummary:
+			m.collector = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+				Name:       opts.Name,
+				Help:       opts.Help,
+				Objectives: opts.Objectives,
+			}, opts.Labels)
+		}
+		m.registry.registry.MustRegister(m.collector)
+	})
+	return m.collector
+}
+
+// CounterMetric is a counter declared in a registry. Label values are provided in the order of the
+// declared labels.
+type CounterMetric struct {
+	m *metric
+}
+
+// Inc increments the counter by 1
+func (c *CounterMetric) Inc(labels ...string) {
+	c.Vec().WithLabelValues(labels...).Inc()
+}
+
+// Add adds the provided value, which must be positive, to the counter
+func (c *CounterMetric) Add(value float64, labels ...string) {
+	c.Vec().WithLabelValues(labels...).Add(value)
+}
+
+// Vec returns the underlying counter vector
+func (c *CounterMetric) Vec() *prometheus.CounterVec {
+	return c.m.get().(*prometheus.CounterVec)
+}
+
+// GaugeMetric is a gauge declared in a registry. Label values are provided in the order of the declared
+// labels.
+type GaugeMetric struct {
+	m *metric
+}
+
+// Set sets the gauge to the provided value
+func (g *GaugeMetric) Set(value float64, labels ...string) {
+	g.Vec().WithLabelValues(labels...).Set(value)
+}
+
+// Inc increments the gauge by 1
+func (g *GaugeMetric) Inc(labels ...string) {
+	g.Vec().WithLabelValues(labels...).Inc()
+}
+
+// Dec decrements the gauge by 1
+func (g *GaugeMetric) Dec(labels ...string) {
+	g.Vec().WithLabelValues(labels...).Dec()
+}
+
+// Add adds the provided value to the gauge
+func (g *GaugeMetric) Add(value float64, labels ...string) {
+	g.Vec().WithLabelValues(labels...).Add(value)
+}
+
+// Vec returns the underlying gauge vector
+func (g *GaugeMetric) Vec() *prometheus.GaugeVec {
+	return g.m.get().(*prometheus.GaugeVec)
+}
+
+// HistogramMetric is a histogram declared in a registry. Label values are provided in the order of the
+// declared labels.
+type HistogramMetric struct {
+	m *metric
+}
+
+// Observe adds an observation to the histogram
+func (h *HistogramMetric) Observe(value float64, labels ...string) {
+	h.Vec().WithLabelValues(labels...).Observe(value)
+}
+
+// Vec returns the underlying histogram vector
+func (h *HistogramMetric) Vec() *prometheus.HistogramVec {
+	return h.m.get().(*prometheus.HistogramVec)
+}
+
+// SummaryMetric is a summary declared in a registry. Label values are provided in the order of the
+// declared labels.
+type SummaryMetric struct {
+	m *metric
+}
+
+// Observe adds an observation to the summary
+func (s *SummaryMetric) Observe(value float64, labels ...string) {
+	s.Vec().WithLabelValues(labels...).Observe(value)
+}
+
+// Vec returns the underlying summary vector
+func (s *SummaryMetric) Vec() *prometheus.SummaryVec {
+	return s.m.get().(*prometheus.SummaryVec)
+}
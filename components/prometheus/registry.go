@@ -0,0 +1,282 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+const hostnameLabel = "hostname"
+
+var (
+	defaultRegistry = newRegistry(GetConfig)
+
+	hostname = func() string {
+		ret, err := os.Hostname()
+		if err != nil {
+			return "unknown"
+		}
+		return ret
+	}()
+)
+
+func init() {
+	defaultRegistry.setRuntimeMetrics(true)
+}
+
+// Registry is a set of metrics exposed together. Metrics are declared once, usually as package variables,
+// and are created and registered on first use, so the buckets configured for them apply even if they
+// are declared before the configuration is loaded.
+//
+// The const labels of the configuration are added to all the metrics when they are gathered, so they may
+// change on configuration reloads.
+type Registry struct {
+	registry *prometheus.Registry
+	config   func() *Config
+	metrics  map[string]*metric
+
+	runtime    bool
+	collectors []prometheus.Collector
+	mux        sync.Mutex
+}
+
+// Default returns the default registry, configured by the `prometheus` configuration and exposed by the
+// admin `/metrics/prometheus` endpoint. It includes the Go runtime and process collectors unless disabled.
+//
+// It is independent of the global registry of the Prometheus client library, whose metrics are exposed
+// along with it by `Handler`.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// NewRegistry creates a private registry with the provided configuration, or an empty configuration if
+// nil, which includes the Go runtime and process collectors unless disabled. Useful in tests, to assert
+// the metrics of a component without interference.
+func NewRegistry(cfg *Config) *Registry {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	ret := newRegistry(func() *Config { return cfg })
+	ret.setRuntimeMetrics(!cfg.DisableRuntimeMetrics)
+	return ret
+}
+
+func newRegistry(config func() *Config) *Registry {
+	return &Registry{
+		registry: prometheus.NewRegistry(),
+		config:   config,
+		metrics:  map[string]*metric{},
+	}
+}
+
+// Counter declares a counter in the default registry, see `Registry.Counter`
+func Counter(opts Opts) *CounterMetric {
+	return defaultRegistry.Counter(opts)
+}
+
+// Gauge declares a gauge in the default registry, see `Registry.Gauge`
+func Gauge(opts Opts) *GaugeMetric {
+	return defaultRegistry.Gauge(opts)
+}
+
+// Histogram declares a histogram in the default registry, see `Registry.Histogram`
+func Histogram(opts Opts) *HistogramMetric {
+	return defaultRegistry.Histogram(opts)
+}
+
+// Summary declares a summary in the default registry, see `Registry.Summary`
+func Summary(opts Opts) *SummaryMetric {
+	return defaultRegistry.Summary(opts)
+}
+
+// Handler returns the HTTP handler that exposes the metrics of the default registry, and the metrics of the
+// global registry of the Prometheus client library, e.g. registered by dependencies with
+// `prometheus.MustRegister`. The global metrics are exposed as is, without the configured const labels, and
+// the ones with the same name as a metric of the default registry, as well as the Go runtime and process
+// metrics, are left out.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(withGlobal{defaultRegistry}, promhttp.HandlerOpts{})
+}
+
+// Counter declares a counter. Declaring an existing counter returns it.
+//
+// Panics if a metric of another type was declared with the same name.
+func (r *Registry) Counter(opts Opts) *CounterMetric {
+	return &CounterMetric{r.declare(kindCounter, opts)}
+}
+
+// Gauge declares a gauge. Declaring an existing gauge returns it.
+//
+// Panics if a metric of another type was declared with the same name.
+func (r *Registry) Gauge(opts Opts) *GaugeMetric {
+	return &GaugeMetric{r.declare(kindGauge, opts)}
+}
+
+// Histogram declares a histogram. Its buckets are, in order of precedence, the ones configured for the
+// metric name in `metric_buckets`, the ones of the options and the configured default `buckets`.
+// Declaring an existing histogram returns it.
+//
+// Panics if a metric of another type was declared with the same name.
+func (r *Registry) Histogram(opts Opts) *HistogramMetric {
+	return &HistogramMetric{r.declare(kindHistogram, opts)}
+}
+
+// Summary declares a summary. Declaring an existing summary returns it.
+//
+// Panics if a metric of another type was declared with the same name.
+func (r *Registry) Summary(opts Opts) *SummaryMetric {
+	return &SummaryMetric{r.declare(kindSummary, opts)}
+}
+
+// Register registers a custom collector in the registry
+func (r *Registry) Register(c prometheus.Collector) error {
+	return r.registry.Register(c)
+}
+
+// Unregister removes a custom collector from the registry
+func (r *Registry) Unregister(c prometheus.Collector) bool {
+	return r.registry.Unregister(c)
+}
+
+// Gather gathers the metrics of the registry with the configured const labels. Implements
+// `prometheus.Gatherer`, so the registry can be used with `testutil`.
+func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
+	families, err := r.registry.Gather()
+	labels := r.constLabels()
+	if len(labels) == 0 {
+		return families, err
+	}
+	for _, f := range families {
+		for _, m := range f.Metric {
+			addLabels(m, labels)
+		}
+	}
+	return families, err
+}
+
+// Handler returns the HTTP handler that exposes the metrics of the registry
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r, promhttp.HandlerOpts{})
+}
+
+func (r *Registry) declare(k kind, opts Opts) *metric {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if m, ok := r.metrics[opts.Name]; ok {
+		if m.kind != k {
+			panic(fmt.Sprintf("metric '%s' already declared as a %s", opts.Name, m.kind))
+		}
+		return m
+	}
+	m := &metric{registry: r, kind: k, opts: opts}
+	r.metrics[opts.Name] = m
+	return m
+}
+
+// buckets returns the buckets of a histogram
+func (r *Registry) buckets(opts Opts) []float64 {
+	cfg := r.config()
+	if b, ok := cfg.MetricBuckets[opts.Name]; ok && len(b) > 0 {
+		return b
+	}
+	if len(opts.Buckets) > 0 {
+		return opts.Buckets
+	}
+	if len(cfg.Buckets) > 0 {
+		return cfg.Buckets
+	}
+	return prometheus.DefBuckets
+}
+
+func (r *Registry) constLabels() map[string]string {
+	cfg := r.config()
+	if !cfg.Hostname {
+		return cfg.ConstLabels
+	}
+	ret := map[string]string{hostnameLabel: hostname}
+	for k, v := range cfg.ConstLabels {
+		ret[k] = v
+	}
+	return ret
+}
+
+// setRuntimeMetrics registers or unregisters the Go runtime and process collectors
+func (r *Registry) setRuntimeMetrics(enabled bool) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if enabled == r.runtime {
+		return
+	}
+	r.runtime = enabled
+	if !enabled {
+		for _, c := range r.collectors {
+			r.registry.Unregister(c)
+		}
+		r.collectors = nil
+		return
+	}
+	r.collectors = []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}
+	for _, c := range r.collectors {
+		r.registry.MustRegister(c)
+	}
+}
+
+// addLabels adds the provided labels to a metric, unless it already has a label with the same name
+func addLabels(m *dto.Metric, labels map[string]string) {
+	existing := map[string]bool{}
+	for _, l := range m.Label {
+		existing[l.GetName()] = true
+	}
+	for k, v := range labels {
+		if !existing[k] {
+			m.Label = append(m.Label, &dto.LabelPair{Name: proto.String(k), Value: proto.String(v)})
+		}
+	}
+	sort.Slice(m.Label, func(i, j int) bool {
+		return m.Label[i].GetName() < m.Label[j].GetName()
+	})
+}
+
+// withGlobal gathers the metrics of a registry followed by the metrics of the global registry which are not
+// in it, see `Handler`
+type withGlobal struct {
+	registry *Registry
+}
+
+func (g withGlobal) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.registry.Gather()
+	var errs prometheus.MultiError
+	errs.Append(err)
+
+	names := map[string]bool{}
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	global, err := prometheus.DefaultGatherer.Gather()
+	errs.Append(err)
+	for _, f := range global {
+		name := f.GetName()
+		if names[name] || strings.HasPrefix(name, "go_") || strings.HasPrefix(name, "process_") {
+			continue
+		}
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+	return families, errs.MaybeUnwrap()
+}
@@ -0,0 +1,108 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestRegistryMetrics(t *testing.T) {
+	r := NewRegistry(&Config{
+		ConstLabels:           map[string]string{"env": "test"},
+		MetricBuckets:         map[string][]float64{"job_duration_seconds": {1, 5}},
+		DisableRuntimeMetrics: true,
+	})
+
+	jobs := r.Counter(Opts{Name: "jobs_total", Help: "Jobs", Labels: []string{"queue"}})
+	jobs.Inc("emails")
+	jobs.Add(2, "emails")
+	assert.Equal(t, jobs.m, r.Counter(Opts{Name: "jobs_total"}).m)
+
+	r.Gauge(Opts{Name: "queue_size", Help: "Queue size"}).Set(7)
+	r.Histogram(Opts{Name: "job_duration_seconds", Help: "Job duration", Buckets: []float64{10}}).Observe(3)
+	r.Summary(Opts{Name: "job_size_bytes", Help: "Job size", Objectives: map[float64]float64{0.5: 0.05}}).Observe(100)
+
+	families := gather(t, r)
+	assert.Equal(t, 4, len(families))
+
+	counter := families["jobs_total"].Metric[0]
+	assert.Equal(t, float64(3), counter.GetCounter().GetValue())
+	assert.Equal(t, map[string]string{"env": "test", "queue": "emails"}, labels(counter))
+
+	assert.Equal(t, float64(7), families["queue_size"].Metric[0].GetGauge().GetValue())
+
+	buckets := families["job_duration_seconds"].Metric[0].GetHistogram().GetBucket()
+	assert.Equal(t, 2, len(buckets))
+	assert.Equal(t, float64(5), buckets[1].GetUpperBound())
+	assert.Equal(t, uint64(1), buckets[1].GetCumulativeCount())
+
+	assert.Equal(t, uint64(1), families["job_size_bytes"].Metric[0].GetSummary().GetSampleCount())
+}
+
+func TestRegistryDeclarationConflict(t *testing.T) {
+	r := NewRegistry(&Config{DisableRuntimeMetrics: true})
+	r.Counter(Opts{Name: "conflict"})
+	assert.Panics(t, func() { r.Gauge(Opts{Name: "conflict"}) })
+}
+
+func TestRuntimeMetrics(t *testing.T) {
+	r := NewRegistry(nil)
+	assert.NotNil(t, gather(t, r)["go_goroutines"])
+
+	r.setRuntimeMetrics(false)
+	assert.Nil(t, gather(t, r)["go_goroutines"])
+}
+
+func TestLegacyClient(t *testing.T) {
+	client := GetSingleton().WithCtx(context.Background())
+	client.IncreaseCounter(CounterApiErrors, map[string]string{"endpointName": "GET /orders", "statusCode": "500"})
+	client.SetGaugeValue("undeclared", 1, nil)
+
+	metric := gather(t, Default())[string(CounterApiErrors)].Metric[0]
+	assert.Equal(t, float64(1), metric.GetCounter().GetValue())
+	assert.Equal(t, "500", labels(metric)["statusCode"])
+	assert.Equal(t, hostname, labels(metric)["hostname"])
+
+	RequestTimeGauge.With(prometheus.Labels{"endpointName": "GET /orders", "hostname": "h", "env": "e", "taskSlot": "1"}).Set(12)
+	assert.Equal(t, float64(12), gather(t, Default())[string(GaugeMetricNameRequestTime)].Metric[0].GetGauge().GetValue())
+}
+
+func TestHandlerGlobalMetrics(t *testing.T) {
+	global := prometheus.NewCounter(prometheus.CounterOpts{Name: "global_jobs_total", Help: "Jobs"})
+	prometheus.MustRegister(global)
+	defer prometheus.Unregister(global)
+	global.Inc()
+	Counter(Opts{Name: "default_jobs_total", Help: "Jobs"}).Inc()
+
+	res := httptest.NewRecorder()
+	Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+	body := res.Body.String()
+	assert.True(t, strings.Contains(body, "global_jobs_total 1"))
+	assert.True(t, strings.Contains(body, "default_jobs_total 1"))
+	assert.Equal(t, 1, strings.Count(body, "# TYPE go_goroutines "))
+}
+
+func gather(t *testing.T, r *Registry) map[string]*dto.MetricFamily {
+	families, err := r.Gather()
+	assert.NoError(t, err)
+	ret := map[string]*dto.MetricFamily{}
+	for _, f := range families {
+		ret[f.GetName()] = f
+	}
+	return ret
+}
+
+func labels(m *dto.Metric) map[string]string {
+	ret := map[string]string{}
+	for _, l := range m.Label {
+		ret[l.GetName()] = l.GetValue()
+	}
+	return ret
+}
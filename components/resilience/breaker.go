@@ -0,0 +1,242 @@
+package resilience
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/logx"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ ICircuitBreaker = (*breaker)(nil)
+)
+
+// NewBreaker creates a new circuit breaker with the provided configuration. Breakers created with this
+// function are not tracked by the registry, use `Breaker` to obtain a shared breaker for a dependency.
+func NewBreaker(name string, cfg BreakerConfig) ICircuitBreaker {
+	cfg = cfg.normalized()
+	return &breaker{
+		name:     name,
+		cfg:      cfg,
+		outcomes: make([]outcome, cfg.WindowSize),
+		now:      time.Now,
+	}
+}
+
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
+type breaker struct {
+	name string
+	cfg  BreakerConfig
+	mux  sync.Mutex
+	now  func() time.Time
+
+	state      State
+	generation int
+	openedAt   time.Time
+
+	// Sliding window of the most recent outcomes, used while closed
+	outcomes []outcome
+	pos      int
+	count    int
+	failures int
+	slow     int
+
+	// Trial calls, used while half-open
+	trials    int
+	successes int
+}
+
+func (b *breaker) Name() string {
+	return b.name
+}
+
+func (b *breaker) State() State {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.checkOpenTimeout()
+	return b.state
+}
+
+func (b *breaker) Allow() (func(success bool), error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.checkOpenTimeout()
+
+	switch b.state {
+	case StateOpen:
+		breakerRejections.Inc(b.name)
+		return nil, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.trials >= b.cfg.HalfOpenCalls {
+			breakerRejections.Inc(b.name)
+			return nil, ErrCircuitOpen
+		}
+		b.trials++
+	}
+
+	generation, start := b.generation, b.now()
+	var once sync.Once
+
+	return func(success bool) {
+		once.Do(func() {
+			b.record(generation, success, b.now().Sub(start))
+		})
+	}, nil
+}
+
+func (b *breaker) Execute(fn func() error, isFailure ...func(error) bool) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	if err != nil && len(isFailure) > 0 && isFailure[0] != nil {
+		done(!isFailure[0](err))
+	} else {
+		done(err == nil)
+	}
+	return err
+}
+
+func (b *breaker) Reset() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.transition(StateClosed)
+}
+
+func (b *breaker) Snapshot() BreakerSnapshot {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.checkOpenTimeout()
+
+	ret := BreakerSnapshot{
+		Name:  b.name,
+		State: b.state,
+		Calls: b.count,
+	}
+	if b.count > 0 {
+		ret.FailureRatio = float64(b.failures) / float64(b.count)
+		ret.SlowRatio = float64(b.slow) / float64(b.count)
+	}
+	if b.state == StateOpen {
+		ret.OpenedAt = b.openedAt.UTC().Format(time.RFC3339)
+	}
+	return ret
+}
+
+func (b *breaker) record(generation int, success bool, elapsed time.Duration) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	// The state changed while the call was in progress, the outcome is no longer relevant
+	if generation != b.generation {
+		return
+	}
+
+	slow := b.cfg.SlowCallDuration > 0 && elapsed >= time.Duration(b.cfg.SlowCallDuration)*time.Millisecond
+
+	switch b.state {
+	case StateHalfOpen:
+		if !success || (slow && b.cfg.SlowCallRatio > 0) {
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenCalls {
+			b.transition(StateClosed)
+		}
+
+	case StateClosed:
+		b.push(outcome{failed: !success, slow: slow})
+		if b.count < b.cfg.MinCalls {
+			return
+		}
+		failureRatio := float64(b.failures) / float64(b.count)
+		slowRatio := float64(b.slow) / float64(b.count)
+		if failureRatio >= b.cfg.FailureRatio || (b.cfg.SlowCallRatio > 0 && slowRatio >= b.cfg.SlowCallRatio) {
+			b.transition(StateOpen)
+		}
+	}
+}
+
+func (b *breaker) push(o outcome) {
+	if b.count == len(b.outcomes) {
+		old := b.outcomes[b.pos]
+		if old.failed {
+			b.failures--
+		}
+		if old.slow {
+			b.slow--
+		}
+	} else {
+		b.count++
+	}
+	if o.failed {
+		b.failures++
+	}
+	if o.slow {
+		b.slow++
+	}
+	b.outcomes[b.pos] = o
+	b.pos = (b.pos + 1) % len(b.outcomes)
+}
+
+// checkOpenTimeout moves the breaker to half-open if the open timeout has elapsed. Must be invoked
+// while holding the lock.
+func (b *breaker) checkOpenTimeout() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= time.Duration(b.cfg.OpenTimeout)*time.Millisecond {
+		b.transition(StateHalfOpen)
+	}
+}
+
+// transition changes the state of the breaker and resets the stats of the previous state. Must be
+// invoked while holding the lock.
+func (b *breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.generation++
+	b.trials, b.successes = 0, 0
+	b.pos, b.count, b.failures, b.slow = 0, 0, 0, 0
+
+	if to == StateOpen {
+		b.openedAt = b.now()
+	}
+	if from == to {
+		return
+	}
+
+	reportTransition(b.name, from, to)
+
+	msg := "circuit breaker '%s' changed state from %s to %s"
+	if to == StateOpen {
+		logx.Warnf(msg, b.name, from, to)
+	} else {
+		logx.Infof(msg, b.name, from, to)
+	}
+}
+
+func (c BreakerConfig) normalized() BreakerConfig {
+	def := defaultBreakerConfig()
+	if c.WindowSize <= 0 {
+		c.WindowSize = def.WindowSize
+	}
+	if c.MinCalls <= 0 || c.MinCalls > c.WindowSize {
+		c.MinCalls = c.WindowSize
+	}
+	if c.FailureRatio <= 0 || c.FailureRatio > 1 {
+		c.FailureRatio = def.FailureRatio
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = def.OpenTimeout
+	}
+	if c.HalfOpenCalls <= 0 {
+		c.HalfOpenCalls = def.HalfOpenCalls
+	}
+	return c
+}
@@ -0,0 +1,101 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-testx/assert"
+)
+
+var errTest = errors.New("test error")
+
+func TestBreakerOpensOnFailureRatio(t *testing.T) {
+	b := newTestBreaker()
+
+	for i := 0; i < 4; i++ {
+		_ = b.Execute(func() error { return nil })
+	}
+	assert.Equal(t, StateClosed, b.State())
+
+	for i := 0; i < 4; i++ {
+		_ = b.Execute(func() error { return errTest })
+	}
+	assert.Equal(t, StateOpen, b.State())
+	assert.Equal(t, ErrCircuitOpen, b.Execute(func() error { return nil }))
+}
+
+func TestBreakerIgnoresNonFailures(t *testing.T) {
+	b := newTestBreaker()
+	notFailure := func(error) bool { return false }
+
+	for i := 0; i < 10; i++ {
+		_ = b.Execute(func() error { return errTest }, notFailure)
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	b := newTestBreaker()
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 8; i++ {
+		_ = b.Execute(func() error { return errTest })
+	}
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(time.Second)
+	assert.Equal(t, StateHalfOpen, b.State())
+
+	// A failed trial call opens the breaker again
+	_ = b.Execute(func() error { return errTest })
+	assert.Equal(t, StateOpen, b.State())
+
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.Execute(func() error { return nil }))
+	}
+	assert.Equal(t, StateClosed, b.State())
+}
+
+func TestBreakerOpensOnSlowCalls(t *testing.T) {
+	b := newTestBreaker()
+	b.cfg.SlowCallRatio = 0.5
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 8; i++ {
+		_ = b.Execute(func() error {
+			now = now.Add(200 * time.Millisecond)
+			return nil
+		})
+	}
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestBulkheadRejectsWhenFull(t *testing.T) {
+	b := NewBulkhead("test", BulkheadConfig{MaxConcurrent: 1})
+
+	release, err := b.Acquire(context.Background())
+	assert.NoError(t, err)
+
+	_, err = b.Acquire(context.Background())
+	assert.Equal(t, ErrBulkheadFull, err)
+
+	release()
+	assert.NoError(t, b.Execute(context.Background(), func() error { return nil }))
+	assert.Equal(t, 0, b.Snapshot().InUse)
+}
+
+func newTestBreaker() *breaker {
+	return NewBreaker("test", BreakerConfig{
+		WindowSize:       10,
+		MinCalls:         8,
+		FailureRatio:     0.5,
+		SlowCallDuration: 100,
+		OpenTimeout:      1000,
+		HalfOpenCalls:    2,
+	}).(*breaker)
+}
@@ -0,0 +1,89 @@
+package resilience
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ IBulkhead = (*bulkhead)(nil)
+)
+
+// NewBulkhead creates a new bulkhead with the provided configuration. Bulkheads created with this
+// function are not tracked by the registry, use `Bulkhead` to obtain a shared bulkhead for a dependency.
+func NewBulkhead(name string, cfg BulkheadConfig) IBulkhead {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultBulkheadConfig().MaxConcurrent
+	}
+	return &bulkhead{
+		name:  name,
+		cfg:   cfg,
+		slots: make(chan struct{}, cfg.MaxConcurrent),
+	}
+}
+
+type bulkhead struct {
+	name  string
+	cfg   BulkheadConfig
+	slots chan struct{}
+}
+
+func (b *bulkhead) Name() string {
+	return b.name
+}
+
+func (b *bulkhead) Acquire(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+		return b.release(), nil
+	default:
+	}
+
+	if b.cfg.MaxWait <= 0 {
+		bulkheadRejections.Inc(b.name)
+		return nil, ErrBulkheadFull
+	}
+
+	timer := time.NewTimer(time.Duration(b.cfg.MaxWait) * time.Millisecond)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return b.release(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		bulkheadRejections.Inc(b.name)
+		return nil, ErrBulkheadFull
+	}
+}
+
+func (b *bulkhead) Execute(ctx context.Context, fn func() error) error {
+	release, err := b.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return fn()
+}
+
+func (b *bulkhead) Snapshot() BulkheadSnapshot {
+	return BulkheadSnapshot{
+		Name:          b.name,
+		InUse:         len(b.slots),
+		MaxConcurrent: b.cfg.MaxConcurrent,
+	}
+}
+
+func (b *bulkhead) release() func() {
+	bulkheadInUse.Inc(b.name)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-b.slots
+			bulkheadInUse.Dec(b.name)
+		})
+	}
+}
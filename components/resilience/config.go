@@ -0,0 +1,123 @@
+package resilience
+
+import (
+	"sync"
+
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/logx"
+)
+
+const (
+	configKey  = "resilience"
+	configName = "resilience-cfg"
+
+	// DefaultName is the key of the configuration used for dependencies without a specific configuration
+	DefaultName = "default"
+)
+
+var (
+	singleton = defaultConfig()
+	cfgMux    sync.RWMutex
+)
+
+// Config contains the circuit breaker and bulkhead configuration for each dependency. The `default`
+// entries apply to any dependency without a specific configuration.
+type Config struct {
+	Breakers  map[string]*BreakerConfig  `json:"breakers,omitempty"  yaml:"breakers,omitempty"`
+	Bulkheads map[string]*BulkheadConfig `json:"bulkheads,omitempty" yaml:"bulkheads,omitempty"`
+}
+
+type BreakerConfig struct {
+	// WindowSize is the amount of most recent calls used to calculate the failure and slow call ratios
+	WindowSize int `json:"window_size" yaml:"window_size" default:"20"`
+
+	// MinCalls is the minimum amount of calls in the window before the ratios are evaluated
+	MinCalls int `json:"min_calls" yaml:"min_calls" default:"10"`
+
+	// FailureRatio is the ratio (0 to 1) of failed calls in the window that opens the breaker
+	FailureRatio float64 `json:"failure_ratio" yaml:"failure_ratio" default:"0.5"`
+
+	// SlowCallRatio is the ratio (0 to 1) of slow calls in the window that opens the breaker. Zero disables it
+	SlowCallRatio float64 `json:"slow_call_ratio" yaml:"slow_call_ratio"`
+
+	// SlowCallDuration is the duration in milliseconds from which a call is considered slow
+	SlowCallDuration int64 `json:"slow_call_duration" yaml:"slow_call_duration" default:"5000"`
+
+	// OpenTimeout is the time in milliseconds the breaker stays open before allowing trial calls
+	OpenTimeout int64 `json:"open_timeout" yaml:"open_timeout" default:"30000"`
+
+	// HalfOpenCalls is the amount of trial calls allowed while half-open
+	HalfOpenCalls int `json:"half_open_calls" yaml:"half_open_calls" default:"3"`
+}
+
+type BulkheadConfig struct {
+	// MaxConcurrent is the maximum amount of concurrent calls allowed to the dependency
+	MaxConcurrent int `json:"max_concurrent" yaml:"max_concurrent" default:"50"`
+
+	// MaxWait is the maximum time in milliseconds a call waits for an available slot. Zero rejects
+	// immediately when the bulkhead is full
+	MaxWait int64 `json:"max_wait" yaml:"max_wait"`
+}
+
+func init() {
+	configx.AddOnReloadCallback(func(cfg configx.IConfig) {
+		config := defaultConfig()
+
+		logx.WithObj(
+			cfg.MapToObj(configKey, config),
+		).Fatal("unable to map resilience configuration")
+
+		if config.Breakers[DefaultName] == nil {
+			config.Breakers[DefaultName] = defaultBreakerConfig()
+		}
+		if config.Bulkheads[DefaultName] == nil {
+			config.Bulkheads[DefaultName] = defaultBulkheadConfig()
+		}
+
+		cfgMux.Lock()
+		defer cfgMux.Unlock()
+		singleton = config
+	}, configName)
+}
+
+func breakerConfig(name string) BreakerConfig {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+	if c, ok := singleton.Breakers[name]; ok && c != nil {
+		return *c
+	}
+	return *singleton.Breakers[DefaultName]
+}
+
+func bulkheadConfig(name string) BulkheadConfig {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+	if c, ok := singleton.Bulkheads[name]; ok && c != nil {
+		return *c
+	}
+	return *singleton.Bulkheads[DefaultName]
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Breakers:  map[string]*BreakerConfig{DefaultName: defaultBreakerConfig()},
+		Bulkheads: map[string]*BulkheadConfig{DefaultName: defaultBulkheadConfig()},
+	}
+}
+
+func defaultBreakerConfig() *BreakerConfig {
+	return &BreakerConfig{
+		WindowSize:       20,
+		MinCalls:         10,
+		FailureRatio:     0.5,
+		SlowCallDuration: 5000,
+		OpenTimeout:      30000,
+		HalfOpenCalls:    3,
+	}
+}
+
+func defaultBulkheadConfig() *BulkheadConfig {
+	return &BulkheadConfig{
+		MaxConcurrent: 50,
+	}
+}
@@ -0,0 +1,13 @@
+package resilience
+
+import (
+	"github.com/jucardi/go-titan/errors"
+)
+
+var (
+	// ErrCircuitOpen is returned when a call is rejected by an open circuit breaker
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+
+	// ErrBulkheadFull is returned when a call is rejected because the bulkhead has no available slots
+	ErrBulkheadFull = errors.New("bulkhead is full")
+)
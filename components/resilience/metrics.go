@@ -0,0 +1,46 @@
+package resilience
+
+import (
+	"github.com/jucardi/go-titan/components/prometheus"
+)
+
+var (
+	breakerState = prometheus.Gauge(prometheus.Opts{
+		Name:   "circuit_breaker_state",
+		Help:   "Current state of a circuit breaker (0 closed, 1 half-open, 2 open)",
+		Labels: []string{"name"},
+	})
+
+	breakerTransitions = prometheus.Counter(prometheus.Opts{
+		Name:   "circuit_breaker_transitions_total",
+		Help:   "Total number of state transitions of a circuit breaker",
+		Labels: []string{"name", "from", "to"},
+	})
+
+	breakerRejections = prometheus.Counter(prometheus.Opts{
+		Name:   "circuit_breaker_rejections_total",
+		Help:   "Total number of calls rejected by a circuit breaker",
+		Labels: []string{"name"},
+	})
+
+	bulkheadInUse = prometheus.Gauge(prometheus.Opts{
+		Name:   "bulkhead_in_use",
+		Help:   "Current amount of concurrent calls in a bulkhead",
+		Labels: []string{"name"},
+	})
+
+	bulkheadRejections = prometheus.Counter(prometheus.Opts{
+		Name:   "bulkhead_rejections_total",
+		Help:   "Total number of calls rejected by a bulkhead",
+		Labels: []string{"name"},
+	})
+)
+
+func reportState(name string, state State) {
+	breakerState.Set(float64(state), name)
+}
+
+func reportTransition(name string, from, to State) {
+	reportState(name, to)
+	breakerTransitions.Inc(name, from.String(), to.String())
+}
@@ -0,0 +1,79 @@
+package resilience
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+var (
+	breakers  = map[string]ICircuitBreaker{}
+	bulkheads = map[string]IBulkhead{}
+	mux       sync.Mutex
+)
+
+// Breaker returns the shared circuit breaker for the provided dependency name, creating it if it does not
+// exist. The breaker uses the configuration for the dependency name or the `default` configuration.
+func Breaker(name string) ICircuitBreaker {
+	mux.Lock()
+	defer mux.Unlock()
+
+	if b, ok := breakers[name]; ok {
+		return b
+	}
+	b := NewBreaker(name, breakerConfig(name))
+	breakers[name] = b
+	reportState(name, StateClosed)
+	return b
+}
+
+// Bulkhead returns the shared bulkhead for the provided dependency name, creating it if it does not
+// exist. The bulkhead uses the configuration for the dependency name or the `default` configuration.
+func Bulkhead(name string) IBulkhead {
+	mux.Lock()
+	defer mux.Unlock()
+
+	if b, ok := bulkheads[name]; ok {
+		return b
+	}
+	b := NewBulkhead(name, bulkheadConfig(name))
+	bulkheads[name] = b
+	return b
+}
+
+// Execute runs the provided func protected by the bulkhead and the circuit breaker of the provided
+// dependency name. Any returned error is considered a failure of the dependency unless `isFailure` is
+// provided.
+func Execute(ctx context.Context, name string, fn func(ctx context.Context) error, isFailure ...func(error) bool) error {
+	return Bulkhead(name).Execute(ctx, func() error {
+		return Breaker(name).Execute(func() error {
+			return fn(ctx)
+		}, isFailure...)
+	})
+}
+
+// Breakers returns a snapshot of all the registered circuit breakers
+func Breakers() []BreakerSnapshot {
+	mux.Lock()
+	defer mux.Unlock()
+
+	var ret []BreakerSnapshot
+	for _, b := range breakers {
+		ret = append(ret, b.Snapshot())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// Bulkheads returns a snapshot of all the registered bulkheads
+func Bulkheads() []BulkheadSnapshot {
+	mux.Lock()
+	defer mux.Unlock()
+
+	var ret []BulkheadSnapshot
+	for _, b := range bulkheads {
+		ret = append(ret, b.Snapshot())
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
@@ -0,0 +1,94 @@
+package resilience
+
+import (
+	"context"
+)
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+// State is the state of a circuit breaker
+type State int
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// MarshalText implements encoding.TextMarshaler so states are serialized by name
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// ICircuitBreaker defines a circuit breaker which stops calls to a dependency when the ratio of failed
+// or slow calls exceeds the configured thresholds.
+//
+//  - closed:    calls are allowed and their outcomes are recorded in a sliding window.
+//  - open:      calls are rejected with `ErrCircuitOpen` until the open timeout elapses.
+//  - half-open: a limited amount of trial calls are allowed. If all of them succeed the breaker closes,
+//               otherwise it opens again.
+type ICircuitBreaker interface {
+	// Name returns the name of the dependency protected by the breaker
+	Name() string
+
+	// State returns the current state of the breaker
+	State() State
+
+	// Allow reserves a call. Returns `ErrCircuitOpen` if the call is not permitted, otherwise the
+	// returned `done` func must be invoked with the outcome of the call once it finishes.
+	Allow() (done func(success bool), err error)
+
+	// Execute runs the provided func if the breaker allows it. Any returned error is considered a failure
+	// unless `isFailure` is provided.
+	Execute(fn func() error, isFailure ...func(error) bool) error
+
+	// Reset forces the breaker into the closed state and clears its sliding window
+	Reset()
+
+	// Snapshot returns the current stats of the breaker
+	Snapshot() BreakerSnapshot
+}
+
+// IBulkhead defines a concurrency limiter for calls to a dependency
+type IBulkhead interface {
+	// Name returns the name of the dependency protected by the bulkhead
+	Name() string
+
+	// Acquire reserves a slot, waiting up to the configured max wait. Returns `ErrBulkheadFull` if no
+	// slot was available in time. The returned `release` func must be invoked when the call finishes.
+	Acquire(ctx context.Context) (release func(), err error)
+
+	// Execute runs the provided func if a slot can be acquired.
+	Execute(ctx context.Context, fn func() error) error
+
+	// Snapshot returns the current stats of the bulkhead
+	Snapshot() BulkheadSnapshot
+}
+
+// BreakerSnapshot contains the stats of a circuit breaker at a given time
+type BreakerSnapshot struct {
+	Name         string  `json:"name"          yaml:"name"`
+	State        State   `json:"state"         yaml:"state"`
+	Calls        int     `json:"calls"         yaml:"calls"`
+	FailureRatio float64 `json:"failure_ratio" yaml:"failure_ratio"`
+	SlowRatio    float64 `json:"slow_ratio"    yaml:"slow_ratio"`
+	OpenedAt     string  `json:"opened_at,omitempty" yaml:"opened_at,omitempty"`
+}
+
+// BulkheadSnapshot contains the stats of a bulkhead at a given time
+type BulkheadSnapshot struct {
+	Name          string `json:"name"           yaml:"name"`
+	InUse         int    `json:"in_use"         yaml:"in_use"`
+	MaxConcurrent int    `json:"max_concurrent" yaml:"max_concurrent"`
+}
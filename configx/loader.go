@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"regexp"
+	"sync"
 	"time"
 
 	"github.com/jucardi/go-streams/streams"
@@ -224,6 +225,41 @@ type remoteLoader struct {
 	format  ConfigFormat
 	url     string
 	freq    time.Duration
+	status  RemoteStatus
+	mux     sync.RWMutex
+}
+
+// RemoteStatus is the status of the remote configuration pulls
+type RemoteStatus struct {
+	// Enabled indicates whether the configuration is loaded from a remote URL
+	Enabled bool `json:"enabled"`
+
+	// URL is the remote URL the configuration is pulled from
+	URL string `json:"url,omitempty"`
+
+	// Frequency is the interval between pulls. Zero means the configuration is only pulled once
+	Frequency time.Duration `json:"frequency,omitempty"`
+
+	// LastAttempt is the time of the most recent pull
+	LastAttempt time.Time `json:"last_attempt,omitempty"`
+
+	// LastSuccess is the time of the most recent successful pull
+	LastSuccess time.Time `json:"last_success,omitempty"`
+
+	// LastError is the error of the most recent pull, empty if it succeeded
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Remote returns the status of the remote configuration pulls. `Enabled` is false if the configuration
+// was not loaded from a remote URL.
+func Remote() RemoteStatus {
+	l := loader
+	if l == nil {
+		return RemoteStatus{}
+	}
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+	return l.status
 }
 
 func (r *remoteLoader) start() error {
@@ -254,7 +290,8 @@ func (r *remoteLoader) ticker() {
 	}
 }
 
-func (r *remoteLoader) triggerRemotePull() error {
+func (r *remoteLoader) triggerRemotePull() (err error) {
+	defer r.report(time.Now(), &err)
 	if data, err := r.handler(r.url); err != nil {
 		return err
 	} else {
@@ -262,6 +299,18 @@ func (r *remoteLoader) triggerRemotePull() error {
 	}
 }
 
+func (r *remoteLoader) report(at time.Time, err *error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.status.LastAttempt = at
+	if *err != nil {
+		r.status.LastError = (*err).Error()
+		return
+	}
+	r.status.LastSuccess = at
+	r.status.LastError = ""
+}
+
 func newRemoteLoader(url string, format ConfigFormat, handler ...RemotePullHandler) (*remoteLoader, error) {
 	if err := validateFormat(format); err != nil {
 		return nil, err
@@ -273,6 +322,7 @@ func newRemoteLoader(url string, format ConfigFormat, handler ...RemotePullHandl
 		url:     url,
 		freq:    DefaultRemoteFreq,
 	}
+	ret.status = RemoteStatus{Enabled: true, URL: url, Frequency: ret.freq}
 
 	if len(handler) > 0 && handler[0] != nil {
 		ret.handler = handler[0]
@@ -0,0 +1,60 @@
+package authx
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+type apiKeyAuthenticator struct {
+	header string
+	keys   []APIKey
+	hashes [][]byte
+}
+
+func newAPIKeyAuthenticator(cfg APIKeyConfig) *apiKeyAuthenticator {
+	ret := &apiKeyAuthenticator{header: cfg.Header, keys: cfg.Keys}
+	if ret.header == "" {
+		ret.header = DefaultConfig().APIKey.Header
+	}
+	for _, k := range cfg.Keys {
+		ret.hashes = append(ret.hashes, hashKey(k.Key))
+	}
+	return ret
+}
+
+func (a *apiKeyAuthenticator) Method() string {
+	return MethodAPIKey
+}
+
+func (a *apiKeyAuthenticator) Applies(r *http.Request) bool {
+	return r.Header.Get(a.header) != ""
+}
+
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	// Comparing fixed length hashes in constant time so the time taken does not leak the keys
+	h := hashKey(r.Header.Get(a.header))
+	match := -1
+	for i := range a.hashes {
+		if subtle.ConstantTimeCompare(h, a.hashes[i]) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, errorx.NewUnauthorized("invalid api key")
+	}
+	k := a.keys[match]
+	return &Principal{
+		Subject: k.Subject,
+		Method:  MethodAPIKey,
+		Scopes:  k.Scopes,
+		Roles:   k.Roles,
+	}, nil
+}
+
+func hashKey(key string) []byte {
+	h := sha256.Sum256([]byte(key))
+	return h[:]
+}
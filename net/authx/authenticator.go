@@ -0,0 +1,98 @@
+package authx
+
+import (
+	"net/http"
+
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+// Authenticator authenticates requests using the configured methods
+type Authenticator struct {
+	methods  []IAuthenticator
+	required bool
+}
+
+// New creates an Authenticator with the methods enabled in the provided configuration
+func New(cfg *Config) (*Authenticator, error) {
+	ret := &Authenticator{required: cfg.Required}
+
+	if cfg.JWT.Enabled {
+		j, err := newJWTAuthenticator(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		ret.methods = append(ret.methods, j)
+	}
+	if cfg.APIKey.Enabled {
+		ret.methods = append(ret.methods, newAPIKeyAuthenticator(cfg.APIKey))
+	}
+	if cfg.HMAC.Enabled {
+		ret.methods = append(ret.methods, newHMACAuthenticator(cfg.HMAC))
+	}
+	if cfg.ClientCert.Enabled {
+		ret.methods = append(ret.methods, newClientCertAuthenticator(cfg.ClientCert))
+	}
+	return ret, nil
+}
+
+// Use adds custom authentication methods. Methods are evaluated in the order they were added.
+func (a *Authenticator) Use(methods ...IAuthenticator) *Authenticator {
+	a.methods = append(a.methods, methods...)
+	return a
+}
+
+// Enabled indicates whether any authentication method is enabled
+func (a *Authenticator) Enabled() bool {
+	return a != nil && len(a.methods) > 0
+}
+
+// Required indicates whether requests without credentials are rejected
+func (a *Authenticator) Required() bool {
+	return a != nil && a.required
+}
+
+// Methods returns the names of the enabled authentication methods
+func (a *Authenticator) Methods() []string {
+	var ret []string
+	for _, m := range a.methods {
+		ret = append(ret, m.Method())
+	}
+	return ret
+}
+
+// APIKeyHeader returns the header that contains the API key, or empty if API keys are not enabled
+func (a *Authenticator) APIKeyHeader() string {
+	for _, m := range a.methods {
+		if k, ok := m.(*apiKeyAuthenticator); ok {
+			return k.header
+		}
+	}
+	return ""
+}
+
+// Authenticate authenticates the request with the first method for which the request carries credentials.
+// Returns a nil principal if the request carries no credentials and authentication is not required.
+//
+// Failures are returned as `errorx.NewUnauthorized`.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if !a.Enabled() {
+		return nil, nil
+	}
+	for _, m := range a.methods {
+		if !m.Applies(r) {
+			continue
+		}
+		p, err := m.Authenticate(r)
+		if err != nil {
+			if _, ok := err.(*errorx.Error); !ok {
+				err = errorx.WrapUnauthorized(err)
+			}
+			return nil, err
+		}
+		return p, nil
+	}
+	if a.required {
+		return nil, errorx.NewUnauthorized("missing credentials")
+	}
+	return nil, nil
+}
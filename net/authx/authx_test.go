@@ -0,0 +1,250 @@
+package authx
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+const testSecret = "super-secret"
+
+func TestJWTHmac(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.JWT.Enabled = true
+	cfg.JWT.Secret = testSecret
+	cfg.JWT.Issuer = "https://issuer.example.com"
+	cfg.JWT.Audience = []string{"orders"}
+	a, err := New(cfg)
+	assert.NoError(t, err)
+
+	claims := map[string]interface{}{
+		"sub":   "user-1",
+		"iss":   "https://issuer.example.com",
+		"aud":   []string{"orders", "payments"},
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"scope": "orders:read orders:write",
+		"roles": []string{"admin"},
+	}
+	p, err := a.Authenticate(bearer(sign(t, "HS256", "", []byte(testSecret), claims)))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", p.Subject)
+	assert.Equal(t, MethodJWT, p.Method)
+	assert.True(t, p.HasScope("orders:write"))
+	assert.True(t, p.HasRole("admin"))
+
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, err = a.Authenticate(bearer(sign(t, "HS256", "", []byte(testSecret), claims)))
+	assert.True(t, errorx.IsUnauthorized(err))
+
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	claims["aud"] = "billing"
+	_, err = a.Authenticate(bearer(sign(t, "HS256", "", []byte(testSecret), claims)))
+	assert.True(t, errorx.IsUnauthorized(err))
+
+	claims["aud"] = "orders"
+	_, err = a.Authenticate(bearer(sign(t, "HS256", "", []byte("wrong"), claims)))
+	assert.True(t, errorx.IsUnauthorized(err))
+
+	_, err = a.Authenticate(bearer("not-a-token"))
+	assert.True(t, errorx.IsUnauthorized(err))
+}
+
+func TestJWTRsaFromJWKSFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	jwks := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"k1","use":"sig","n":"%s","e":"%s"}]}`,
+		b64(key.N.Bytes()), b64(big.NewInt(int64(key.E)).Bytes()))
+	assert.NoError(t, os.WriteFile(file, []byte(jwks), 0o600))
+
+	cfg := DefaultConfig()
+	cfg.JWT.Enabled = true
+	cfg.JWT.JWKSFile = file
+	a, err := New(cfg)
+	assert.NoError(t, err)
+
+	claims := map[string]interface{}{"sub": "svc", "exp": time.Now().Add(time.Minute).Unix()}
+	p, err := a.Authenticate(bearer(sign(t, "RS256", "k1", key, claims)))
+	assert.NoError(t, err)
+	assert.Equal(t, "svc", p.Subject)
+
+	// An unknown key id must not be resolved to a different key
+	_, err = a.Authenticate(bearer(sign(t, "RS256", "k2", key, claims)))
+	assert.True(t, errorx.IsUnauthorized(err))
+
+	// An RSA public key must never be used as an HMAC secret
+	_, err = a.Authenticate(bearer(sign(t, "HS256", "k1", key.N.Bytes(), claims)))
+	assert.True(t, errorx.IsUnauthorized(err))
+}
+
+func TestJWTEcdsaFromJWKSUrl(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		_, _ = fmt.Fprintf(w, `{"keys":[{"kty":"EC","kid":"ec1","crv":"P-256","x":"%s","y":"%s"}]}`,
+			b64(key.X.FillBytes(make([]byte, 32))), b64(key.Y.FillBytes(make([]byte, 32))))
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.JWT.Enabled = true
+	cfg.JWT.JWKSUrl = server.URL
+	a, err := New(cfg)
+	assert.NoError(t, err)
+
+	claims := map[string]interface{}{"sub": "svc", "exp": time.Now().Add(time.Minute).Unix()}
+	for i := 0; i < 3; i++ {
+		p, err := a.Authenticate(bearer(sign(t, "ES256", "ec1", key, claims)))
+		assert.NoError(t, err)
+		assert.Equal(t, "svc", p.Subject)
+	}
+	assert.Equal(t, 1, fetches)
+}
+
+func TestAPIKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey.Enabled = true
+	cfg.APIKey.Keys = []APIKey{{Key: "key-1", Subject: "reporting", Scopes: []string{"reports:read"}}}
+	a, err := New(cfg)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "key-1")
+	p, err := a.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "reporting", p.Subject)
+	assert.True(t, p.HasScope("reports:read"))
+
+	req.Header.Set("X-API-Key", "key-2")
+	_, err = a.Authenticate(req)
+	assert.True(t, errorx.IsUnauthorized(err))
+}
+
+func TestHMAC(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HMAC.Enabled = true
+	cfg.HMAC.Keys = []HMACKey{{Id: "partner", Secret: testSecret, Subject: "partner-co"}}
+	a, err := New(cfg)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?page=1", bytes.NewBufferString(`{"id":1}`))
+	assert.NoError(t, SignRequest(req, "partner", testSecret))
+	p, err := a.Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "partner-co", p.Subject)
+
+	// The body must still be readable by the handlers
+	body, _ := ioutil.ReadAll(req.Body)
+	assert.Equal(t, `{"id":1}`, string(body))
+
+	req.Body = ioutil.NopCloser(bytes.NewBufferString(`{"id":2}`))
+	_, err = a.Authenticate(req)
+	assert.True(t, errorx.IsUnauthorized(err))
+}
+
+func TestRequired(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey.Enabled = true
+	a, err := New(cfg)
+	assert.NoError(t, err)
+
+	p, err := a.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NoError(t, err)
+	assert.Nil(t, p)
+
+	cfg.Required = true
+	a, err = New(cfg)
+	assert.NoError(t, err)
+	_, err = a.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, errorx.IsUnauthorized(err))
+}
+
+func bearer(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderAuthorization, schemeBearer+token)
+	return req
+}
+
+func sign(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := b64(h) + "." + b64(c)
+
+	hash := hashes[alg[2:]]
+	digest := hash.New()
+	digest.Write([]byte(input))
+
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.Hash(hash), digest.Sum(nil))
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, digest.Sum(nil))
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+	}
+	assert.NoError(t, err)
+	return input + "." + strings.TrimRight(b64(sig), "=")
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestRBACAuthorizer(t *testing.T) {
+	a := NewRBACAuthorizer(map[string][]string{"editor": {"orders:read", "orders:write"}})
+	p := &Principal{
+		Subject: "user-1",
+		Method:  MethodJWT,
+		Roles:   []string{"editor"},
+		Claims:  map[string]interface{}{"tenant": "acme", "groups": []interface{}{"eu", "us"}},
+	}
+
+	assert.NoError(t, a.Authorize(p, Requirement{}))
+	assert.NoError(t, a.Authorize(p, Requirement{Scopes: []string{"orders:write"}}))
+	assert.True(t, errorx.IsForbidden(a.Authorize(p, Requirement{Scopes: []string{"orders:delete"}})))
+	assert.True(t, errorx.IsForbidden(a.Authorize(p, Requirement{Roles: []string{"admin"}})))
+	assert.True(t, errorx.IsUnauthorized(a.Authorize(nil, Requirement{})))
+
+	assert.NoError(t, a.Authorize(p, Requirement{Policy: "role:admin or (scope:orders:read and claim:tenant=acme)"}))
+	assert.NoError(t, a.Authorize(p, Requirement{Policy: "claim:groups=us and not method:api_key"}))
+	assert.True(t, errorx.IsForbidden(a.Authorize(p, Requirement{Policy: "sub:user-2 || claim:tenant=other"})))
+
+	for _, expr := range []string{"", "role:", "role:admin and", "(role:admin", "unknown:x", "claim:tenant"} {
+		_, err := CompilePolicy(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,143 @@
+package authx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jucardi/go-streams/streams"
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+// Requirement is the security requirement of a route. A principal satisfies the requirement if it was
+// granted all the scopes, was assigned any of the roles (if any), and satisfies the policy expression
+// (if any). An empty requirement only requires the request to be authenticated.
+type Requirement struct {
+	// Scopes are the scopes required, all of them must be granted
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Roles are the accepted roles, at least one of them must be assigned
+	Roles []string `json:"roles,omitempty"`
+
+	// Policy is a policy expression the principal must satisfy. See `CompilePolicy`
+	Policy string `json:"policy,omitempty"`
+}
+
+// String returns a readable description of the requirement
+func (r Requirement) String() string {
+	var parts []string
+	if len(r.Scopes) > 0 {
+		parts = append(parts, "scopes="+strings.Join(r.Scopes, ","))
+	}
+	if len(r.Roles) > 0 {
+		parts = append(parts, "roles="+strings.Join(r.Roles, ","))
+	}
+	if r.Policy != "" {
+		parts = append(parts, "policy="+r.Policy)
+	}
+	if len(parts) == 0 {
+		return "authenticated"
+	}
+	return strings.Join(parts, " ")
+}
+
+// IAuthorizer decides whether a principal satisfies a security requirement
+type IAuthorizer interface {
+	// Authorize returns nil if the principal satisfies the requirement. Failures should be returned
+	// as `errorx.NewForbidden`.
+	Authorize(p *Principal, req Requirement) error
+}
+
+var (
+	authorizer IAuthorizer = NewRBACAuthorizer(nil)
+	custom     bool
+	authzMux   sync.RWMutex
+)
+
+// SetAuthorizer replaces the authorizer used to evaluate route security requirements
+func SetAuthorizer(a IAuthorizer) {
+	authzMux.Lock()
+	defer authzMux.Unlock()
+	authorizer = a
+	custom = true
+}
+
+// setDefaultAuthorizer replaces the authorizer unless a custom one was set
+func setDefaultAuthorizer(a IAuthorizer) {
+	authzMux.Lock()
+	defer authzMux.Unlock()
+	if !custom {
+		authorizer = a
+	}
+}
+
+// Authorizer returns the authorizer used to evaluate route security requirements. By default, a
+// `RBACAuthorizer` using the `auth.roles` configuration.
+func Authorizer() IAuthorizer {
+	authzMux.RLock()
+	defer authzMux.RUnlock()
+	return authorizer
+}
+
+// RBACAuthorizer is a role based authorizer. Scopes can be granted directly to the principal, or through
+// its roles using the role to scopes mapping.
+type RBACAuthorizer struct {
+	roles    map[string][]string
+	policies sync.Map
+}
+
+// NewRBACAuthorizer creates a role based authorizer, where `roles` maps each role to the scopes it grants
+func NewRBACAuthorizer(roles map[string][]string) *RBACAuthorizer {
+	return &RBACAuthorizer{roles: roles}
+}
+
+func (a *RBACAuthorizer) Authorize(p *Principal, req Requirement) error {
+	if p == nil {
+		return errorx.NewUnauthorized("authentication required")
+	}
+
+	for _, s := range req.Scopes {
+		if !a.hasScope(p, s) {
+			return errorx.NewForbidden(fmt.Sprintf("missing required scope '%s'", s))
+		}
+	}
+	if len(req.Roles) > 0 && !streams.From(req.Roles).AnyMatch(func(i interface{}) bool { return p.HasRole(i.(string)) }) {
+		return errorx.NewForbidden("missing required role")
+	}
+	if req.Policy == "" {
+		return nil
+	}
+
+	policy, err := a.policy(req.Policy)
+	if err != nil {
+		return errorx.WrapUnhandled(err, "invalid policy")
+	}
+	if !policy.eval(a, p) {
+		return errorx.NewForbidden("access denied by policy")
+	}
+	return nil
+}
+
+func (a *RBACAuthorizer) hasScope(p *Principal, scope string) bool {
+	if p.HasScope(scope) {
+		return true
+	}
+	for _, r := range p.Roles {
+		if streams.From(a.roles[r]).Contains(scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *RBACAuthorizer) policy(expr string) (*Policy, error) {
+	if v, ok := a.policies.Load(expr); ok {
+		return v.(*Policy), nil
+	}
+	p, err := CompilePolicy(expr)
+	if err != nil {
+		return nil, err
+	}
+	a.policies.Store(expr, p)
+	return p, nil
+}
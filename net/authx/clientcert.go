@@ -0,0 +1,57 @@
+package authx
+
+import (
+	"net/http"
+
+	"github.com/jucardi/go-titan/net/tlsx"
+)
+
+type clientCertAuthenticator struct {
+	identities map[string]CertIdentity
+}
+
+func newClientCertAuthenticator(cfg ClientCertConfig) *clientCertAuthenticator {
+	ret := &clientCertAuthenticator{identities: map[string]CertIdentity{}}
+	for _, i := range cfg.Identities {
+		ret.identities[i.Subject] = i
+	}
+	return ret
+}
+
+func (a *clientCertAuthenticator) Method() string {
+	return MethodClientCert
+}
+
+func (a *clientCertAuthenticator) Applies(r *http.Request) bool {
+	return tlsx.PeerIdentity(r.TLS) != nil
+}
+
+func (a *clientCertAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	id := tlsx.PeerIdentity(r.TLS)
+	ret := &Principal{
+		Subject: id.Subject,
+		Method:  MethodClientCert,
+		Claims: map[string]interface{}{
+			"cn":          id.CommonName,
+			"o":           toInterfaces(id.Organization),
+			"dns":         toInterfaces(id.DNSNames),
+			"uri":         toInterfaces(id.URIs),
+			"email":       toInterfaces(id.Emails),
+			"issuer":      id.Issuer,
+			"fingerprint": id.Fingerprint,
+		},
+	}
+	if i, ok := a.identities[id.Subject]; ok {
+		ret.Scopes = i.Scopes
+		ret.Roles = i.Roles
+	}
+	return ret, nil
+}
+
+func toInterfaces(values []string) []interface{} {
+	ret := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		ret = append(ret, v)
+	}
+	return ret
+}
@@ -0,0 +1,177 @@
+package authx
+
+import (
+	"sync"
+
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/logx"
+)
+
+const (
+	configKey  = "auth"
+	configName = "auth-cfg"
+)
+
+// Config is the authentication configuration. Each method is only used if enabled.
+type Config struct {
+	// Required indicates whether requests without credentials should be rejected. When false, requests
+	// without credentials are handled as anonymous, but invalid credentials are always rejected.
+	Required bool `json:"required" yaml:"required"`
+
+	// Roles maps each role to the scopes it grants, used by the default role based authorizer
+	Roles map[string][]string `json:"roles,omitempty" yaml:"roles,omitempty"`
+
+	JWT        JWTConfig        `json:"jwt"         yaml:"jwt"`
+	APIKey     APIKeyConfig     `json:"api_key"     yaml:"api_key"`
+	HMAC       HMACConfig       `json:"hmac"        yaml:"hmac"`
+	ClientCert ClientCertConfig `json:"client_cert" yaml:"client_cert"`
+}
+
+// JWTConfig is the configuration of the bearer JWT authentication
+type JWTConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Algorithms restricts the accepted signing algorithms (e.g. "RS256", "ES256"). If empty, any supported
+	// algorithm for which a key is configured is accepted.
+	Algorithms []string `json:"algorithms,omitempty" yaml:"algorithms,omitempty"`
+
+	// Secret is the shared secret used to verify HS256, HS384 and HS512 tokens
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// PublicKeyFile is the path to a PEM encoded RSA or ECDSA public key used when the token key can't be
+	// resolved from a JWKS
+	PublicKeyFile string `json:"public_key_file,omitempty" yaml:"public_key_file,omitempty"`
+
+	// JWKSFile is the path to a JSON Web Key Set file
+	JWKSFile string `json:"jwks_file,omitempty" yaml:"jwks_file,omitempty"`
+
+	// JWKSUrl is the URL of a JSON Web Key Set. Takes precedence over `JWKSFile`
+	JWKSUrl string `json:"jwks_url,omitempty" yaml:"jwks_url,omitempty"`
+
+	// JWKSRefresh is the time in milliseconds the key set is cached before being reloaded
+	JWKSRefresh int64 `json:"jwks_refresh" yaml:"jwks_refresh" default:"300000"`
+
+	// Issuer is the expected `iss` claim. Not validated if empty
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+
+	// Audience contains the accepted `aud` values. The token must contain at least one of them. Not
+	// validated if empty
+	Audience []string `json:"audience,omitempty" yaml:"audience,omitempty"`
+
+	// Leeway is the clock skew in milliseconds tolerated when validating `exp` and `nbf`
+	Leeway int64 `json:"leeway" yaml:"leeway" default:"5000"`
+
+	// ScopesClaim is the claim that contains the scopes, either as a space separated string or an array
+	ScopesClaim string `json:"scopes_claim" yaml:"scopes_claim" default:"scope"`
+
+	// RolesClaim is the claim that contains the roles, either as a space separated string or an array
+	RolesClaim string `json:"roles_claim" yaml:"roles_claim" default:"roles"`
+}
+
+// APIKeyConfig is the configuration of the static API key authentication
+type APIKeyConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Header is the request header that contains the API key
+	Header string `json:"header" yaml:"header" default:"X-API-Key"`
+
+	// Keys are the accepted API keys
+	Keys []APIKey `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// APIKey is an accepted static API key and the principal it authenticates
+type APIKey struct {
+	Key     string   `json:"key"              yaml:"key"`
+	Subject string   `json:"subject"          yaml:"subject"`
+	Scopes  []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Roles   []string `json:"roles,omitempty"  yaml:"roles,omitempty"`
+}
+
+// HMACConfig is the configuration of the HMAC signed requests authentication. See `SignRequest` for the
+// signature scheme.
+type HMACConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// MaxSkew is the maximum difference in milliseconds between the signature timestamp and the server time
+	MaxSkew int64 `json:"max_skew" yaml:"max_skew" default:"300000"`
+
+	// Keys are the accepted signing keys
+	Keys []HMACKey `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
+// HMACKey is an accepted signing key and the principal it authenticates
+type HMACKey struct {
+	Id      string   `json:"id"               yaml:"id"`
+	Secret  string   `json:"secret"           yaml:"secret"`
+	Subject string   `json:"subject"          yaml:"subject"`
+	Scopes  []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Roles   []string `json:"roles,omitempty"  yaml:"roles,omitempty"`
+}
+
+// ClientCertConfig is the configuration of the authentication by verified client certificates (mTLS). The
+// certificates are verified by the TLS listener, see `tls.client_ca_file` in the REST configuration. The
+// certificate fields are available as the claims `cn`, `o`, `dns`, `uri`, `email`, `issuer` and `fingerprint`.
+type ClientCertConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Identities assigns scopes and roles to the certificate subjects. Verified certificates with other
+	// subjects are authenticated without scopes nor roles.
+	Identities []CertIdentity `json:"identities,omitempty" yaml:"identities,omitempty"`
+}
+
+// CertIdentity assigns scopes and roles to the subject of a client certificate, which is the first URI
+// SAN (e.g. a SPIFFE ID) if any, otherwise the common name
+type CertIdentity struct {
+	Subject string   `json:"subject"          yaml:"subject"`
+	Scopes  []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+	Roles   []string `json:"roles,omitempty"  yaml:"roles,omitempty"`
+}
+
+var (
+	singleton = &Authenticator{}
+	cfgMux    sync.RWMutex
+)
+
+func init() {
+	configx.AddOnReloadCallback(func(cfg configx.IConfig) {
+		config := DefaultConfig()
+
+		logx.WithObj(
+			cfg.MapToObj(configKey, config),
+		).Fatal("unable to map auth configuration")
+
+		a, err := New(config)
+		logx.WithObj(err).Fatal("unable to initialize the authentication")
+
+		setDefaultAuthorizer(NewRBACAuthorizer(config.Roles))
+
+		cfgMux.Lock()
+		defer cfgMux.Unlock()
+		singleton = a
+	}, configName)
+}
+
+// Default returns the authenticator created from the `auth` configuration
+func Default() *Authenticator {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+	return singleton
+}
+
+// DefaultConfig returns a configuration with the default values and all methods disabled
+func DefaultConfig() *Config {
+	return &Config{
+		JWT: JWTConfig{
+			JWKSRefresh: 300000,
+			Leeway:      5000,
+			ScopesClaim: "scope",
+			RolesClaim:  "roles",
+		},
+		APIKey: APIKeyConfig{
+			Header: "X-API-Key",
+		},
+		HMAC: HMACConfig{
+			MaxSkew: 300000,
+		},
+	}
+}
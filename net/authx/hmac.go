@@ -0,0 +1,145 @@
+package authx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+const (
+	// HeaderSignatureTimestamp is the header that contains the unix time in seconds when the request was signed
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+
+	// SchemeHMAC is the `Authorization` scheme of HMAC signed requests
+	SchemeHMAC = "HMAC-SHA256"
+)
+
+// SignRequest signs the request with the provided HMAC key. The signature is sent in the `Authorization`
+// header as
+//
+//	Authorization: HMAC-SHA256 Credential=<key id>, Signature=<signature>
+//
+// where the signature is the base64 encoded HMAC-SHA256 of the string
+//
+//	<METHOD>\n<request URI>\n<X-Signature-Timestamp>\n<hex encoded SHA256 of the body>
+func SignRequest(r *http.Request, keyId, secret string) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(HeaderSignatureTimestamp, ts)
+	sig := hmacSignature(secret, stringToSign(r, ts, body))
+	r.Header.Set(HeaderAuthorization, fmt.Sprintf("%s Credential=%s, Signature=%s", SchemeHMAC, keyId, sig))
+	return nil
+}
+
+type hmacAuthenticator struct {
+	maxSkew time.Duration
+	keys    map[string]HMACKey
+	now     func() time.Time
+}
+
+func newHMACAuthenticator(cfg HMACConfig) *hmacAuthenticator {
+	ret := &hmacAuthenticator{
+		maxSkew: time.Duration(cfg.MaxSkew) * time.Millisecond,
+		keys:    map[string]HMACKey{},
+		now:     time.Now,
+	}
+	for _, k := range cfg.Keys {
+		ret.keys[k.Id] = k
+	}
+	return ret
+}
+
+func (a *hmacAuthenticator) Method() string {
+	return MethodHMAC
+}
+
+func (a *hmacAuthenticator) Applies(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get(HeaderAuthorization), SchemeHMAC+" ")
+}
+
+func (a *hmacAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	params := parseParams(strings.TrimPrefix(r.Header.Get(HeaderAuthorization), SchemeHMAC+" "))
+	keyId, signature := params["Credential"], params["Signature"]
+	if keyId == "" || signature == "" {
+		return nil, errorx.NewUnauthorized("malformed signature")
+	}
+	key, ok := a.keys[keyId]
+	if !ok {
+		return nil, errorx.NewUnauthorized("invalid signature")
+	}
+
+	ts := r.Header.Get(HeaderSignatureTimestamp)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, errorx.NewUnauthorized("invalid signature timestamp")
+	}
+	if skew := a.now().Sub(time.Unix(unix, 0)); skew > a.maxSkew || skew < -a.maxSkew {
+		return nil, errorx.NewUnauthorized("signature expired")
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return nil, errorx.WrapUnauthorized(err, "unable to read the request body")
+	}
+	expected := hmacSignature(key.Secret, stringToSign(r, ts, body))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, errorx.NewUnauthorized("invalid signature")
+	}
+
+	return &Principal{
+		Subject: key.Subject,
+		Method:  MethodHMAC,
+		Scopes:  key.Scopes,
+		Roles:   key.Roles,
+	}, nil
+}
+
+func stringToSign(r *http.Request, ts string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{r.Method, r.URL.RequestURI(), ts, hex.EncodeToString(sum[:])}, "\n")
+}
+
+func hmacSignature(secret, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// readBody reads the request body and replaces it with a new reader so it can be read again
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	_ = r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// parseParams parses comma separated `key=value` pairs
+func parseParams(s string) map[string]string {
+	ret := map[string]string{}
+	for _, p := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) == 2 {
+			ret[kv[0]] = kv[1]
+		}
+	}
+	return ret
+}
@@ -0,0 +1,220 @@
+package authx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/logx"
+)
+
+const (
+	// jwksMinRefresh is the minimum time between reloads triggered by tokens signed with an unknown key id
+	jwksMinRefresh = 10 * time.Second
+	jwksTimeout    = 10 * time.Second
+)
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type jwkKey struct {
+	alg string
+	key interface{}
+}
+
+// keySet is a JSON Web Key Set loaded from a file or URL, cached for the configured refresh interval
+type keySet struct {
+	file     string
+	url      string
+	refresh  time.Duration
+	client   *http.Client
+	keys     map[string]jwkKey
+	loadedAt time.Time
+	now      func() time.Time
+	mux      sync.RWMutex
+}
+
+func newKeySet(file, url string, refresh time.Duration) *keySet {
+	return &keySet{
+		file:    file,
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: jwksTimeout},
+		now:     time.Now,
+	}
+}
+
+// get returns the key with the provided id. If the id is empty, returns the only key in the set compatible
+// with the algorithm.
+func (k *keySet) get(kid, alg string) (interface{}, error) {
+	k.mux.RLock()
+	key, found, stale, recent := k.lookup(kid, alg)
+	k.mux.RUnlock()
+
+	if stale || (!found && !recent) {
+		if err := k.reload(); err != nil {
+			logx.Warn("failed to load the JWKS, ", err.Error())
+		}
+		k.mux.RLock()
+		key, found, _, _ = k.lookup(kid, alg)
+		k.mux.RUnlock()
+	}
+	if !found {
+		return nil, fmt.Errorf("no key found for kid '%s'", kid)
+	}
+	return key, nil
+}
+
+func (k *keySet) lookup(kid, alg string) (key interface{}, found, stale, recent bool) {
+	elapsed := k.now().Sub(k.loadedAt)
+	stale = k.keys == nil || elapsed > k.refresh
+	recent = elapsed < jwksMinRefresh
+
+	if kid != "" {
+		if v, ok := k.keys[kid]; ok && (v.alg == "" || v.alg == alg) {
+			return v.key, true, stale, recent
+		}
+		return nil, false, stale, recent
+	}
+
+	for _, v := range k.keys {
+		if (v.alg == "" || v.alg == alg) && keyMatchesAlg(v.key, alg) {
+			if found {
+				// Ambiguous, tokens must include the key id
+				return nil, false, stale, recent
+			}
+			key, found = v.key, true
+		}
+	}
+	return key, found, stale, recent
+}
+
+func (k *keySet) reload() error {
+	k.mux.Lock()
+	defer k.mux.Unlock()
+
+	// Another goroutine may have reloaded it while waiting for the lock, or the last attempt failed recently
+	if !k.loadedAt.IsZero() && k.now().Sub(k.loadedAt) < jwksMinRefresh {
+		return nil
+	}
+	k.loadedAt = k.now()
+
+	data, err := k.fetch()
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+	k.keys = keys
+	return nil
+}
+
+func (k *keySet) fetch() ([]byte, error) {
+	if k.url == "" {
+		return ioutil.ReadFile(k.file)
+	}
+	resp, err := k.client.Get(k.url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching the JWKS", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func parseJWKS(data []byte) (map[string]jwkKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS, %s", err.Error())
+	}
+
+	ret := map[string]jwkKey{}
+	for i, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		key, err := j.parse()
+		if err != nil {
+			logx.Warn("skipping invalid JWK '", j.Kid, "', ", err.Error())
+			continue
+		}
+		kid := j.Kid
+		if kid == "" {
+			kid = fmt.Sprintf("#%d", i)
+		}
+		ret[kid] = jwkKey{alg: j.Alg, key: key}
+	}
+	return ret, nil
+}
+
+func (j jwk) parse() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(j.K)
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", j.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,307 @@
+package authx
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jucardi/go-streams/streams"
+	"github.com/jucardi/go-titan/net/errorx"
+
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const schemeBearer = "Bearer "
+
+var hashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtAuthenticator struct {
+	cfg       JWTConfig
+	secret    []byte
+	publicKey interface{}
+	keys      *keySet
+	leeway    time.Duration
+	now       func() time.Time
+}
+
+func newJWTAuthenticator(cfg JWTConfig) (*jwtAuthenticator, error) {
+	ret := &jwtAuthenticator{
+		cfg:    cfg,
+		leeway: time.Duration(cfg.Leeway) * time.Millisecond,
+		now:    time.Now,
+	}
+	if cfg.Secret != "" {
+		ret.secret = []byte(cfg.Secret)
+	}
+	if cfg.PublicKeyFile != "" {
+		key, err := loadPublicKey(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		ret.publicKey = key
+	}
+	if cfg.JWKSUrl != "" || cfg.JWKSFile != "" {
+		ret.keys = newKeySet(cfg.JWKSFile, cfg.JWKSUrl, time.Duration(cfg.JWKSRefresh)*time.Millisecond)
+	}
+	if ret.secret == nil && ret.publicKey == nil && ret.keys == nil {
+		return nil, errors.New("jwt authentication enabled without a secret, public key or JWKS")
+	}
+	return ret, nil
+}
+
+func (a *jwtAuthenticator) Method() string {
+	return MethodJWT
+}
+
+func (a *jwtAuthenticator) Applies(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get(HeaderAuthorization), schemeBearer)
+}
+
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get(HeaderAuthorization), schemeBearer))
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, errorx.WrapUnauthorized(err, "invalid token")
+	}
+	sub, _ := claims["sub"].(string)
+	return &Principal{
+		Subject: sub,
+		Method:  MethodJWT,
+		Scopes:  claimStrings(claims[a.cfg.ScopesClaim]),
+		Roles:   claimStrings(claims[a.cfg.RolesClaim]),
+		Claims:  claims,
+	}, nil
+}
+
+// verify validates the token signature and registered claims, and returns the token claims
+func (a *jwtAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header, %s", err.Error())
+	}
+	if len(a.cfg.Algorithms) > 0 && !streams.From(a.cfg.Algorithms).Contains(header.Alg) {
+		return nil, fmt.Errorf("algorithm '%s' not allowed", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature, %s", err.Error())
+	}
+	key, err := a.key(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims, %s", err.Error())
+	}
+	return claims, a.validateClaims(claims)
+}
+
+func (a *jwtAuthenticator) key(header jwtHeader) (interface{}, error) {
+	if strings.HasPrefix(header.Alg, "HS") {
+		if a.secret != nil {
+			return a.secret, nil
+		}
+		if a.keys == nil {
+			return nil, fmt.Errorf("algorithm '%s' not supported", header.Alg)
+		}
+	}
+	if a.keys != nil {
+		key, err := a.keys.get(header.Kid, header.Alg)
+		if err == nil || a.publicKey == nil {
+			return key, err
+		}
+	}
+	if a.publicKey == nil {
+		return nil, fmt.Errorf("algorithm '%s' not supported", header.Alg)
+	}
+	return a.publicKey, nil
+}
+
+func (a *jwtAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := a.now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("missing expiration")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(a.leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not valid yet")
+	}
+	if a.cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.cfg.Issuer {
+			return errors.New("invalid issuer")
+		}
+	}
+	if len(a.cfg.Audience) > 0 {
+		valid := false
+		for _, aud := range claimStrings(claims["aud"]) {
+			if streams.From(a.cfg.Audience).Contains(aud) {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return errors.New("invalid audience")
+		}
+	}
+	return nil
+}
+
+func verifySignature(alg string, key interface{}, data, sig []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("algorithm '%s' not supported", alg)
+	}
+	hash, ok := hashes[alg[2:]]
+	if !ok {
+		return fmt.Errorf("algorithm '%s' not supported", alg)
+	}
+	if !keyMatchesAlg(key, alg) {
+		return fmt.Errorf("key not valid for algorithm '%s'", alg)
+	}
+
+	if strings.HasPrefix(alg, "HS") {
+		mac := hmac.New(hash.New, key.([]byte))
+		mac.Write(data)
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+
+	h := hash.New()
+	h.Write(data)
+	digest := h.Sum(nil)
+
+	var err error
+	switch alg[:2] {
+	case "RS":
+		err = rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), hash, digest, sig)
+	case "PS":
+		err = rsa.VerifyPSS(key.(*rsa.PublicKey), hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		pub := key.(*ecdsa.PublicKey)
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(sig[:size]), new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			err = errors.New("invalid signature")
+		}
+	}
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// keyMatchesAlg indicates whether the key type can be used with the algorithm, preventing algorithm
+// confusion such as verifying HS256 tokens with a public key used as secret
+func keyMatchesAlg(key interface{}, alg string) bool {
+	if len(alg) < 2 {
+		return false
+	}
+	switch k := key.(type) {
+	case []byte:
+		return alg[:2] == "HS"
+	case *rsa.PublicKey:
+		return alg[:2] == "RS" || alg[:2] == "PS"
+	case *ecdsa.PublicKey:
+		return alg[:2] == "ES" && hashes[alg[2:]] != 0 && expectedCurveBits(alg) == k.Curve.Params().BitSize
+	}
+	return false
+}
+
+func expectedCurveBits(alg string) int {
+	switch alg {
+	case "ES256":
+		return 256
+	case "ES384":
+		return 384
+	case "ES512":
+		return 521
+	}
+	return 0
+}
+
+func loadPublicKey(file string) (interface{}, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in '%s'", file)
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
+
+func decodeSegment(seg string, target interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// claimStrings converts a claim that is either a space separated string or an array into a string slice
+func claimStrings(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var ret []string
+		for _, x := range v {
+			if s, ok := x.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	return nil
+}
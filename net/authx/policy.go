@@ -0,0 +1,204 @@
+package authx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy is a compiled policy expression
+type Policy struct {
+	root policyNode
+}
+
+type policyNode interface {
+	eval(a *RBACAuthorizer, p *Principal) bool
+}
+
+// CompilePolicy compiles a policy expression. Expressions combine the following terms with `and`, `or`,
+// `not` and parentheses:
+//
+//	authenticated       any authenticated principal
+//	role:<role>         the principal has the role
+//	scope:<scope>       the principal was granted the scope, directly or through its roles
+//	sub:<subject>       the principal subject matches
+//	method:<method>     the principal was authenticated with the method, e.g. `method:api_key`
+//	claim:<name>=<v>    the token claim matches, or contains the value if the claim is an array
+//
+// For example `role:admin or (scope:orders:read and claim:tenant=acme)`
+func CompilePolicy(expr string) (*Policy, error) {
+	p := &policyParser{tokens: tokenize(expr)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token '%s' in policy '%s'", p.tokens[p.pos], expr)
+	}
+	return &Policy{root: root}, nil
+}
+
+// Eval evaluates the policy for the provided principal using the default authorizer role mappings
+func (p *Policy) Eval(principal *Principal) bool {
+	a, _ := Authorizer().(*RBACAuthorizer)
+	if a == nil {
+		a = &RBACAuthorizer{}
+	}
+	return p.eval(a, principal)
+}
+
+func (p *Policy) eval(a *RBACAuthorizer, principal *Principal) bool {
+	return principal != nil && p.root.eval(a, principal)
+}
+
+type policyParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *policyParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *policyParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *policyParser) parseOr() (policyNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t == "or" || t == "||"; t = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parseAnd() (policyNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t == "and" || t == "&&"; t = p.peek() {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *policyParser) parseUnary() (policyNode, error) {
+	switch t := p.next(); t {
+	case "":
+		return nil, fmt.Errorf("unexpected end of policy")
+	case "not", "!":
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	case "(":
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis in policy")
+		}
+		return n, nil
+	default:
+		return parseTerm(t)
+	}
+}
+
+func parseTerm(t string) (policyNode, error) {
+	if t == "authenticated" {
+		return termNode(func(*RBACAuthorizer, *Principal) bool { return true }), nil
+	}
+
+	kv := strings.SplitN(t, ":", 2)
+	if len(kv) != 2 || kv[1] == "" {
+		return nil, fmt.Errorf("invalid policy term '%s'", t)
+	}
+	value := kv[1]
+
+	switch kv[0] {
+	case "role":
+		return termNode(func(_ *RBACAuthorizer, p *Principal) bool { return p.HasRole(value) }), nil
+	case "scope":
+		return termNode(func(a *RBACAuthorizer, p *Principal) bool { return a.hasScope(p, value) }), nil
+	case "sub":
+		return termNode(func(_ *RBACAuthorizer, p *Principal) bool { return p.Subject == value }), nil
+	case "method":
+		return termNode(func(_ *RBACAuthorizer, p *Principal) bool { return p.Method == value }), nil
+	case "claim":
+		claim := strings.SplitN(value, "=", 2)
+		if len(claim) != 2 {
+			return nil, fmt.Errorf("invalid claim term '%s', expected claim:<name>=<value>", t)
+		}
+		return termNode(func(_ *RBACAuthorizer, p *Principal) bool {
+			for _, v := range claimValues(p.Claims[claim[0]]) {
+				if v == claim[1] {
+					return true
+				}
+			}
+			return false
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown policy term '%s'", t)
+}
+
+func claimValues(claim interface{}) []string {
+	switch v := claim.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		var ret []string
+		for _, x := range v {
+			ret = append(ret, fmt.Sprint(x))
+		}
+		return ret
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func tokenize(expr string) []string {
+	expr = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expr)
+	return strings.Fields(expr)
+}
+
+type termNode func(a *RBACAuthorizer, p *Principal) bool
+
+func (n termNode) eval(a *RBACAuthorizer, p *Principal) bool { return n(a, p) }
+
+type andNode [2]policyNode
+
+func (n andNode) eval(a *RBACAuthorizer, p *Principal) bool {
+	return n[0].eval(a, p) && n[1].eval(a, p)
+}
+
+type orNode [2]policyNode
+
+func (n orNode) eval(a *RBACAuthorizer, p *Principal) bool {
+	return n[0].eval(a, p) || n[1].eval(a, p)
+}
+
+type notNode [1]policyNode
+
+func (n notNode) eval(a *RBACAuthorizer, p *Principal) bool {
+	return !n[0].eval(a, p)
+}
@@ -0,0 +1,59 @@
+package authx
+
+import (
+	"net/http"
+
+	"github.com/jucardi/go-streams/streams"
+)
+
+// HeaderAuthorization is the standard authorization header
+const HeaderAuthorization = "Authorization"
+
+// Authentication methods reported in `Principal.Method`
+const (
+	MethodJWT        = "jwt"
+	MethodAPIKey     = "api_key"
+	MethodHMAC       = "hmac"
+	MethodClientCert = "mtls"
+)
+
+// Principal is the identity authenticated for a request
+type Principal struct {
+	// Subject identifies the authenticated user or client
+	Subject string `json:"subject"`
+
+	// Method is the authentication method used to authenticate the principal
+	Method string `json:"method"`
+
+	// Scopes are the scopes granted to the principal
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Roles are the roles assigned to the principal
+	Roles []string `json:"roles,omitempty"`
+
+	// Claims contains the claims of the token when authenticated by JWT, or the certificate fields when
+	// authenticated by client certificate
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+// HasScope indicates whether the principal was granted the provided scope
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && streams.From(p.Scopes).Contains(scope)
+}
+
+// HasRole indicates whether the principal was assigned the provided role
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && streams.From(p.Roles).Contains(role)
+}
+
+// IAuthenticator authenticates requests with a specific method
+type IAuthenticator interface {
+	// Method returns the name of the authentication method
+	Method() string
+
+	// Applies indicates whether the request carries credentials for this method
+	Applies(r *http.Request) bool
+
+	// Authenticate validates the credentials in the request and returns the authenticated principal
+	Authenticate(r *http.Request) (*Principal, error)
+}
@@ -2,6 +2,7 @@ package encoding
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/jucardi/go-titan/net/errorx"
 	"github.com/jucardi/go-titan/net/rest"
 	"github.com/jucardi/go-titan/utils/reflectx"
+	"github.com/ugorji/go/codec"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"gopkg.in/yaml.v3"
@@ -73,7 +75,13 @@ func (r *responseDeserializer) Unmarshal(obj interface{}, contentType ...string)
 		ct = r.Headers().Get(rest.HeaderContentType)
 	}
 
-	if msg, ok := target.(proto.Message); ok {
+	if e, ok := target.(*errorx.Error); ok && strings.Contains(ct, rest.ContentTypeProblemJson) {
+		// RFC 7807 problem details are mapped onto the error
+		p := &errorx.Problem{}
+		if err = json.Unmarshal(respBytes, p); err == nil {
+			proto.Merge(e, p.ToError())
+		}
+	} else if msg, ok := target.(proto.Message); ok {
 		// If the target is a protobuf message.
 
 		if strings.Contains(ct, rest.ContentTypeProto) {
@@ -82,6 +90,9 @@ func (r *responseDeserializer) Unmarshal(obj interface{}, contentType ...string)
 		} else if strings.Contains(ct, rest.ContentTypeJson) {
 			// If the target object is a protobuf message but the Content-Type is JSON, uses the jsonpb package to unmarshal as JSON
 			err = protojson.Unmarshal(respBytes, msg)
+		} else if decode, ok := decoderFor(ct); ok {
+			// Other known encodings are decoded into the generated struct fields
+			err = decode(respBytes, target)
 		} else if err = proto.Unmarshal(respBytes, msg); err != nil {
 			// If the Content-Type was unrecognized and the target is a protobuf message, attempts proto.Unmarshal and if fails, attempts jsonpb.Unmarshal
 			logx.Warnf("unrecognized Content-Type (%s), target is Protobuf but proto.Unmarshal failed. Attempting JSON with jsonpb.Unmarshal", ct)
@@ -93,13 +104,18 @@ func (r *responseDeserializer) Unmarshal(obj interface{}, contentType ...string)
 		if strings.Contains(ct, rest.ContentTypeProto) {
 			// Logs an error if the Content-Type was set as proto but the target is not a protobuf message.
 			logx.Errorf("detected Content-Type %s but the target element is not a protobuf message. Attempting to deserialize as json", rest.ContentTypeProto)
-		} else if !strings.Contains(ct, rest.ContentTypeJson) {
-			// Logs a warning if the Content-Type was unrecognized
-			logx.Warnf("unrecognized Content-Type (%s), attempting to unmarshal as JSON", ct)
 		}
 
-		// Attempts to unmarshal as JSON
-		err = json.Unmarshal(respBytes, target)
+		if decode, ok := decoderFor(ct); ok {
+			err = decode(respBytes, target)
+		} else {
+			if !strings.Contains(ct, rest.ContentTypeJson) && !strings.Contains(ct, rest.ContentTypeProto) {
+				// Logs a warning if the Content-Type was unrecognized
+				logx.Warnf("unrecognized Content-Type (%s), attempting to unmarshal as JSON", ct)
+			}
+			// Attempts to unmarshal as JSON
+			err = json.Unmarshal(respBytes, target)
+		}
 	}
 
 	if err != nil {
@@ -120,7 +136,8 @@ func (r *responseDeserializer) Unmarshal(obj interface{}, contentType ...string)
 // UnmarshalError returns an error instance representing an error response obtained by the server.
 // Returns nil if the status code was not an error status
 //
-// Attempts to deserialize the response body to an instance of `*errorx.Error`
+// Attempts to deserialize the response body to an instance of `*errorx.Error`, either from the `errorx`
+// schema or from RFC 7807 `application/problem+json` problem details
 //
 // It also returns `*errorx.Error` if unmarshalling the response body fails
 //
@@ -140,9 +157,12 @@ func (r *responseDeserializer) UnmarshalError() error {
 		return errorx.New(r.GetCode(), http.StatusText(r.GetCode()), "Obtained error code with empty response body from the server")
 	}
 
-	if strings.Contains(r.Headers().Get(rest.HeaderContentType), rest.ContentTypeProto) {
+	ct := r.Headers().Get(rest.HeaderContentType)
+	if strings.Contains(ct, rest.ContentTypeProto) {
 		err = proto.Unmarshal(respBytes, target)
-	} else {
+	} else if decode, ok := decoderFor(ct); ok {
+		err = decode(respBytes, target)
+	} else if !strings.Contains(ct, rest.ContentTypeProblemJson) {
 		err = protojson.Unmarshal(respBytes, target)
 	}
 
@@ -150,6 +170,11 @@ func (r *responseDeserializer) UnmarshalError() error {
 		return target
 	}
 
+	// Attempts to parse the body as RFC 7807 problem details, regardless of the Content-Type
+	if p := (&errorx.Problem{}); json.Unmarshal(respBytes, p) == nil && p.Status > 0 {
+		return p.ToError()
+	}
+
 	ret := errorx.New(r.GetCode(), http.StatusText(r.GetCode()), string(respBytes))
 	return ret
 }
@@ -172,6 +197,33 @@ func (r *responseDeserializer) UnmarshalYaml(obj interface{}) error {
 	return yaml.Unmarshal(respBytes, obj)
 }
 
+// UnmarshalXml attempts to deserialize a XML response into the given obj.
+func (r *responseDeserializer) UnmarshalXml(obj interface{}) error {
+	respBytes, err := r.BodyBytes()
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(respBytes, obj)
+}
+
+// UnmarshalMsgpack attempts to deserialize a MessagePack response into the given obj.
+func (r *responseDeserializer) UnmarshalMsgpack(obj interface{}) error {
+	respBytes, err := r.BodyBytes()
+	if err != nil {
+		return err
+	}
+	return codec.NewDecoderBytes(respBytes, rest.MsgpackHandle).Decode(obj)
+}
+
+// UnmarshalCbor attempts to deserialize a CBOR response into the given obj.
+func (r *responseDeserializer) UnmarshalCbor(obj interface{}) error {
+	respBytes, err := r.BodyBytes()
+	if err != nil {
+		return err
+	}
+	return codec.NewDecoderBytes(respBytes, rest.CborHandle).Decode(obj)
+}
+
 // UnmarshalProto attempts to deserialize a Protobuf Message response into the given obj.
 func (r *responseDeserializer) UnmarshalProto(obj proto.Message) error {
 	respBytes, err := r.BodyBytes()
@@ -181,6 +233,25 @@ func (r *responseDeserializer) UnmarshalProto(obj proto.Message) error {
 	return proto.Unmarshal(respBytes, obj)
 }
 
+// decoderFor returns the decoding function for the YAML, XML, MessagePack and CBOR content types
+func decoderFor(contentType string) (func(data []byte, obj interface{}) error, bool) {
+	switch {
+	case strings.Contains(contentType, "yaml"):
+		return yaml.Unmarshal, true
+	case strings.Contains(contentType, "xml"):
+		return xml.Unmarshal, true
+	case strings.Contains(contentType, "msgpack"):
+		return func(data []byte, obj interface{}) error {
+			return codec.NewDecoderBytes(data, rest.MsgpackHandle).Decode(obj)
+		}, true
+	case strings.Contains(contentType, rest.ContentTypeCbor):
+		return func(data []byte, obj interface{}) error {
+			return codec.NewDecoderBytes(data, rest.CborHandle).Decode(obj)
+		}, true
+	}
+	return nil, false
+}
+
 func (r *responseDeserializer) appendRequestDetails(err *errorx.Error) *errorx.Error {
 	req := r.Request()
 	url := ""
@@ -4,6 +4,8 @@ const (
 	Json         = Encoding("json")
 	IndentedJson = Encoding("indented-json")
 	Gzip         = Encoding("gzip")
+	Deflate      = Encoding("deflate")
+	Zstd         = Encoding("zstd")
 	Protobuf     = Encoding("proto")
 	Auto         = Encoding("auto")
 )
@@ -20,7 +20,8 @@ type IResponseDecoder interface {
 	// UnmarshalError returns an error instance representing an error response obtained by the server.
 	// Returns nil if the status code was not an error status
 	//
-	// Attempts to deserialize the response body to an instance of `*errorx.Error`
+	// Attempts to deserialize the response body to an instance of `*errorx.Error`, either from the `errorx`
+	// schema or from RFC 7807 `application/problem+json` problem details
 	//
 	// It also returns `*errorx.Error` if unmarshalling the response body fails
 	//
@@ -32,6 +33,15 @@ type IResponseDecoder interface {
 	// UnmarshalYaml attempts to deserialize a YAML response into the given obj.
 	UnmarshalYaml(obj interface{}) error
 
+	// UnmarshalXml attempts to deserialize a XML response into the given obj.
+	UnmarshalXml(obj interface{}) error
+
+	// UnmarshalMsgpack attempts to deserialize a MessagePack response into the given obj.
+	UnmarshalMsgpack(obj interface{}) error
+
+	// UnmarshalCbor attempts to deserialize a CBOR response into the given obj.
+	UnmarshalCbor(obj interface{}) error
+
 	// UnmarshalProto attempts to deserialize a Protobuf Message response into the given obj.
 	UnmarshalProto(obj proto.Message) error
 }
@@ -0,0 +1,133 @@
+package errorx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	// ProblemTypeDefault is the problem type used when the problem has no additional semantics beyond the
+	// HTTP status code
+	ProblemTypeDefault = "about:blank"
+
+	problemTimestamp = "timestamp"
+	problemInner     = "inner"
+	problemFields    = "fields"
+	problemTrace     = "trace"
+)
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+// Problem is the RFC 7807 `application/problem+json` representation of an error. Members that are not
+// defined by the RFC are kept in `Extensions` and serialized at the top level of the JSON object.
+type Problem struct {
+	Type       string                 `json:"type,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Status     int                    `json:"status,omitempty"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// ToProblem maps the error onto a RFC 7807 problem. The code, title and message are mapped to the `status`,
+// `title` and `detail` members, while the timestamp, inner errors, fields and trace are added as extensions.
+func (m *Error) ToProblem(instance ...string) *Problem {
+	ret := &Problem{
+		Type:       ProblemTypeDefault,
+		Title:      m.Title,
+		Status:     int(m.Code),
+		Detail:     m.Message,
+		Extensions: map[string]interface{}{},
+	}
+	if ret.Title == "" {
+		ret.Title = http.StatusText(ret.Status)
+	}
+	if len(instance) > 0 {
+		ret.Instance = instance[0]
+	}
+	if m.Timestamp != "" {
+		ret.Extensions[problemTimestamp] = m.Timestamp
+	}
+	if len(m.Inner) > 0 {
+		ret.Extensions[problemInner] = m.Inner
+	}
+	if len(m.Fields) > 0 {
+		ret.Extensions[problemFields] = m.Fields
+	}
+	if len(m.Trace) > 0 {
+		ret.Extensions[problemTrace] = m.Trace
+	}
+	return ret
+}
+
+// ToError maps the problem back onto an error, restoring the extensions set by `ToProblem`
+func (p *Problem) ToError() *Error {
+	ret := &Error{
+		Code:    int32(p.Status),
+		Title:   p.Title,
+		Message: p.Detail,
+	}
+	if ret.Message == "" {
+		ret.Message = ret.Title
+	}
+	if v, ok := p.Extensions[problemTimestamp].(string); ok {
+		ret.Timestamp = v
+	}
+	if v, ok := p.Extensions[problemFields].(map[string]interface{}); ok {
+		ret.Fields = map[string]string{}
+		for k, f := range v {
+			ret.Fields[k] = fmt.Sprint(f)
+		}
+	}
+	if v, ok := p.Extensions[problemTrace].([]interface{}); ok {
+		for _, t := range v {
+			ret.Trace = append(ret.Trace, fmt.Sprint(t))
+		}
+	}
+	if v, ok := p.Extensions[problemInner].([]interface{}); ok {
+		for _, i := range v {
+			inner, _ := i.(map[string]interface{})
+			e := &InnerError{}
+			e.Error, _ = inner["error"].(string)
+			e.Details, _ = inner["details"].(string)
+			e.Caller, _ = inner["caller"].(string)
+			ret.Inner = append(ret.Inner, e)
+		}
+	}
+	return ret
+}
+
+// MarshalJSON serializes the problem members along with the extensions
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	ret := map[string]interface{}{}
+	for k, v := range p.Extensions {
+		ret[k] = v
+	}
+	type problem Problem
+	data, err := json.Marshal((*problem)(p))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return nil, err
+	}
+	return json.Marshal(ret)
+}
+
+// UnmarshalJSON deserializes the problem members, keeping any other member in the extensions
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problem Problem
+	if err := json.Unmarshal(data, (*problem)(p)); err != nil {
+		return err
+	}
+	ext := map[string]interface{}{}
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return err
+	}
+	for _, k := range problemMembers {
+		delete(ext, k)
+	}
+	p.Extensions = ext
+	return nil
+}
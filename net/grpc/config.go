@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/tlsx"
+)
+
+const (
+	configKey  = "grpc"
+	configName = "grpc-cfg"
+)
+
+// Config is the configuration of the gRPC server. Durations are in milliseconds.
+type Config struct {
+	// Port is the port where the server listens for connections. Default is 9090
+	Port int `json:"port" yaml:"port" default:"9090"`
+
+	// TLS is the TLS configuration of the server, see `tlsx.Config`
+	TLS tlsx.Config `json:"tls" yaml:"tls"`
+
+	// MaxRecvMsgSize is the maximum size in bytes of a message received by the server. Default is 4MiB
+	MaxRecvMsgSize int `json:"max_recv_msg_size" yaml:"max_recv_msg_size" default:"4194304"`
+
+	// MaxSendMsgSize is the maximum size in bytes of a message sent by the server. Default is 4MiB
+	MaxSendMsgSize int `json:"max_send_msg_size" yaml:"max_send_msg_size" default:"4194304"`
+
+	// Keepalive contains the keepalive settings of the connections
+	Keepalive KeepaliveConfig `json:"keepalive" yaml:"keepalive"`
+
+	// ErrorStackTrace indicates whether the errors sent to the clients include the stack trace and the
+	// callers of the inner errors
+	ErrorStackTrace bool `json:"error_stack_trace" yaml:"error_stack_trace"`
+
+	// ShutdownTimeout is the maximum duration to wait for the in-flight calls to complete when the server
+	// is shut down, after which the remaining calls are cancelled. Default is 30 seconds
+	ShutdownTimeout int64 `json:"shutdown_timeout" yaml:"shutdown_timeout" default:"30000"`
+}
+
+// KeepaliveConfig contains the keepalive settings of the server connections. Durations are in milliseconds,
+// zero uses the gRPC defaults.
+type KeepaliveConfig struct {
+	// Time is the idle duration after which the server pings the client to check the connection is alive.
+	// Default is 2 hours
+	Time int64 `json:"time" yaml:"time"`
+
+	// Timeout is the duration to wait for the ping ack before closing the connection. Default is 20 seconds
+	Timeout int64 `json:"timeout" yaml:"timeout"`
+
+	// MaxConnectionIdle is the idle duration after which the connection is closed with a GoAway. Default is
+	// infinity
+	MaxConnectionIdle int64 `json:"max_connection_idle" yaml:"max_connection_idle"`
+
+	// MaxConnectionAge is the maximum duration a connection may exist before it is closed with a GoAway,
+	// useful to rebalance the clients across instances. Default is infinity
+	MaxConnectionAge int64 `json:"max_connection_age" yaml:"max_connection_age"`
+
+	// MaxConnectionAgeGrace is the duration the calls of a connection closed by age may take to complete
+	// before the connection is forcibly closed. Default is infinity
+	MaxConnectionAgeGrace int64 `json:"max_connection_age_grace" yaml:"max_connection_age_grace"`
+
+	// MinTime is the minimum interval between the pings of a client. Clients pinging more often are
+	// disconnected. Default is 5 minutes
+	MinTime int64 `json:"min_time" yaml:"min_time"`
+
+	// PermitWithoutStream allows the clients to ping when there are no active calls
+	PermitWithoutStream bool `json:"permit_without_stream" yaml:"permit_without_stream"`
+}
+
+var (
+	cfg    = defaultConfig()
+	cfgMux sync.RWMutex
+)
+
+func init() {
+	configx.AddOnReloadCallback(func(c configx.IConfig) {
+		config := defaultConfig()
+
+		logx.WithObj(
+			c.MapToObj(configKey, config),
+		).Fatal("unable to map grpc configuration")
+
+		cfgMux.Lock()
+		cfg = config
+		cfgMux.Unlock()
+	}, configName)
+}
+
+// GetConfig returns the gRPC configuration
+func GetConfig() *Config {
+	cfgMux.RLock()
+	defer cfgMux.RUnlock()
+	return cfg
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Port: 9090,
+		TLS: tlsx.Config{
+			ReloadInterval: 10000,
+		},
+		MaxRecvMsgSize:  4194304,
+		MaxSendMsgSize:  4194304,
+		ShutdownTimeout: 30000,
+	}
+}
+
+func millis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
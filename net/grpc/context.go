@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/jucardi/go-titan/net/authx"
+	"google.golang.org/grpc"
+)
+
+type contextKey int
+
+const (
+	cidKey contextKey = iota
+	traceKey
+	principalKey
+)
+
+// Cid returns the correlation ID of the call, set by the correlation ID interceptor
+func Cid(ctx context.Context) string {
+	ret, _ := ctx.Value(cidKey).(string)
+	return ret
+}
+
+// Trace returns the correlation trace of the call, set by the correlation ID interceptor
+func Trace(ctx context.Context) string {
+	ret, _ := ctx.Value(traceKey).(string)
+	return ret
+}
+
+// Principal returns the principal authenticated for the call, or nil if the call is anonymous
+func Principal(ctx context.Context) *authx.Principal {
+	ret, _ := ctx.Value(principalKey).(*authx.Principal)
+	return ret
+}
+
+// WithPrincipal returns a copy of the context with the provided principal assigned
+func WithPrincipal(ctx context.Context, p *authx.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// serverStream overrides the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func withContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &serverStream{ServerStream: ss, ctx: ctx}
+}
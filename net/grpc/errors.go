@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/jucardi/go-titan/net/errorx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusClientClosedRequest is the non-standard HTTP status used for requests cancelled by the client
+const statusClientClosedRequest = 499
+
+var (
+	httpToCode = map[int]codes.Code{
+		http.StatusBadRequest:            codes.InvalidArgument,
+		http.StatusUnauthorized:          codes.Unauthenticated,
+		http.StatusForbidden:             codes.PermissionDenied,
+		http.StatusNotFound:              codes.NotFound,
+		http.StatusMethodNotAllowed:      codes.Unimplemented,
+		http.StatusNotAcceptable:         codes.InvalidArgument,
+		http.StatusRequestTimeout:        codes.DeadlineExceeded,
+		http.StatusConflict:              codes.AlreadyExists,
+		http.StatusPreconditionFailed:    codes.FailedPrecondition,
+		http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+		http.StatusUnprocessableEntity:   codes.InvalidArgument,
+		http.StatusTooManyRequests:       codes.ResourceExhausted,
+		statusClientClosedRequest:        codes.Canceled,
+		http.StatusInternalServerError:   codes.Internal,
+		http.StatusNotImplemented:        codes.Unimplemented,
+		http.StatusBadGateway:            codes.Unavailable,
+		http.StatusServiceUnavailable:    codes.Unavailable,
+		http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+	}
+
+	codeToHttp = map[codes.Code]int{
+		codes.OK:                 http.StatusOK,
+		codes.Canceled:           statusClientClosedRequest,
+		codes.Unknown:            http.StatusInternalServerError,
+		codes.InvalidArgument:    http.StatusBadRequest,
+		codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+		codes.NotFound:           http.StatusNotFound,
+		codes.AlreadyExists:      http.StatusConflict,
+		codes.PermissionDenied:   http.StatusForbidden,
+		codes.ResourceExhausted:  http.StatusTooManyRequests,
+		codes.FailedPrecondition: http.StatusPreconditionFailed,
+		codes.Aborted:            http.StatusConflict,
+		codes.OutOfRange:         http.StatusBadRequest,
+		codes.Unimplemented:      http.StatusNotImplemented,
+		codes.Internal:           http.StatusInternalServerError,
+		codes.Unavailable:        http.StatusServiceUnavailable,
+		codes.DataLoss:           http.StatusInternalServerError,
+		codes.Unauthenticated:    http.StatusUnauthorized,
+	}
+)
+
+// CodeFromHTTP returns the gRPC code that corresponds to an HTTP status code
+func CodeFromHTTP(httpStatus int) codes.Code {
+	if code, ok := httpToCode[httpStatus]; ok {
+		return code
+	}
+	switch {
+	case httpStatus < 400:
+		return codes.OK
+	case httpStatus < 500:
+		return codes.FailedPrecondition
+	}
+	return codes.Unknown
+}
+
+// HTTPFromCode returns the HTTP status code that corresponds to a gRPC code
+func HTTPFromCode(code codes.Code) int {
+	if ret, ok := codeToHttp[code]; ok {
+		return ret
+	}
+	return http.StatusInternalServerError
+}
+
+// ToStatus converts an error into a gRPC status error. Errors which are already gRPC status errors are
+// returned as is, context errors are converted to `Canceled` and `DeadlineExceeded`, and any other error
+// is wrapped by `errorx.Wrap`.
+//
+// The code of the `errorx.Error` is mapped to a gRPC code by `CodeFromHTTP`, or `Unknown` if it is not an
+// error status, and the error is attached to the status details, so clients can restore it with `FromError`.
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	e, ok := err.(*errorx.Error)
+	switch {
+	case ok:
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		e = errorx.Wrap(err)
+	}
+
+	// An error is never reported as OK, since the status would be dropped and the call would succeed
+	code := CodeFromHTTP(int(e.Code))
+	if code == codes.OK {
+		code = codes.Unknown
+	}
+	st := status.New(code, e.Message)
+	if withDetails, err := st.WithDetails(sanitize(e)); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// FromError converts an error returned by a gRPC call into an `errorx.Error`. If the status carries the
+// `errorx.Error` sent by the server it is restored, otherwise the gRPC code is mapped to an HTTP status
+// code by `HTTPFromCode`. Returns nil if the error is nil.
+func FromError(err error) *errorx.Error {
+	if err == nil {
+		return nil
+	}
+	if e, ok := err.(*errorx.Error); ok {
+		return e
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return errorx.Wrap(err)
+	}
+	for _, d := range st.Details() {
+		if e, ok := d.(*errorx.Error); ok {
+			return e
+		}
+	}
+	code := HTTPFromCode(st.Code())
+	return errorx.New(code, http.StatusText(code), st.Message())
+}
+
+// sanitize removes the stack trace, the callers of the inner errors and the fields, unless `error_stack_trace`
+// is enabled. The fields are never sent since they may carry the details of the outbound requests of the
+// errors returned by downstream services, see `rest.Context.InvalidFields`.
+func sanitize(e *errorx.Error) *errorx.Error {
+	if GetConfig().ErrorStackTrace {
+		return e
+	}
+	ret := &errorx.Error{
+		Code:      e.Code,
+		Timestamp: e.Timestamp,
+		Title:     e.Title,
+		Message:   e.Message,
+	}
+	for _, x := range e.Inner {
+		ret.Inner = append(ret.Inner, &errorx.InnerError{
+			Error:   x.Error,
+			Details: x.Details,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,165 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/errorx"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testInfo = &grpc.UnaryServerInfo{FullMethod: "/orders.v1.Orders/Get"}
+
+func TestErrorMapping(t *testing.T) {
+	err := ToStatus(errorx.NewConflict("order already exists"))
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	e := FromError(err)
+	assert.Equal(t, int32(http.StatusConflict), e.Code)
+	assert.Equal(t, "order already exists", e.Message)
+	assert.Empty(t, e.Trace)
+
+	e = FromError(status.Error(codes.Unavailable, "down"))
+	assert.Equal(t, int32(http.StatusServiceUnavailable), e.Code)
+	assert.Equal(t, "down", e.Message)
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(ToStatus(context.DeadlineExceeded)))
+	assert.Nil(t, ToStatus(nil))
+
+	err = ToStatus(&errorx.Error{Message: "x"})
+	assert.NotNil(t, err)
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Equal(t, "x", status.Convert(err).Message())
+
+	downstream := errorx.NewBadGateway("upstream failed")
+	downstream.Fields = map[string]string{"url": "http://orders/v1", "headers": "Authorization:Bearer secret"}
+	assert.Nil(t, FromError(ToStatus(downstream)).Fields)
+}
+
+func TestRecoveryAndErrors(t *testing.T) {
+	handler := chain(UnaryRecovery(), UnaryErrors())
+
+	_, err := handler(context.Background(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = handler(context.Background(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, errorx.NewNotFound("order not found")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "order not found", status.Convert(err).Message())
+}
+
+func TestRecoveryMetrics(t *testing.T) {
+	counter := requestsCounter.Vec().WithLabelValues(testInfo.FullMethod, codes.Internal.String())
+	count := func() float64 {
+		m := &dto.Metric{}
+		assert.NoError(t, counter.Write(m))
+		return m.GetCounter().GetValue()
+	}
+	before := count()
+
+	_, err := chain(UnaryInterceptors()...)(context.Background(), func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, before+1, count())
+}
+
+func TestAuth(t *testing.T) {
+	cfg := authx.DefaultConfig()
+	cfg.APIKey.Enabled = true
+	cfg.Required = true
+	cfg.APIKey.Keys = []authx.APIKey{{Key: "reader-key", Subject: "reader"}}
+	a, err := authx.New(cfg)
+	assert.NoError(t, err)
+
+	handler := chain(UnaryAuth(a))
+	subject := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return Principal(ctx).Subject, nil
+	}
+
+	_, err = handler(context.Background(), subject)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "reader-key"))
+	resp, err := handler(ctx, subject)
+	assert.NoError(t, err)
+	assert.Equal(t, "reader", resp)
+}
+
+func TestServerHealth(t *testing.T) {
+	ln := bufconn.Listen(1 << 20)
+	srv := FromConfig(defaultConfig())
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return ln.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), MetadataCorrelationId, "cid-1")
+	resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, []string{"cid-1"}, header.Get(MetadataCorrelationId))
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func chain(interceptors ...grpc.UnaryServerInterceptor) func(ctx context.Context, handler grpc.UnaryHandler) (interface{}, error) {
+	return func(ctx context.Context, handler grpc.UnaryHandler) (interface{}, error) {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, testInfo, next)
+			}
+		}
+		return handler(ctx, nil)
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	ln := bufconn.Listen(1 << 20)
+	srv := FromConfig(defaultConfig())
+	go func() { _ = srv.Serve(ln) }()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return ln.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// A watch outlives the graceful shutdown, and is cancelled once the context is done
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, srv.Shutdown(ctx))
+	assert.False(t, srv.Ready())
+	_, err = stream.Recv()
+	assert.NotNil(t, err)
+
+	assert.NoError(t, srv.Shutdown(context.Background()))
+}
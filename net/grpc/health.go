@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jucardi/go-titan/components/health"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Services reported by the health service. The empty service reports the readiness of the server
+const (
+	HealthServiceReadiness = "readiness"
+	HealthServiceLiveness  = "liveness"
+)
+
+// healthWatchInterval is the interval at which the watched status is evaluated
+var healthWatchInterval = 5 * time.Second
+
+// healthServer implements the standard gRPC health service (`grpc.health.v1.Health`) with the checks of the
+// health component. Degraded reports are considered serving.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (h *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, ok := evaluateHealth(ctx, req.Service)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service '%s'", req.Service)
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+func (h *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		st, ok := evaluateHealth(stream.Context(), req.Service)
+		if !ok {
+			st = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func evaluateHealth(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	var report *health.Report
+	switch service {
+	case "", HealthServiceReadiness:
+		report = health.Readiness(ctx)
+	case HealthServiceLiveness:
+		report = health.Liveness(ctx)
+	default:
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+	if report.Status == health.StatusDown {
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+	return healthpb.HealthCheckResponse_SERVING, true
+}
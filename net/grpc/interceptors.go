@@ -0,0 +1,296 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/utils/recovery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MetadataCorrelationId is the metadata key of the correlation ID, the gRPC equivalent of the `X-CID`
+	// header
+	MetadataCorrelationId = "x-cid"
+
+	// MetadataCorrelationTrace is the metadata key of the correlation trace, the gRPC equivalent of the
+	// `X-CID-Trace` header
+	MetadataCorrelationTrace = "x-cid-trace"
+
+	metadataAuthority = ":authority"
+)
+
+var processName = func() string {
+	s := strings.Split(os.Args[0], "/")
+	return s[len(s)-1]
+}()
+
+type panicDump struct {
+	Error      interface{} `json:"error"                 yaml:"error"`
+	Method     string      `json:"method"                yaml:"method"`
+	StackTrace string      `json:"stack_trace,omitempty" yaml:"stack_trace,omitempty"`
+}
+
+// UnaryInterceptors returns the common interceptors of unary calls: correlation ID, metrics, logging,
+// recovery, errors and authentication with `authx.Default()`, in that order. As in the REST middleware,
+// the calls which panic are logged and recorded in the metrics.
+func UnaryInterceptors() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		UnaryCid(),
+		UnaryMetrics(),
+		UnaryLogging(),
+		UnaryRecovery(),
+		UnaryErrors(),
+		UnaryAuth(nil),
+	}
+}
+
+// StreamInterceptors returns the common interceptors of streaming calls, see `UnaryInterceptors`
+func StreamInterceptors() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		StreamCid(),
+		StreamMetrics(),
+		StreamLogging(),
+		StreamRecovery(),
+		StreamErrors(),
+		StreamAuth(nil),
+	}
+}
+
+// UnaryRecovery returns an interceptor that recovers from panics in the handlers, logging the panic with
+// its stack trace and failing the call with `Internal`
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer recoverCall(info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery returns the streaming equivalent of `UnaryRecovery`
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverCall(info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverCall(method string, err *error) {
+	rval := recover()
+	if rval == nil {
+		return
+	}
+
+	msg := fmt.Sprint(rval)
+	logx.WithObj(
+		panicDump{
+			Error:      rval,
+			Method:     method,
+			StackTrace: recovery.Stack(),
+		}).Errorf("PANIC RECOVERED | %s", msg)
+
+	recovery.TriggerHandlers(rval)
+	if e, ok := rval.(error); ok {
+		*err = ToStatus(errorx.Wrap(e))
+	} else {
+		*err = ToStatus(errorx.NewUnhandled(msg))
+	}
+}
+
+// UnaryCid returns an interceptor that reads the correlation ID and trace from the `x-cid` and
+// `x-cid-trace` metadata, generating a new ID if missing and appending this application to the trace.
+// They are available through `Cid` and `Trace`, and sent back in the response headers.
+func UnaryCid() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = correlate(ctx)
+		_ = grpc.SetHeader(ctx, cidHeader(ctx))
+		return handler(ctx, req)
+	}
+}
+
+// StreamCid returns the streaming equivalent of `UnaryCid`
+func StreamCid() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := correlate(ss.Context())
+		_ = ss.SetHeader(cidHeader(ctx))
+		return handler(srv, withContext(ss, ctx))
+	}
+}
+
+func correlate(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	cid, trace := first(md, MetadataCorrelationId), first(md, MetadataCorrelationTrace)
+	if cid == "" {
+		cid = uuid.New().String()
+	}
+	if trace == "" {
+		trace = processName
+	} else {
+		trace = fmt.Sprintf("%s | %s", trace, processName)
+	}
+	ctx = context.WithValue(ctx, cidKey, cid)
+	return context.WithValue(ctx, traceKey, trace)
+}
+
+func cidHeader(ctx context.Context) metadata.MD {
+	return metadata.Pairs(MetadataCorrelationId, Cid(ctx), MetadataCorrelationTrace, Trace(ctx))
+}
+
+// UnaryLogging returns an interceptor that logs the calls with their latency, as warnings if they failed
+// with a client error, as errors if they failed with a server error, and as debug otherwise. The codes are
+// classified by their HTTP equivalent, see `HTTPFromCode`.
+func UnaryLogging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, info.FullMethod, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamLogging returns the streaming equivalent of `UnaryLogging`
+func StreamLogging() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
+func logCall(ctx context.Context, method string, latency time.Duration, err error) {
+	st := status.Convert(err)
+	code := HTTPFromCode(st.Code())
+
+	fields := map[string]interface{}{
+		"Latency": latency.String(),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields["Source"] = p.Addr.String()
+	}
+	if cid := Cid(ctx); cid != "" {
+		fields["Cid"] = cid
+	}
+	lx := logx.WithFields(fields)
+	msg := fmt.Sprintf(`[%s] %s`, st.Code(), method)
+	if err != nil {
+		msg += " | " + st.Message()
+	}
+
+	switch {
+	case code >= 500:
+		lx.Error(msg)
+	case code >= 400:
+		lx.Warn(msg)
+	default:
+		lx.Debug(msg)
+	}
+}
+
+// UnaryErrors returns an interceptor that converts the errors returned by the handlers to gRPC status
+// errors, see `ToStatus`
+func UnaryErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, ToStatus(err)
+	}
+}
+
+// StreamErrors returns the streaming equivalent of `UnaryErrors`
+func StreamErrors() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return ToStatus(handler(srv, ss))
+	}
+}
+
+// UnaryAuth returns an interceptor that authenticates the calls using the provided authenticator, or the
+// one of the `auth` configuration if nil, and assigns the authenticated principal to the context, see
+// `Principal`. The credentials are read from the metadata as if they were headers, e.g. `authorization` or
+// `x-api-key`, and the client certificate from the TLS connection. HMAC signatures are verified against an
+// empty body.
+//
+// Calls with invalid credentials fail with `Unauthenticated`, as well as calls without credentials if
+// authentication is required. Has no effect if no authentication method is enabled.
+func UnaryAuth(a *authx.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, a, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth returns the streaming equivalent of `UnaryAuth`
+func StreamAuth(a *authx.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), a, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, withContext(ss, ctx))
+	}
+}
+
+func authenticate(ctx context.Context, a *authx.Authenticator, method string) (context.Context, error) {
+	if a == nil {
+		a = authx.Default()
+	}
+	if !a.Enabled() {
+		return ctx, nil
+	}
+	p, err := a.Authenticate(authRequest(ctx, method))
+	if err != nil {
+		return ctx, ToStatus(err)
+	}
+	if p != nil {
+		ctx = WithPrincipal(ctx, p)
+	}
+	return ctx, nil
+}
+
+// authRequest creates the HTTP request authenticated by the `authx` methods, with the metadata as headers
+func authRequest(ctx context.Context, method string) *http.Request {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r := &http.Request{
+		Method: http.MethodPost,
+		URL:    &url.URL{Path: method},
+		Host:   first(md, metadataAuthority),
+		Header: http.Header{},
+		Body:   http.NoBody,
+	}
+	for k, values := range md {
+		if strings.HasPrefix(k, ":") {
+			continue
+		}
+		for _, v := range values {
+			r.Header.Add(k, v)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			r.TLS = &info.State
+		}
+	}
+	return r.WithContext(ctx)
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jucardi/go-titan/components/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	requestsCounter = prometheus.Counter(prometheus.Opts{
+		Name:   "grpc_server_requests_total",
+		Help:   "Total number of handled gRPC calls",
+		Labels: []string{"method", "code"},
+	})
+
+	requestDuration = prometheus.Histogram(prometheus.Opts{
+		Name:   "grpc_server_request_duration_seconds",
+		Help:   "Duration of handled gRPC calls in seconds",
+		Labels: []string{"method"},
+	})
+)
+
+// UnaryMetrics returns an interceptor that records the count of calls by method and code, and their
+// duration by method, in Prometheus
+func UnaryMetrics() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamMetrics returns the streaming equivalent of `UnaryMetrics`
+func StreamMetrics() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func observe(method string, err error, elapsed time.Duration) {
+	requestsCounter.Inc(method, status.Code(err).String())
+	requestDuration.Observe(elapsed.Seconds(), method)
+}
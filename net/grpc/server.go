@@ -0,0 +1,215 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/jucardi/go-titan/components/health"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/tlsx"
+	"github.com/jucardi/go-titan/utils/shutdown"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+)
+
+const (
+	// ServerCheckName is the name of the readiness check that fails while the server is not serving
+	ServerCheckName = "grpc-server"
+
+	defaultShutdownTimeout = 30000
+)
+
+// Server is a gRPC server configured from the `grpc` configuration, which serves the standard health
+// service backed by the health component. Services are registered through the embedded `grpc.Server`.
+//
+//	srv := grpc.New()
+//	pb.RegisterOrdersServer(srv, &ordersServer{})
+//	if err := srv.Run(); err != nil {
+//		logx.Fatal(err)
+//	}
+type Server struct {
+	*grpc.Server
+	config Config
+	addr   string
+	err    error
+
+	ready    bool
+	stopping chan struct{}
+	hookOnce sync.Once
+	mux      sync.RWMutex
+}
+
+// New creates a server with the `grpc` configuration and the common interceptors, see `UnaryInterceptors`
+// and `StreamInterceptors`. Interceptors provided with `grpc.ChainUnaryInterceptor` and
+// `grpc.ChainStreamInterceptor` run after the common ones.
+func New(opts ...grpc.ServerOption) *Server {
+	return FromConfig(nil, opts...)
+}
+
+// FromConfig creates a server with the provided configuration, or the `grpc` configuration if nil, and the
+// common interceptors, see `New`
+func FromConfig(cfg *Config, opts ...grpc.ServerOption) *Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryInterceptors()...),
+		grpc.ChainStreamInterceptor(StreamInterceptors()...),
+	}, opts...)
+	return Bare(cfg, opts...)
+}
+
+// Bare creates a server with the provided configuration, or the `grpc` configuration if nil, and no
+// interceptors
+func Bare(cfg *Config, opts ...grpc.ServerOption) *Server {
+	if cfg == nil {
+		cfg = GetConfig()
+	}
+	ret := &Server{
+		config: *cfg,
+		addr:   fmt.Sprintf(":%d", cfg.Port),
+	}
+
+	var options []grpc.ServerOption
+	if cfg.TLS.Enabled {
+		loader, err := tlsx.New(cfg.TLS)
+		if err != nil {
+			ret.err = err
+		} else {
+			options = append(options, grpc.Creds(credentials.NewTLS(loader.TLSConfig())))
+		}
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		options = append(options, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		options = append(options, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	options = append(options, keepaliveOptions(cfg.Keepalive)...)
+
+	ret.Server = grpc.NewServer(append(options, opts...)...)
+	healthpb.RegisterHealthServer(ret.Server, &healthServer{})
+	return ret
+}
+
+// SetAddress sets the address where the server listens for connections, instead of the configured port
+func (s *Server) SetAddress(addr string) *Server {
+	s.addr = addr
+	return s
+}
+
+// Run listens on the configured address and serves gRPC calls. The server is registered as a `shutdown`
+// hook, so it is shut down gracefully when the service is.
+// Note: this method blocks the calling goroutine until the server is shut down, in which case it returns
+// nil, or until an error happens.
+func (s *Server) Run() error {
+	if s.err != nil {
+		return s.err
+	}
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve serves gRPC calls on the provided listener, see `Run`
+func (s *Server) Serve(ln net.Listener) error {
+	s.mux.Lock()
+	if s.stopping != nil {
+		s.mux.Unlock()
+		_ = ln.Close()
+		return nil
+	}
+	s.ready = true
+	s.mux.Unlock()
+
+	s.hookOnce.Do(func() {
+		shutdown.AddHook(s.shutdownHook, "grpc-server")
+	})
+	health.Register(health.Check{
+		Name:     ServerCheckName,
+		Fn:       s.readinessCheck,
+		CacheTTL: -1,
+	})
+
+	logx.Info("gRPC listening on: ", ln.Addr().String())
+	if err := s.Server.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Ready indicates whether the server is accepting calls
+func (s *Server) Ready() bool {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+	return s.ready
+}
+
+// Shutdown stops the server gracefully. It reports the server as not ready, stops accepting connections
+// and waits for the in-flight calls to complete, or for the context to be done, in which case the remaining
+// calls are cancelled and the context error is returned. Once stopped, later calls return nil.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mux.Lock()
+	if s.stopping == nil {
+		s.stopping = make(chan struct{})
+		s.ready = false
+		go func(done chan struct{}) {
+			s.GracefulStop()
+			logx.Info("gRPC server stopped")
+			close(done)
+		}(s.stopping)
+	}
+	stopping := s.stopping
+	s.mux.Unlock()
+
+	select {
+	case <-stopping:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		<-stopping
+		return ctx.Err()
+	}
+}
+
+func (s *Server) shutdownHook() error {
+	timeout := s.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), millis(timeout))
+	defer cancel()
+	return s.Shutdown(ctx)
+}
+
+func (s *Server) readinessCheck(context.Context) error {
+	if s.Ready() {
+		return nil
+	}
+	return errors.New("the gRPC server is not serving")
+}
+
+func keepaliveOptions(cfg KeepaliveConfig) []grpc.ServerOption {
+	var ret []grpc.ServerOption
+	params := keepalive.ServerParameters{
+		Time:                  millis(cfg.Time),
+		Timeout:               millis(cfg.Timeout),
+		MaxConnectionIdle:     millis(cfg.MaxConnectionIdle),
+		MaxConnectionAge:      millis(cfg.MaxConnectionAge),
+		MaxConnectionAgeGrace: millis(cfg.MaxConnectionAgeGrace),
+	}
+	if params != (keepalive.ServerParameters{}) {
+		ret = append(ret, grpc.KeepaliveParams(params))
+	}
+	if cfg.MinTime > 0 || cfg.PermitWithoutStream {
+		ret = append(ret, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             millis(cfg.MinTime),
+			PermitWithoutStream: cfg.PermitWithoutStream,
+		}))
+	}
+	return ret
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jucardi/go-titan/utils/paths"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ IClient = (*client)(nil)
+)
+
+// New creates a new outbound HTTP client. If no configuration is provided, the configuration under
+// the `http_client` key will be used.
+func New(cfg ...*Config) IClient {
+	var c *Config
+	if len(cfg) > 0 && cfg[0] != nil {
+		c = cfg[0]
+	} else {
+		c = getConfig()
+	}
+	return &client{
+		config: *c,
+		http: &http.Client{
+			Timeout: time.Duration(c.Timeout) * time.Millisecond,
+		},
+	}
+}
+
+type client struct {
+	config Config
+	http   *http.Client
+}
+
+func (c *client) Name() string {
+	return c.config.Name
+}
+
+func (c *client) NewRequest(method, url string) IRequest {
+	return &request{
+		client:  c,
+		method:  method,
+		url:     c.resolve(url),
+		enc:     c.config.Encoding,
+		headers: http.Header{},
+	}
+}
+
+func (c *client) Get(url string) IRequest {
+	return c.NewRequest(http.MethodGet, url)
+}
+
+func (c *client) Post(url string, body interface{}) IRequest {
+	return c.NewRequest(http.MethodPost, url).WithBody(body)
+}
+
+func (c *client) Put(url string, body interface{}) IRequest {
+	return c.NewRequest(http.MethodPut, url).WithBody(body)
+}
+
+func (c *client) Patch(url string, body interface{}) IRequest {
+	return c.NewRequest(http.MethodPatch, url).WithBody(body)
+}
+
+func (c *client) Delete(url string) IRequest {
+	return c.NewRequest(http.MethodDelete, url)
+}
+
+func (c *client) resolve(url string) string {
+	if c.config.BaseUrl == "" || strings.Contains(url, "://") {
+		return url
+	}
+	return paths.Combine(c.config.BaseUrl, url)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/components/resilience"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/middleware/cid"
+	"google.golang.org/protobuf/proto"
+)
+
+type testObj struct {
+	Name string `json:"name"`
+}
+
+func TestRetryOnServiceUnavailable(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set(rest.HeaderContentType, rest.ContentTypeJson)
+		_, _ = w.Write([]byte(`{"name":"titan"}`))
+	}))
+	defer server.Close()
+
+	obj := &testObj{}
+	err := newTestClient(server.URL).Get("/obj").Into(obj)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, "titan", obj.Name)
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set(headerRetryAfter, "10")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := defaultConfig()
+	cfg.BaseUrl = server.URL
+	cfg.RetryWaitMin = 1
+	cfg.RetryWaitMax = 300
+	cfg.Dependency = "retry-after"
+
+	start := time.Now()
+	sent := make(chan error)
+	go func() {
+		_, err := New(cfg).Get("/").Send()
+		sent <- err
+	}()
+
+	// The bulkhead permit is released while waiting for the next attempt
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 0, resilience.Bulkhead(cfg.Dependency).Snapshot().InUse)
+
+	assert.NoError(t, <-sent)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 300*time.Millisecond && elapsed < 10*time.Second)
+}
+
+func TestNoRetryOnPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).Post("/obj", &testObj{Name: "titan"}).Send()
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, int32(http.StatusServiceUnavailable), err.(*errorx.Error).Code)
+}
+
+func TestErrorBodyDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, rest.ContentTypeProto, r.Header.Get(rest.HeaderResponseType))
+		data, _ := proto.Marshal(errorx.NewNotFound("user not found"))
+		w.Header().Set(rest.HeaderContentType, rest.ContentTypeProto)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	err := newTestClient(server.URL).Get("/users/1").WithEncoding("proto").Into(&errorx.Error{})
+
+	assert.True(t, errorx.IsNotFound(err))
+	assert.Equal(t, "user not found", err.(*errorx.Error).Message)
+}
+
+func TestCorrelationIdPropagation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "some-cid", r.Header.Get(cid.HeaderCorrelationId))
+		assert.NotEmpty(t, r.Header.Get(cid.HeaderCorrelationTrace))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	inbound := httptest.NewRequest(http.MethodGet, "/", nil)
+	inbound.Header.Set(cid.HeaderCorrelationId, "some-cid")
+	c := rest.NewContext(testGinContext(inbound), false)
+
+	resp, err := newTestClient(server.URL).Get("/").WithContext(c).Send()
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.GetCode())
+}
+
+func newTestClient(url string) IClient {
+	cfg := defaultConfig()
+	cfg.BaseUrl = url
+	cfg.RetryWaitMin = 1
+	cfg.RetryWaitMax = 5
+	return New(cfg)
+}
+
+func testGinContext(req *http.Request) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = req
+	return c
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/encoding"
+)
+
+const (
+	configKey  = "http_client"
+	configName = "http-client-cfg"
+)
+
+var (
+	singleton *Config
+)
+
+type Config struct {
+	// Name is the name of the client, used as a label in the client metrics
+	Name string `json:"name,omitempty" yaml:"name,omitempty" default:"default"`
+
+	// BaseUrl is used to resolve relative request URLs
+	BaseUrl string `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+
+	// Encoding is the default encoding for request bodies and the requested response encoding.
+	// Supported values are `json` and `proto`
+	Encoding encoding.Encoding `json:"encoding" yaml:"encoding" default:"json"`
+
+	// Timeout is the timeout in milliseconds of a single attempt. Zero means no timeout
+	Timeout int64 `json:"timeout" yaml:"timeout" default:"30000"`
+
+	// MaxRetries is the maximum amount of retries for idempotent requests (GET, HEAD, OPTIONS, PUT,
+	// DELETE) that fail with a network error or a 429, 502, 503 or 504 status
+	MaxRetries int `json:"max_retries" yaml:"max_retries" default:"2"`
+
+	// RetryWaitMin is the base wait in milliseconds between retries. The wait grows exponentially
+	// on every attempt and is randomized with full jitter
+	RetryWaitMin int64 `json:"retry_wait_min" yaml:"retry_wait_min" default:"100"`
+
+	// RetryWaitMax is the maximum wait in milliseconds between retries. Also caps the wait requested by the
+	// `Retry-After` header of 429 and 503 responses, which is used instead of the exponential wait
+	RetryWaitMax int64 `json:"retry_wait_max" yaml:"retry_wait_max" default:"2000"`
+
+	// Dependency is the name of the downstream dependency used to key the circuit breaker and the
+	// bulkhead that protect the outbound calls (see the `resilience` component). If empty, calls are
+	// not protected.
+	Dependency string `json:"dependency,omitempty" yaml:"dependency,omitempty"`
+}
+
+func init() {
+	configx.AddOnReloadCallback(reloadCallback, configName)
+}
+
+func getConfig() *Config {
+	if singleton == nil {
+		reloadCallback(configx.Get())
+	}
+	return singleton
+}
+
+func reloadCallback(cfg configx.IConfig) {
+	config := defaultConfig()
+
+	logx.WithObj(
+		cfg.MapToObj(configKey, config),
+	).Fatal("unable to map http client configuration")
+
+	singleton = config
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Name:         "default",
+		Encoding:     encoding.Json,
+		Timeout:      30000,
+		MaxRetries:   2,
+		RetryWaitMin: 100,
+		RetryWaitMax: 2000,
+	}
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jucardi/go-titan/components/prometheus"
+)
+
+var (
+	requestsCounter = prometheus.Counter(prometheus.Opts{
+		Name:   "http_client_requests_total",
+		Help:   "Total number of outbound HTTP requests",
+		Labels: []string{"client", "method", "host", "code"},
+	})
+
+	requestDuration = prometheus.Histogram(prometheus.Opts{
+		Name:   "http_client_request_duration_seconds",
+		Help:   "Duration of outbound HTTP requests in seconds",
+		Labels: []string{"client", "method", "host"},
+	})
+
+	retriesCounter = prometheus.Counter(prometheus.Opts{
+		Name:   "http_client_retries_total",
+		Help:   "Total number of retried outbound HTTP requests",
+		Labels: []string{"client", "method", "host"},
+	})
+)
+
+func observe(name string, req *http.Request, resp *http.Response, elapsed time.Duration) {
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	requestsCounter.Inc(name, req.Method, req.URL.Host, code)
+	requestDuration.Observe(elapsed.Seconds(), name, req.Method, req.URL.Host)
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jucardi/go-titan/errors"
+	"github.com/jucardi/go-titan/net/encoding"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/middleware/cid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ IRequest = (*request)(nil)
+
+	contentTypes = map[encoding.Encoding]string{
+		encoding.Json:         rest.ContentTypeJson,
+		encoding.IndentedJson: rest.ContentTypeJson,
+		encoding.Protobuf:     rest.ContentTypeProto,
+	}
+)
+
+type request struct {
+	client  *client
+	method  string
+	url     string
+	enc     encoding.Encoding
+	headers http.Header
+	query   url.Values
+	body    interface{}
+	ctx     context.Context
+	timeout time.Duration
+}
+
+func (r *request) WithContext(c *rest.Context) IRequest {
+	if c == nil {
+		return r
+	}
+	if c.Request != nil {
+		r.ctx = c.Request.Context()
+	}
+	cid.Propagate(c, r.headers)
+	return r
+}
+
+func (r *request) WithHeader(key, value string) IRequest {
+	r.headers.Set(key, value)
+	return r
+}
+
+func (r *request) WithQuery(key, value string) IRequest {
+	if r.query == nil {
+		r.query = url.Values{}
+	}
+	r.query.Add(key, value)
+	return r
+}
+
+func (r *request) WithBody(body interface{}) IRequest {
+	r.body = body
+	return r
+}
+
+func (r *request) WithEncoding(enc encoding.Encoding) IRequest {
+	r.enc = enc
+	return r
+}
+
+func (r *request) WithTimeout(timeout time.Duration) IRequest {
+	r.timeout = timeout
+	return r
+}
+
+func (r *request) Send() (*Response, error) {
+	resp, err := r.client.do(r)
+	if err != nil {
+		return nil, err
+	}
+	return resp, resp.UnmarshalError()
+}
+
+func (r *request) Into(obj interface{}) error {
+	resp, err := r.client.do(r)
+	if err != nil {
+		return err
+	}
+	return resp.Unmarshal(obj)
+}
+
+// encodeBody returns the request body bytes and the content type to be sent with the request.
+func (r *request) encodeBody() ([]byte, string, error) {
+	switch b := r.body.(type) {
+	case nil:
+		return nil, "", nil
+	case []byte:
+		return b, "", nil
+	case string:
+		return []byte(b), "", nil
+	case io.Reader:
+		data, err := io.ReadAll(b)
+		return data, "", err
+	}
+
+	contentType, ok := contentTypes[r.enc]
+	if !ok {
+		return nil, "", errors.New("unsupported request encoding: ", r.enc)
+	}
+
+	msg, isProto := r.body.(proto.Message)
+
+	if contentType == rest.ContentTypeProto {
+		if !isProto {
+			return nil, "", errors.New("request body is not a protobuf message")
+		}
+		data, err := proto.Marshal(msg)
+		return data, contentType, err
+	}
+
+	if isProto {
+		data, err := protojson.Marshal(msg)
+		return data, contentType, err
+	}
+	data, err := json.Marshal(r.body)
+	return data, contentType, err
+}
+
+func (r *request) build(ctx context.Context, body []byte, contentType string) (*http.Request, error) {
+	var rdr io.Reader
+	if body != nil {
+		rdr = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, rdr)
+	if err != nil {
+		return nil, errorx.Wrap(err, "failed to create outbound request")
+	}
+
+	for k, v := range r.headers {
+		req.Header[k] = v
+	}
+
+	if len(r.query) > 0 {
+		q := req.URL.Query()
+		for k, v := range r.query {
+			for _, x := range v {
+				q.Add(k, x)
+			}
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	if contentType != "" && req.Header.Get(rest.HeaderContentType) == "" {
+		req.Header.Set(rest.HeaderContentType, contentType)
+	}
+	if responseType, ok := contentTypes[r.enc]; ok && req.Header.Get(rest.HeaderResponseType) == "" {
+		req.Header.Set(rest.HeaderResponseType, responseType)
+	}
+
+	// Propagates the remaining time of the deadline so the server does not keep working after we give up
+	if deadline, ok := ctx.Deadline(); ok && req.Header.Get(rest.HeaderRequestTimeout) == "" {
+		if remaining := time.Until(deadline).Milliseconds(); remaining > 0 {
+			req.Header.Set(rest.HeaderRequestTimeout, strconv.FormatInt(remaining, 10))
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jucardi/go-titan/components/resilience"
+	"github.com/jucardi/go-titan/errors"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+const headerRetryAfter = "Retry-After"
+
+var (
+	idempotentMethods = map[string]bool{
+		http.MethodGet:     true,
+		http.MethodHead:    true,
+		http.MethodOptions: true,
+		http.MethodPut:     true,
+		http.MethodDelete:  true,
+		http.MethodTrace:   true,
+	}
+
+	retryableStatuses = map[int]bool{
+		http.StatusTooManyRequests:    true,
+		http.StatusBadGateway:         true,
+		http.StatusServiceUnavailable: true,
+		http.StatusGatewayTimeout:     true,
+	}
+)
+
+func (c *client) do(r *request) (*Response, error) {
+	body, contentType, err := r.encodeBody()
+	if err != nil {
+		return nil, errorx.WrapBadRequest(err, "failed to encode outbound request body")
+	}
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	maxRetries := 0
+	if idempotentMethods[r.method] {
+		maxRetries = c.config.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := r.build(ctx, body, contentType)
+		if err != nil {
+			return nil, err
+		}
+
+		// The bulkhead permit is held by each attempt, and released while waiting for the next one
+		release, done, err := c.admit(ctx)
+		if err != nil {
+			return nil, c.wrapResilienceError(ctx, err)
+		}
+
+		start := time.Now()
+		resp, err := c.http.Do(req)
+		observe(c.config.Name, req, resp, time.Since(start))
+
+		if done != nil {
+			done(err == nil && resp.StatusCode < http.StatusInternalServerError)
+		}
+
+		if attempt >= maxRetries || !shouldRetry(ctx, resp, err) {
+			defer release()
+			if err != nil {
+				return nil, wrapTransportError(ctx, err)
+			}
+			return newResponse(resp)
+		}
+
+		wait := c.backoff(attempt)
+		if after, ok := c.retryAfter(resp); ok {
+			wait = after
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		release()
+
+		logx.Debugf("outbound request %s %s failed, retrying in %s (attempt %d of %d)", r.method, req.URL.Redacted(), wait, attempt+1, maxRetries)
+		retriesCounter.Inc(c.config.Name, r.method, req.URL.Host)
+
+		select {
+		case <-ctx.Done():
+			return nil, wrapTransportError(ctx, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// admit acquires a permit of the bulkhead of the dependency and checks its circuit breaker. Returns the
+// function that releases the permit, and the function that reports the outcome of the call to the breaker.
+// Both are no-ops if the client has no dependency.
+func (c *client) admit(ctx context.Context) (func(), func(success bool), error) {
+	if c.config.Dependency == "" {
+		return func() {}, nil, nil
+	}
+	release, err := resilience.Bulkhead(c.config.Dependency).Acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	done, err := resilience.Breaker(c.config.Dependency).Allow()
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return release, done, nil
+}
+
+// retryAfter returns the wait requested by the `Retry-After` header of a 429 or 503 response, either in
+// seconds or as a date, capped by the maximum wait between retries
+func (c *client) retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := resp.Header.Get(headerRetryAfter)
+	if value == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = time.Until(date)
+	} else {
+		return 0, false
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	if max := time.Duration(c.config.RetryWaitMax) * time.Millisecond; max > 0 && wait > max {
+		wait = max
+	}
+	return wait, true
+}
+
+// backoff returns an exponential wait for the provided attempt randomized with full jitter.
+func (c *client) backoff(attempt int) time.Duration {
+	min := time.Duration(c.config.RetryWaitMin) * time.Millisecond
+	max := time.Duration(c.config.RetryWaitMax) * time.Millisecond
+
+	wait := min << uint(attempt)
+	if wait <= 0 || wait > max {
+		wait = max
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return retryableStatuses[resp.StatusCode]
+}
+
+func wrapTransportError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+		return errorx.WrapWithCode(err, http.StatusGatewayTimeout, "outbound request timed out")
+	}
+	return errorx.WrapBadGateway(err, "outbound request failed")
+}
+
+func (c *client) wrapResilienceError(ctx context.Context, err error) error {
+	if err == resilience.ErrCircuitOpen || err == resilience.ErrBulkheadFull {
+		return errorx.WrapWithCodef(err, http.StatusServiceUnavailable, "dependency '%s' is unavailable", c.config.Dependency)
+	}
+	return wrapTransportError(ctx, err)
+}
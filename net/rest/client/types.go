@@ -0,0 +1,107 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jucardi/go-titan/net/encoding"
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+// IClient defines the contract for an outbound HTTP client which follows the titan conventions:
+// correlation ID propagation, proto/JSON negotiation and `*errorx.Error` responses.
+type IClient interface {
+	// Name returns the name of the client, used as a label in the client metrics
+	Name() string
+
+	// NewRequest creates a new request for the provided method and URL. If the URL is relative, it
+	// will be resolved using the configured `BaseUrl`
+	NewRequest(method, url string) IRequest
+
+	// Get is a shortcut for NewRequest(http.MethodGet, url)
+	Get(url string) IRequest
+
+	// Post is a shortcut for NewRequest(http.MethodPost, url).WithBody(body)
+	Post(url string, body interface{}) IRequest
+
+	// Put is a shortcut for NewRequest(http.MethodPut, url).WithBody(body)
+	Put(url string, body interface{}) IRequest
+
+	// Patch is a shortcut for NewRequest(http.MethodPatch, url).WithBody(body)
+	Patch(url string, body interface{}) IRequest
+
+	// Delete is a shortcut for NewRequest(http.MethodDelete, url)
+	Delete(url string) IRequest
+}
+
+// IRequest defines a request builder for outbound calls
+type IRequest interface {
+	// WithContext propagates the request scope of an inbound request: the correlation ID and trace
+	// headers are forwarded, and the request will be cancelled if the inbound request is or its deadline
+	// is exceeded. The remaining time until the deadline is sent in the `X-Request-Timeout` header.
+	WithContext(c *rest.Context) IRequest
+
+	// WithHeader sets a header to the outbound request
+	WithHeader(key, value string) IRequest
+
+	// WithQuery adds a query parameter to the outbound request
+	WithQuery(key, value string) IRequest
+
+	// WithBody sets the request body. `[]byte`, `string` and `io.Reader` values are sent as is, any
+	// other value is encoded using the request encoding.
+	WithBody(body interface{}) IRequest
+
+	// WithEncoding overrides the client encoding for this request. Determines the encoding of the
+	// request body and the `Response-Type` requested to the server.
+	WithEncoding(enc encoding.Encoding) IRequest
+
+	// WithTimeout sets a deadline for the whole request, including retries.
+	WithTimeout(timeout time.Duration) IRequest
+
+	// Send executes the request. If the response status is an error status, the returned error is
+	// the `*errorx.Error` decoded from the response body.
+	Send() (*Response, error)
+
+	// Into executes the request and decodes the response body into the provided object.
+	Into(obj interface{}) error
+}
+
+// Response is the response obtained by an outbound request. It implements `encoding.IResponseDecoder`
+// to decode the response body.
+type Response struct {
+	*http.Response
+	encoding.IResponseDecoder
+	body []byte
+}
+
+// GetCode returns the status code of the response
+func (r *Response) GetCode() int {
+	return r.StatusCode
+}
+
+// BodyBytes returns the response body bytes
+func (r *Response) BodyBytes() ([]byte, error) {
+	return r.body, nil
+}
+
+// Headers returns the headers contained in the response
+func (r *Response) Headers() http.Header {
+	return r.Header
+}
+
+// Request returns the request that was sent to obtain this response
+func (r *Response) Request() *http.Request {
+	return r.Response.Request
+}
+
+func newResponse(resp *http.Response) (*Response, error) {
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	ret := &Response{Response: resp, body: data}
+	ret.IResponseDecoder = encoding.NewResponseDecoder(ret)
+	return ret, nil
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"reflect"
+
+	"github.com/ugorji/go/codec"
+)
+
+var (
+	// MsgpackHandle is the MessagePack codec configuration used to encode and decode MessagePack bodies
+	MsgpackHandle = newMsgpackHandle()
+
+	// CborHandle is the CBOR codec configuration used to encode and decode CBOR bodies
+	CborHandle = newCborHandle()
+)
+
+func newMsgpackHandle() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{}
+	h.RawToString = true
+	h.WriteExt = true
+	h.MapType = reflect.TypeOf(map[string]interface{}{})
+	return h
+}
+
+func newCborHandle() *codec.CborHandle {
+	h := &codec.CborHandle{}
+	h.MapType = reflect.TypeOf(map[string]interface{}{})
+	return h
+}
@@ -1,14 +1,28 @@
 package config
 
+import "github.com/jucardi/go-titan/net/tlsx"
+
 const (
 	EncodingAuto         Encoding = "auto"
 	EncodingJson         Encoding = "json"
 	EncodingIndentedJson Encoding = "indented-json"
 	EncodingProto        Encoding = "proto"
+	EncodingYaml         Encoding = "yaml"
+	EncodingXml          Encoding = "xml"
+	EncodingMsgpack      Encoding = "msgpack"
+	EncodingCbor         Encoding = "cbor"
+)
+
+const (
+	ErrorFormatAuto    ErrorFormat = "auto"
+	ErrorFormatErrorx  ErrorFormat = "errorx"
+	ErrorFormatProblem ErrorFormat = "problem"
 )
 
 type Encoding string
 
+type ErrorFormat string
+
 type RestConfig struct {
 	// Port indicates the port where an API should be listening to
 	HttpPort int `json:"http_port" yaml:"http_port" env:"TITAN_REST_HTTP_PORT" default:"8080"`
@@ -24,19 +38,85 @@ type RestConfig struct {
 	// ContextPath indicates the context path to be used by the API
 	ContextPath string `json:"context_path" yaml:"context_path" env:"TITAN_REST_CONTEXT_PATH"`
 
+	// TLS contains the TLS configuration of the API listener
+	TLS tlsx.Config `json:"tls" yaml:"tls"`
+
+	// AdminTLS contains the TLS configuration of the admin listener, only used if the admin routes are
+	// served on a different port than the API
+	AdminTLS tlsx.Config `json:"admin_tls" yaml:"admin_tls"`
+
+	// Server contains the configuration of the HTTP servers of the API and admin listeners
+	Server ServerConfig `json:"server" yaml:"server"`
+
 	// Response contains the configuration on how the response should be handled
 	Response ResponseConfig `json:"response" yaml:"response"`
 
 	// Reporting contains the configuration about how the middleware handles logging
 	Reporting ReportingConfig `json:"reporting" yaml:"reporting"`
 
+	// Metrics contains the configuration of the request metrics middlewares
+	Metrics MetricsConfig `json:"metrics" yaml:"metrics"`
+
 	// RequestLimitSize is the max byte size allowed in the request body. Zero means no limit. Default is 5Mib
 	RequestLimitSize int64 `json:"request_limit_size" yaml:"request_limit_size" default:"5242880"`
 
+	// Timeout contains the configuration of the request timeout middleware
+	Timeout TimeoutConfig `json:"timeout" yaml:"timeout"`
+
+	// RateLimit contains the configuration of the request rate limiting middleware
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit"`
+
+	// Idempotency contains the configuration of the `Idempotency-Key` middleware
+	Idempotency IdempotencyConfig `json:"idempotency" yaml:"idempotency"`
+
+	// Cache contains the configuration of the response caching middleware
+	Cache CacheConfig `json:"cache" yaml:"cache"`
+
+	// Compression contains the configuration of the response compression and request decompression middleware
+	Compression CompressionConfig `json:"compression" yaml:"compression"`
+
+	// WebSocket contains the configuration of the WebSocket connections
+	WebSocket WebSocketConfig `json:"websocket" yaml:"websocket"`
+
+	// Cors contains the configuration of the CORS middleware
+	Cors CorsConfig `json:"cors" yaml:"cors"`
+
+	// Security contains the configuration of the security headers middleware
+	Security SecurityConfig `json:"security" yaml:"security"`
+
 	// Verbose enables verbose mode to the Gin router
 	Verbose bool `json:"verbose" yaml:"verbose"`
 }
 
+// ServerConfig contains the timeouts and limits of the HTTP servers. Durations are in milliseconds, zero
+// means no timeout.
+type ServerConfig struct {
+	// ReadTimeout is the maximum duration for reading an entire request, including the body
+	ReadTimeout int64 `json:"read_timeout" yaml:"read_timeout"`
+
+	// ReadHeaderTimeout is the maximum duration for reading the request headers. Default is 10 seconds
+	ReadHeaderTimeout int64 `json:"read_header_timeout" yaml:"read_header_timeout" default:"10000"`
+
+	// WriteTimeout is the maximum duration before timing out writes of the response. Streamed responses
+	// are cut when it elapses, so it should be left as zero if the API streams responses
+	WriteTimeout int64 `json:"write_timeout" yaml:"write_timeout"`
+
+	// IdleTimeout is the maximum duration to wait for the next request when keep-alives are enabled.
+	// Default is 2 minutes
+	IdleTimeout int64 `json:"idle_timeout" yaml:"idle_timeout" default:"120000"`
+
+	// MaxHeaderBytes is the maximum size in bytes of the request headers. Default is 1MiB
+	MaxHeaderBytes int `json:"max_header_bytes" yaml:"max_header_bytes" default:"1048576"`
+
+	// DrainDelay is the duration the engine keeps serving requests while reporting it is not ready before
+	// it stops accepting connections on shutdown, giving load balancers time to stop routing requests to it
+	DrainDelay int64 `json:"drain_delay" yaml:"drain_delay"`
+
+	// ShutdownTimeout is the maximum duration to wait for in-flight requests to complete when the service
+	// shuts down. Default is 30 seconds
+	ShutdownTimeout int64 `json:"shutdown_timeout" yaml:"shutdown_timeout" default:"30000"`
+}
+
 type ResponseConfig struct {
 	// Encoding indicates the responses will be encoded in controllers. If using the provided context functions
 	// Send, SendOrErr, StatusOrErr, SendError, this will determine how the message will be encoded.
@@ -51,8 +131,28 @@ type ResponseConfig struct {
 	// ErrorBodies indicates whether serializing errors and writing them to the response bodies should be enabled
 	ErrorBodies bool `json:"error_bodies" yaml:"error_bodies" default:"true"`
 
+	// ErrorFormat indicates how error responses are represented:
+	//  - `errorx`:  the `errorx.Error` schema, encoded as any other response.
+	//  - `problem`: RFC 7807 `application/problem+json`.
+	//  - `auto`:    (default) `problem` if the client prefers `application/problem+json` in the `Accept`
+	//               header, otherwise `errorx`.
+	//  - any other value is the name of a custom error renderer registered with `rest.RegisterErrorRenderer`
+	ErrorFormat ErrorFormat `json:"error_format" yaml:"error_format" default:"auto"`
+
 	// ErrorStackTrace indicates whether stack traces should be sent to the client when an error occurs
 	ErrorStackTrace bool `json:"error_stack_trace" yaml:"error_stack_trace"`
+
+	// StreamHeartbeat is the interval in milliseconds of the heartbeat comments sent in Server-Sent Events
+	// streams to keep idle connections open through proxies. Negative disables heartbeats. Default is 15 seconds
+	StreamHeartbeat int64 `json:"stream_heartbeat" yaml:"stream_heartbeat" default:"15000"`
+}
+
+// MetricsConfig is the configuration of the request metrics middlewares
+type MetricsConfig struct {
+	// MaxRoutes is the maximum number of routes tracked by the metrics, to bound the number of series. The
+	// requests of the routes beyond the cap are aggregated as `OTHER`. Negative disables the cap. Default
+	// is 500
+	MaxRoutes int `json:"max_routes" yaml:"max_routes" default:"500"`
 }
 
 // ReportingConfig is the configuration for the middleware logging
@@ -69,3 +169,250 @@ type ReportingConfig struct {
 	// StackTrace indicates whether the stack trace should be appended to the dump
 	StackTrace bool `json:"stack_trace" yaml:"stack_trace"`
 }
+
+// CompressionConfig is the configuration for the compression middleware
+type CompressionConfig struct {
+	// Enabled indicates whether responses should be compressed when the client accepts it
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Encodings is the list of content codings used to compress responses in order of preference, used when
+	// the client accepts several of them equally. Supported codings are `zstd`, `gzip` and `deflate`.
+	// Default is zstd, gzip and deflate
+	Encodings []string `json:"encodings,omitempty" yaml:"encodings,omitempty"`
+
+	// Level is the compression level, from 1 (fastest) to 9 (best compression). Zero uses the default level
+	// of each coding
+	Level int `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// MinSize is the minimum response size in bytes to be compressed. Default is 1024
+	MinSize int `json:"min_size" yaml:"min_size" default:"1024"`
+
+	// ContentTypes is the allowlist of response content types to be compressed. Entries may contain
+	// wildcards, e.g. `text/*` or `application/*+json`. Default is JSON, YAML, XML, Protobuf, MessagePack,
+	// CBOR, javascript and text types
+	ContentTypes []string `json:"content_types,omitempty" yaml:"content_types,omitempty"`
+
+	// DisableDecompression turns off the decompression of request bodies sent with a `Content-Encoding`
+	DisableDecompression bool `json:"disable_decompression" yaml:"disable_decompression"`
+}
+
+// CorsConfig is the configuration for the Cross-Origin Resource Sharing middleware
+type CorsConfig struct {
+	// Disabled turns off CORS handling, no CORS headers will be set and preflight requests will not be answered
+	Disabled bool `json:"disabled" yaml:"disabled"`
+
+	// AllowedOrigins is the list of origins allowed to make cross-origin requests. Entries can be exact
+	// origins (`https://app.example.com`), patterns (`https://*.example.com`) or `*` to allow any origin.
+	// Default is `*`. The `*` entry is ignored if `allow_credentials` is enabled, so the allowed origins must
+	// be listed explicitly
+	AllowedOrigins []string `json:"allowed_origins,omitempty" yaml:"allowed_origins,omitempty"`
+
+	// AllowedMethods is the list of methods allowed in cross-origin requests. Default is GET, HEAD, PUT,
+	// POST, PATCH, DELETE and OPTIONS
+	AllowedMethods []string `json:"allowed_methods,omitempty" yaml:"allowed_methods,omitempty"`
+
+	// AllowedHeaders is the list of request headers allowed in cross-origin requests. Use `*` to allow any
+	// header requested in a preflight. Default is Content-Type, Authorization, X-Requested-By, Response-Type,
+	// X-CID and X-CID-Trace
+	AllowedHeaders []string `json:"allowed_headers,omitempty" yaml:"allowed_headers,omitempty"`
+
+	// ExposedHeaders is the list of response headers browsers are allowed to access. Default is X-CID and X-CID-Trace
+	ExposedHeaders []string `json:"exposed_headers,omitempty" yaml:"exposed_headers,omitempty"`
+
+	// AllowCredentials indicates whether cross-origin requests may include credentials (cookies, authorization
+	// headers or client certificates). When enabled, the request origin is reflected instead of using `*`,
+	// and only the origins listed in `allowed_origins` are allowed
+	AllowCredentials bool `json:"allow_credentials" yaml:"allow_credentials"`
+
+	// MaxAge is the time in seconds browsers may cache the result of a preflight request
+	MaxAge int `json:"max_age" yaml:"max_age" default:"600"`
+}
+
+// SecurityConfig is the configuration for the security headers middleware
+type SecurityConfig struct {
+	// Enabled indicates whether the security headers should be set in the responses
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Default is the policy applied to the requests that do not match any of the configured groups. Any
+	// header left empty uses the middleware default value.
+	Default SecurityPolicy `json:"default" yaml:"default"`
+
+	// Groups contains policy overrides for route groups, keyed by the path prefix of the group including the
+	// context path, e.g. "/user/v1/docs". The overrides of the longest matching prefix are applied on top of
+	// the default policy.
+	Groups map[string]SecurityPolicy `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// SecurityPolicy contains the values of the security headers. Setting a value to "-" omits the header.
+type SecurityPolicy struct {
+	// StrictTransportSecurity is the `Strict-Transport-Security` (HSTS) header.
+	// Default is "max-age=31536000; includeSubDomains"
+	StrictTransportSecurity string `json:"strict_transport_security,omitempty" yaml:"strict_transport_security,omitempty"`
+
+	// ContentTypeOptions is the `X-Content-Type-Options` header. Default is "nosniff"
+	ContentTypeOptions string `json:"content_type_options,omitempty" yaml:"content_type_options,omitempty"`
+
+	// FrameOptions is the `X-Frame-Options` header. Default is "DENY"
+	FrameOptions string `json:"frame_options,omitempty" yaml:"frame_options,omitempty"`
+
+	// ReferrerPolicy is the `Referrer-Policy` header. Default is "strict-origin-when-cross-origin"
+	ReferrerPolicy string `json:"referrer_policy,omitempty" yaml:"referrer_policy,omitempty"`
+
+	// PermissionsPolicy is the `Permissions-Policy` header. Not set by default
+	PermissionsPolicy string `json:"permissions_policy,omitempty" yaml:"permissions_policy,omitempty"`
+
+	// ContentSecurityPolicy is the `Content-Security-Policy` header.
+	// Default is "default-src 'none'; frame-ancestors 'none'", suitable for APIs
+	ContentSecurityPolicy string `json:"content_security_policy,omitempty" yaml:"content_security_policy,omitempty"`
+
+	// ContentSecurityPolicyReportOnly is the `Content-Security-Policy-Report-Only` header. Not set by default
+	ContentSecurityPolicyReportOnly string `json:"content_security_policy_report_only,omitempty" yaml:"content_security_policy_report_only,omitempty"`
+}
+
+// RateLimitConfig is the configuration for the request rate limiting middleware
+type RateLimitConfig struct {
+	// Enabled indicates whether requests should be rate limited
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Default is the policy applied to the requests that do not match any of the configured groups
+	Default RateLimitPolicy `json:"default" yaml:"default"`
+
+	// Groups contains the policies for route groups, keyed by the path prefix of the group including the
+	// context path, e.g. "/user/v1/admin". The policy of the longest matching prefix is applied.
+	Groups map[string]RateLimitPolicy `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// RateLimitPolicy defines how requests are rate limited
+type RateLimitPolicy struct {
+	// Algorithm is the rate limiting algorithm, either `token_bucket` or `sliding_window`. Default is `token_bucket`
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+
+	// Limit is the amount of requests allowed per period. Zero means no limit
+	Limit int `json:"limit" yaml:"limit"`
+
+	// Period is the duration of the period in milliseconds. Default is 1 second
+	Period int64 `json:"period,omitempty" yaml:"period,omitempty"`
+
+	// Burst is the capacity of the bucket when using `token_bucket`. Defaults to `Limit`
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+
+	// Key indicates how clients are identified:
+	//  - `ip`:            (default) by the client IP.
+	//  - `route`:         by the matched route, limiting the route as a whole.
+	//  - `principal`:     by the authenticated principal, or by client IP for anonymous requests.
+	//  - `header:{name}`: by the value of a header, e.g. `header:X-API-Key`, for authenticated requests. By
+	//                     client IP if the header is missing or the request is anonymous.
+	//  - any other value is the name of a custom key extractor registered in the ratelimit middleware.
+	//
+	// The client IP is resolved by gin, which trusts the `X-Forwarded-For` and `X-Real-Ip` headers sent by
+	// any client unless the trusted proxies of the engine are set, see `gin.Engine.SetTrustedProxies`.
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// TimeoutConfig is the configuration for the request timeout middleware. Durations are in milliseconds,
+// zero means no timeout.
+type TimeoutConfig struct {
+	// Default is the maximum duration of the requests that do not match any of the configured groups
+	Default int64 `json:"default" yaml:"default"`
+
+	// Groups contains the timeouts of route groups, keyed by the path prefix of the group including the
+	// context path, e.g. "/user/v1/reports". The timeout of the longest matching prefix is applied.
+	Groups map[string]int64 `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	// Max is the maximum timeout a client may request with the `X-Request-Timeout` or `grpc-timeout`
+	// headers. If not set, clients may only shorten the timeout of the route.
+	Max int64 `json:"max" yaml:"max"`
+
+	// DisableHeaders turns off the timeouts requested by clients in the `X-Request-Timeout` and
+	// `grpc-timeout` headers
+	DisableHeaders bool `json:"disable_headers" yaml:"disable_headers"`
+}
+
+// IdempotencyConfig is the configuration for the idempotency middleware. Durations are in milliseconds.
+type IdempotencyConfig struct {
+	// Enabled indicates whether requests with an idempotency key should be deduplicated
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Header is the request header that contains the idempotency key. Default is `Idempotency-Key`
+	Header string `json:"header,omitempty" yaml:"header,omitempty" default:"Idempotency-Key"`
+
+	// Methods is the list of methods the idempotency keys apply to. Default is POST and PATCH
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+
+	// Required indicates whether requests using any of the methods must provide an idempotency key, rejecting
+	// them with 400 Bad Request otherwise
+	Required bool `json:"required" yaml:"required"`
+
+	// TTL is how long the responses are kept to be replayed. Default is 24 hours
+	TTL int64 `json:"ttl" yaml:"ttl" default:"86400000"`
+
+	// LockTimeout is how long a key is reserved for a request in progress. If the request does not complete
+	// before it elapses, e.g. because the instance crashed, the key can be used again. Default is 1 minute
+	LockTimeout int64 `json:"lock_timeout" yaml:"lock_timeout" default:"60000"`
+}
+
+// CacheConfig is the configuration for the response caching middleware
+type CacheConfig struct {
+	// Enabled indicates whether the cache policies should be applied to the GET and HEAD requests
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// Default is the policy applied to the requests that do not match any of the configured groups
+	Default CachePolicy `json:"default" yaml:"default"`
+
+	// Groups contains the policies for route groups, keyed by the path prefix of the group including the
+	// context path, e.g. "/user/v1/catalog". The policy of the longest matching prefix is applied.
+	Groups map[string]CachePolicy `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	// MaxEntries is the maximum amount of responses kept in the server-side cache. Default is 1000
+	MaxEntries int `json:"max_entries" yaml:"max_entries" default:"1000"`
+
+	// MaxEntrySize is the maximum size in bytes of a response body to be kept in the server-side cache.
+	// Default is 1MiB
+	MaxEntrySize int `json:"max_entry_size" yaml:"max_entry_size" default:"1048576"`
+}
+
+// CachePolicy defines how the responses of a route are cached
+type CachePolicy struct {
+	// ETag indicates how the `ETag` header is generated from the response body: `strong` (default), `weak`
+	// or `none`. Requests with a matching `If-None-Match` header are answered with 304 Not Modified
+	ETag string `json:"etag,omitempty" yaml:"etag,omitempty"`
+
+	// CacheControl is the `Cache-Control` header set in successful responses, e.g. "public, max-age=60".
+	// Not set if empty
+	CacheControl string `json:"cache_control,omitempty" yaml:"cache_control,omitempty"`
+
+	// TTL is the duration in milliseconds successful responses are kept in the server-side cache. Zero
+	// means the responses are not cached server-side
+	TTL int64 `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// WebSocketConfig is the configuration of the WebSocket connections. Durations are in milliseconds.
+type WebSocketConfig struct {
+	// AllowedOrigins is the list of origins allowed to open connections. Entries can be exact origins
+	// (`https://app.example.com`), patterns (`https://*.example.com`) or `*` to allow any origin. If empty,
+	// only same-origin connections and clients which do not send an `Origin` header are allowed
+	AllowedOrigins []string `json:"allowed_origins,omitempty" yaml:"allowed_origins,omitempty"`
+
+	// PingInterval is the interval of the pings sent to keep the connections alive, negative to disable the
+	// pings. Default is 30 seconds
+	PingInterval int64 `json:"ping_interval" yaml:"ping_interval" default:"30000"`
+
+	// PongTimeout is the maximum duration to wait for a pong or message from the client before the
+	// connection is considered lost, negative to wait indefinitely. Must be greater than the ping interval.
+	// Default is 1 minute
+	PongTimeout int64 `json:"pong_timeout" yaml:"pong_timeout" default:"60000"`
+
+	// WriteTimeout is the maximum duration of a write to the client. Default is 10 seconds
+	WriteTimeout int64 `json:"write_timeout" yaml:"write_timeout" default:"10000"`
+
+	// MaxMessageSize is the maximum size in bytes of a message received from the client. Connections
+	// exceeding it are closed. Default is 1MiB
+	MaxMessageSize int64 `json:"max_message_size" yaml:"max_message_size" default:"1048576"`
+
+	// SendBuffer is the amount of outgoing messages queued per connection. Connections whose queue is full
+	// are considered slow and closed when broadcasting. Default is 64
+	SendBuffer int `json:"send_buffer" yaml:"send_buffer" default:"64"`
+
+	// EnableCompression negotiates the per-message deflate extension with the clients that support it
+	EnableCompression bool `json:"enable_compression" yaml:"enable_compression"`
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"github.com/jucardi/go-titan/configx"
 	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/tlsx"
 )
 
 const (
@@ -42,13 +43,52 @@ func defaultConfig() *RestConfig {
 		HttpPort:    8080,
 		AdminPort:   15000,
 		ContextPath: "",
+		TLS: tlsx.Config{
+			ReloadInterval: 10000,
+		},
+		AdminTLS: tlsx.Config{
+			ReloadInterval: 10000,
+		},
+		Server: ServerConfig{
+			ReadHeaderTimeout: 10000,
+			IdleTimeout:       120000,
+			MaxHeaderBytes:    1048576,
+			ShutdownTimeout:   30000,
+		},
 		Response: ResponseConfig{
 			Encoding:         EncodingAuto,
 			FallbackEncoding: EncodingJson,
 			ErrorBodies:      true,
+			ErrorFormat:      ErrorFormatAuto,
 			ErrorStackTrace:  false,
+			StreamHeartbeat:  15000,
+		},
+		Reporting: ReportingConfig{},
+		Metrics: MetricsConfig{
+			MaxRoutes: 500,
 		},
-		Reporting:        ReportingConfig{},
 		RequestLimitSize: 5242880,
+		Idempotency: IdempotencyConfig{
+			Header:      "Idempotency-Key",
+			TTL:         86400000,
+			LockTimeout: 60000,
+		},
+		Cache: CacheConfig{
+			MaxEntries:   1000,
+			MaxEntrySize: 1048576,
+		},
+		Compression: CompressionConfig{
+			MinSize: 1024,
+		},
+		WebSocket: WebSocketConfig{
+			PingInterval:   30000,
+			PongTimeout:    60000,
+			WriteTimeout:   10000,
+			MaxMessageSize: 1048576,
+			SendBuffer:     64,
+		},
+		Cors: CorsConfig{
+			MaxAge: 600,
+		},
 	}
 }
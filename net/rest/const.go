@@ -12,8 +12,26 @@ const (
 	// from the request content type.
 	HeaderResponseType = "Response-Type"
 
-	// HeaderContentEncoding is the standard Content-Encoder header user in rest
-	HeaderContentEncoding = "Content-Encoder"
+	// HeaderAccept is the standard header used by clients to indicate the accepted response media types
+	HeaderAccept = "Accept"
+
+	// HeaderContentEncoding is the standard Content-Encoding header used in rest
+	HeaderContentEncoding = "Content-Encoding"
+
+	// HeaderAcceptEncoding is the standard header used by clients to indicate the accepted content codings
+	HeaderAcceptEncoding = "Accept-Encoding"
+
+	// HeaderLastEventID is the standard header sent by Server-Sent Events clients when reconnecting, with the ID
+	// of the last event received
+	HeaderLastEventID = "Last-Event-ID"
+
+	// HeaderRequestTimeout is a custom header used by clients to indicate how long they will wait for the
+	// response, either as a duration (`1.5s`, `300ms`) or in milliseconds
+	HeaderRequestTimeout = "X-Request-Timeout"
+
+	// HeaderGrpcTimeout is the gRPC header used by clients to indicate how long they will wait for the
+	// response, e.g. `300m` for 300 milliseconds
+	HeaderGrpcTimeout = "grpc-timeout"
 )
 
 // Section for MIME Content Type values. Added a selection, could grow to add more or all.
@@ -22,12 +40,30 @@ const (
 	// ContentTypeJson is the standard MIME type for json objects
 	ContentTypeJson = "application/json"
 
+	// ContentTypeProblemJson is the standard MIME type for RFC 7807 problem details
+	ContentTypeProblemJson = "application/problem+json"
+
 	// ContentTypeProto is an non-standard yet commonly used MINE type for protobuf encoding
 	ContentTypeProto = "application/x-protobuf"
 
 	// ContentTypeYaml is an non-standard yet commonly used MINE type for YAML encoding
 	ContentTypeYaml = "application/x-yaml"
 
+	// ContentTypeXml is the standard MIME type for XML encoding
+	ContentTypeXml = "application/xml"
+
+	// ContentTypeMsgpack is an non-standard yet commonly used MIME type for MessagePack encoding
+	ContentTypeMsgpack = "application/x-msgpack"
+
+	// ContentTypeCbor is the standard MIME type for CBOR encoding
+	ContentTypeCbor = "application/cbor"
+
+	// ContentTypeEventStream is the standard MIME type for Server-Sent Events streams
+	ContentTypeEventStream = "text/event-stream"
+
+	// ContentTypeNdjson is the commonly used MIME type for newline delimited JSON streams
+	ContentTypeNdjson = "application/x-ndjson"
+
 	// ContentTypeText is the standard MIME type for javascript files
 	ContentType = "application/javascript"
 
@@ -63,4 +99,10 @@ const (
 
 	// ContentTypeGzip is the content type for gzipped data
 	ContentTypeGzip = "application/gzip"
+
+	// ContentTypeForm is the standard MIME type for URL encoded forms
+	ContentTypeForm = "application/x-www-form-urlencoded"
+
+	// ContentTypeMultipart is the standard MIME type for multipart forms
+	ContentTypeMultipart = "multipart/form-data"
 )
@@ -2,6 +2,8 @@ package rest
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
@@ -18,9 +20,10 @@ import (
 // Context is a `gin.Context` wrapper that allows extending context functionality.
 type Context struct {
 	*gin.Context
-	enc     Encoder
-	reqBody []byte
-	aborted bool
+	enc      Encoder
+	explicit bool
+	reqBody  []byte
+	aborted  bool
 }
 
 func NewContext(ctx *gin.Context, rewindable bool) *Context {
@@ -107,7 +110,7 @@ func (c *Context) SendError(err error) {
 // The response will be JSON encoded regardless of the encoding configuration.
 //
 func (c *Context) SendErrorJson(err error) {
-	c.setEncoding(encoders.Json).sendError(err)
+	c.forceEncoding(encoders.Json).sendError(err)
 }
 
 // SendErrorJsonIndent aborts any pending middleware handlers and indents this error instance as a response object.
@@ -117,7 +120,7 @@ func (c *Context) SendErrorJson(err error) {
 // The response will be JSON encoded regardless of the encoding configuration.
 //
 func (c *Context) SendErrorJsonIndent(err error) {
-	c.setEncoding(encoders.IndentedJson).sendError(err)
+	c.forceEncoding(encoders.IndentedJson).sendError(err)
 }
 
 // SendErrorProtobuf aborts any pending middleware handlers and indents this error instance as a response object.
@@ -127,7 +130,7 @@ func (c *Context) SendErrorJsonIndent(err error) {
 // The response will be Protobuf encoded regardless of the encoding configuration.
 //
 func (c *Context) SendErrorProtobuf(err error) {
-	c.setEncoding(encoders.Protobuf).sendError(err)
+	c.forceEncoding(encoders.Protobuf).sendError(err)
 }
 
 // SendOrErr marshals the provided response interface and assigns the provided status (or 200 OK if not provided)
@@ -149,7 +152,7 @@ func (c *Context) SendOrErr(resp interface{}, err error, httpStatus ...int) {
 // The response message (resp object or error) will be encoded as JSON regardless of the configuration.
 //
 func (c *Context) SendOrErrJson(resp interface{}, err error, httpStatus ...int) {
-	c.setEncoding(encoders.Json).sendOrErr(resp, err, httpStatus...)
+	c.forceEncoding(encoders.Json).sendOrErr(resp, err, httpStatus...)
 }
 
 // SendOrErrJsonIndent marshals the provided response interface and assigns the provided status (or 200 OK if not provided)
@@ -159,7 +162,7 @@ func (c *Context) SendOrErrJson(resp interface{}, err error, httpStatus ...int)
 // The response message (resp object or error) will be encoded as JSON regardless of the configuration.
 //
 func (c *Context) SendOrErrJsonIndent(resp interface{}, err error, httpStatus ...int) {
-	c.setEncoding(encoders.IndentedJson).sendOrErr(resp, err, httpStatus...)
+	c.forceEncoding(encoders.IndentedJson).sendOrErr(resp, err, httpStatus...)
 }
 
 // SendOrErrProtobuf marshals the provided response interface and assigns the provided status (or 200 OK if not provided)
@@ -169,7 +172,7 @@ func (c *Context) SendOrErrJsonIndent(resp interface{}, err error, httpStatus ..
 // The response message (resp object or error) will be encoded as Protobuf regardless of the configuration.
 //
 func (c *Context) SendOrErrProtobuf(resp interface{}, err error, httpStatus ...int) {
-	c.setEncoding(encoders.Protobuf).sendOrErr(resp, err, httpStatus...)
+	c.forceEncoding(encoders.Protobuf).sendOrErr(resp, err, httpStatus...)
 }
 
 // StatusOrErr assigns the provided status code (or 200 OK if not provided) unless `err` is not nil, in which case,
@@ -191,7 +194,7 @@ func (c *Context) StatusOrErr(err error, httpStatus ...int) {
 // The response message will be encoded as JSON regardless of the configuration.
 //
 func (c *Context) StatusOrErrJson(err error, httpStatus ...int) {
-	c.setEncoding(encoders.Json).statusOrErr(err, httpStatus...)
+	c.forceEncoding(encoders.Json).statusOrErr(err, httpStatus...)
 }
 
 // StatusOrErrProtobuf assigns the provided status code (or 200 OK if not provided) unless `err` is not nil, in which case,
@@ -201,7 +204,7 @@ func (c *Context) StatusOrErrJson(err error, httpStatus ...int) {
 // The response message will be encoded as Protobuf regardless of the configuration.
 //
 func (c *Context) StatusOrErrProtobuf(err error, httpStatus ...int) {
-	c.setEncoding(encoders.Protobuf).statusOrErr(err, httpStatus...)
+	c.forceEncoding(encoders.Protobuf).statusOrErr(err, httpStatus...)
 }
 
 // DumpRequest dumps the request data contained in the context.
@@ -213,14 +216,7 @@ func (c *Context) DumpRequest() string {
 
 func (c *Context) sendError(err error) {
 	logx.Trace("sending error")
-	var e *errorx.Error
-
-	if v, ok := err.(*errorx.Error); ok {
-		e = v
-	} else {
-		e = errorx.Wrap(e)
-	}
-
+	e := c.wrapError(err)
 	if e == nil {
 		return
 	}
@@ -238,33 +234,56 @@ func (c *Context) sendError(err error) {
 		return
 	}
 
-	var ex *errorx.Error
-
-	if !config.Rest().Response.ErrorStackTrace {
-		// Cloning the error, sanitizing stack data before sending it to the client
-		ex = &errorx.Error{
-			Code:      e.Code,
-			Timestamp: e.Timestamp,
-			Title:     e.Title,
-			Message:   e.Message,
-		}
-		if len(ex.Inner) > 0 {
-			ex.Inner = streams.From(e.Inner).Map(func(i interface{}) interface{} {
-				x := i.(*errorx.InnerError)
-				return &errorx.InnerError{
-					Error:   x.Error,
-					Details: x.Details,
-				}
-			}).ToArray().([]*errorx.InnerError)
-		}
-	} else {
-		ex = e
+	ex := sanitizeError(e)
+	if c.hasInvalidFields(e) {
+		ex.Fields = e.Fields
 	}
 
-	c.encode(httpStatus, ex)
+	// The configured error format does not apply if a specific encoding was requested
+	if c.explicit {
+		c.encode(httpStatus, ex)
+	} else if err := errorRenderer(config.Rest().Response.ErrorFormat)(c, httpStatus, ex); err != nil {
+		logx.Error("failed to render error response, ", err.Error())
+		c.encode(httpStatus, ex)
+	}
 	c.Abort()
 }
 
+func (c *Context) wrapError(err error) *errorx.Error {
+	if v, ok := err.(*errorx.Error); ok {
+		return v
+	}
+	if errors.Is(err, context.DeadlineExceeded) && c.Err() == context.DeadlineExceeded {
+		// The request deadline set by the timeout middleware was exceeded
+		return errorx.WrapRequestTimeout(err)
+	}
+	return errorx.Wrap(err)
+}
+
+// sanitizeError clones the error without the stack data and the fields to be sent to the client, unless stack
+// traces are enabled in the configuration
+func sanitizeError(e *errorx.Error) *errorx.Error {
+	if config.Rest().Response.ErrorStackTrace {
+		return e
+	}
+	ret := &errorx.Error{
+		Code:      e.Code,
+		Timestamp: e.Timestamp,
+		Title:     e.Title,
+		Message:   e.Message,
+	}
+	if len(e.Inner) > 0 {
+		ret.Inner = streams.From(e.Inner).Map(func(i interface{}) interface{} {
+			x := i.(*errorx.InnerError)
+			return &errorx.InnerError{
+				Error:   x.Error,
+				Details: x.Details,
+			}
+		}).ToArray().([]*errorx.InnerError)
+	}
+	return ret
+}
+
 func (c *Context) sendOrErr(resp interface{}, err error, httpStatus ...int) {
 	status := http.StatusOK
 
@@ -273,7 +292,7 @@ func (c *Context) sendOrErr(resp interface{}, err error, httpStatus ...int) {
 	}
 
 	if !reflectx.IsNil(err) {
-		c.sendError(errorx.Wrap(err))
+		c.sendError(err)
 	} else if resp == nil {
 		c.Status(status)
 	} else {
@@ -301,6 +320,15 @@ func (c *Context) statusOrErr(err error, httpStatus ...int) {
 	}
 }
 
+// forceEncoding sets the encoding requested explicitly by the caller, which takes precedence over the
+// configured error format
+func (c *Context) forceEncoding(enc Encoder) *Context {
+	if c.enc == nil {
+		c.explicit = true
+	}
+	return c.setEncoding(enc)
+}
+
 func (c *Context) setEncoding(enc Encoder) *Context {
 	if c.enc == nil {
 		c.enc = enc
@@ -313,7 +341,7 @@ func (c *Context) encode(code int, obj interface{}) {
 
 	// Encoding not explicitly set, using encoding based on the configuration
 	if enc == nil {
-		if e, ok := encodingMap[config.Rest().Response.Encoding]; ok {
+		if e, ok := encoderByName(config.Rest().Response.Encoding); ok {
 			enc = e
 		} else {
 			logx.Warn("unrecognized primary encoding configured, ", config.Rest().Response.Encoding)
@@ -326,7 +354,7 @@ func (c *Context) encode(code int, obj interface{}) {
 		return
 	}
 	logx.Error("failed to encode response with primary encoding, ", err.Error())
-	if e, ok := encodingMap[config.Rest().Response.FallbackEncoding]; ok {
+	if e, ok := encoderByName(config.Rest().Response.FallbackEncoding); ok {
 		enc = e
 	} else {
 		logx.Warn("unrecognized fallback encoding configured, ", config.Rest().Response.FallbackEncoding)
@@ -0,0 +1,14 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+func TestSanitizeErrorFields(t *testing.T) {
+	fields := map[string]string{"url": "http://orders/v1", "headers": "Authorization:Bearer x"}
+	assert.Nil(t, sanitizeError(&errorx.Error{Code: http.StatusBadGateway, Fields: fields}).Fields)
+}
@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"context"
+	"time"
+)
+
+// Ctx returns the request-scoped `context.Context`, which carries the request deadline set by the timeout
+// middleware and is cancelled when the client disconnects. Pass it to downstream calls, e.g. Mongo queries
+// or HTTP requests, so they are bound to the request.
+//
+// `*Context` implements `context.Context` using the request context as well, so it can be passed directly.
+func (c *Context) Ctx() context.Context {
+	if c.Request == nil {
+		return context.Background()
+	}
+	return c.Request.Context()
+}
+
+// Deadline returns the deadline of the request context. See `Ctx`
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	return c.Ctx().Deadline()
+}
+
+// Done returns the channel closed when the request context is cancelled or its deadline is exceeded. See `Ctx`
+func (c *Context) Done() <-chan struct{} {
+	return c.Ctx().Done()
+}
+
+// Err returns the error of the request context once it is done. See `Ctx`
+func (c *Context) Err() error {
+	return c.Ctx().Err()
+}
+
+// Value returns the value associated with the key in the gin context keys, or in the request context if
+// not found.
+func (c *Context) Value(key interface{}) interface{} {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.Ctx().Value(key)
+}
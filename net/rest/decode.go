@@ -0,0 +1,192 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	tagForm = "form"
+
+	// defaultMultipartMemory is the maximum memory used to parse multipart forms if no request limit is set.
+	// The remaining parts are stored in temporary files.
+	defaultMultipartMemory = 32 << 20
+)
+
+// Decode decodes the request body into `obj` based on the request Content-Type:
+//   - `application/json` (or no Content-Type): JSON, using `protojson` if `obj` is a proto message
+//   - `application/x-protobuf`: binary Protobuf, `obj` must be a proto message
+//   - `application/x-yaml`: YAML, converted to JSON for proto messages
+//   - `application/xml`: XML
+//   - `application/x-msgpack`: MessagePack
+//   - `application/cbor`: CBOR
+//   - `application/x-www-form-urlencoded`: assigns the fields tagged with `form`
+//   - `multipart/form-data`: assigns the fields tagged with `form`, including `*multipart.FileHeader` and
+//     `[]*multipart.FileHeader` fields for the uploaded files
+//
+// Returns `errorx` 400 Bad Request if the body is empty or can't be decoded, with details per field in
+// `Fields` when available, 415 Unsupported Media Type if the Content-Type is not supported, and
+// 413 Request Entity Too Large if the body exceeds the configured `request_limit_size`. In the latter case,
+// if the limits middleware is in use the error response was already sent and the context is aborted.
+//
+// The body can be read again after decoding.
+func (c *Context) Decode(obj interface{}) error {
+	err := c.decode(obj)
+	if e, ok := err.(*errorx.Error); ok && len(e.Fields) > 0 {
+		c.addInvalidFields(e)
+	}
+	return err
+}
+
+func (c *Context) decode(obj interface{}) error {
+	data, err := c.readBody()
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return errorx.NewBadRequest("the request body is empty")
+	}
+
+	contentType, params, _ := mime.ParseMediaType(c.Request.Header.Get(HeaderContentType))
+	msg, isProto := obj.(proto.Message)
+
+	switch {
+	case contentType == "" || contentType == ContentTypeJson || strings.HasSuffix(contentType, "+json"):
+		if isProto {
+			return decodeError(protojson.Unmarshal(data, msg))
+		}
+		return decodeError(json.Unmarshal(data, obj))
+
+	case contentType == ContentTypeProto || contentType == "application/protobuf":
+		if !isProto {
+			return unsupportedMediaType("the request does not support %s", contentType)
+		}
+		return decodeError(proto.Unmarshal(data, msg))
+
+	case contentType == ContentTypeYaml || contentType == "application/yaml" || contentType == "text/yaml":
+		if !isProto {
+			return decodeError(yaml.Unmarshal(data, obj))
+		}
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return decodeError(err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return decodeError(err)
+		}
+		return decodeError(protojson.Unmarshal(data, msg))
+
+	case contentType == ContentTypeXml || contentType == "text/xml":
+		return decodeError(xml.Unmarshal(data, obj))
+
+	case contentType == ContentTypeMsgpack || contentType == "application/msgpack":
+		return decodeError(codec.NewDecoderBytes(data, MsgpackHandle).Decode(obj))
+
+	case contentType == ContentTypeCbor:
+		return decodeError(codec.NewDecoderBytes(data, CborHandle).Decode(obj))
+
+	case contentType == ContentTypeForm:
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return decodeError(err)
+		}
+		return bindForm(obj, values)
+
+	case contentType == ContentTypeMultipart:
+		if params["boundary"] == "" {
+			return errorx.NewBadRequest("missing multipart boundary")
+		}
+		maxMemory := int64(defaultMultipartMemory)
+		if limit := config.Rest().RequestLimitSize; limit > 0 {
+			maxMemory = limit
+		}
+		if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+			return decodeError(err)
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(data))
+		bindFiles(obj, c.Request.MultipartForm.File)
+		return bindForm(obj, c.Request.MultipartForm.Value)
+	}
+
+	return unsupportedMediaType("unsupported content type %s", contentType)
+}
+
+// readBody reads the request body, using the cached body if the context is rewindable, and replaces the
+// request body with a new reader so it can be read again
+func (c *Context) readBody() ([]byte, error) {
+	limit := config.Rest().RequestLimitSize
+	data := c.reqBody
+	if data == nil && c.Request.Body != nil && c.Request.Body != http.NoBody {
+		// Reading past the limit is enough to reject the body, the limits middleware may not be in use
+		var rdr io.Reader = c.Request.Body
+		if limit > 0 {
+			rdr = io.LimitReader(rdr, limit+1)
+		}
+		var err error
+		data, err = ioutil.ReadAll(rdr)
+		if c.IsAborted() {
+			// The limits middleware already sent the error response
+			return nil, errorx.NewRequestEntityTooLarge("the request body is too large")
+		}
+		if err != nil {
+			return nil, errorx.WrapBadRequest(err, "unable to read the request body")
+		}
+		_ = c.Request.Body.Close()
+	}
+
+	if limit > 0 && int64(len(data)) > limit {
+		return nil, errorx.NewRequestEntityTooLarge("the request body is too large")
+	}
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+func bindForm(obj interface{}, values url.Values) error {
+	fields := BindValues(obj, tagForm, func(name string) ([]string, bool) {
+		v, ok := values[name]
+		return v, ok
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+	ret := errorx.NewBadRequest("invalid request body")
+	ret.Fields = fields
+	return ret
+}
+
+// decodeError converts a decoding error into a 400 Bad Request, with the failing field in `Fields` if known
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	ret := errorx.WrapBadRequest(err, "invalid request body")
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		ret.Fields = map[string]string{typeErr.Field: fmt.Sprintf("expected %s", typeErr.Type.String())}
+	} else {
+		ret.Fields = map[string]string{"body": err.Error()}
+	}
+	return ret
+}
+
+func unsupportedMediaType(format string, args ...interface{}) error {
+	code := http.StatusUnsupportedMediaType
+	return errorx.New(code, http.StatusText(code), fmt.Sprintf(format, args...))
+}
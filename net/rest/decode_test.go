@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+type upload struct {
+	Name  string                `json:"name" yaml:"name" form:"name"`
+	Tags  []string              `json:"tags" yaml:"tags" form:"tag"`
+	Count int                   `json:"count" yaml:"count" form:"count"`
+	File  *multipart.FileHeader `form:"file"`
+}
+
+func TestDecodeJson(t *testing.T) {
+	c := newDecodeContext(ContentTypeJson, []byte(`{"name":"report","tags":["a","b"],"count":2}`))
+	obj := &upload{}
+	assert.NoError(t, c.Decode(obj))
+	assert.Equal(t, &upload{Name: "report", Tags: []string{"a", "b"}, Count: 2}, obj)
+
+	// The body can be read again
+	data, _ := ioutil.ReadAll(c.Request.Body)
+	assert.Equal(t, `{"name":"report","tags":["a","b"],"count":2}`, string(data))
+
+	c = newDecodeContext(ContentTypeJson, []byte(`{"count":"two"}`))
+	err := c.Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, int32(http.StatusBadRequest), err.Code)
+	assert.Equal(t, map[string]string{"count": "expected int"}, err.Fields)
+}
+
+func TestDecodeProto(t *testing.T) {
+	data, _ := proto.Marshal(&errorx.Error{Code: 409, Message: "conflict"})
+	obj := &errorx.Error{}
+	assert.NoError(t, newDecodeContext(ContentTypeProto, data).Decode(obj))
+	assert.Equal(t, "conflict", obj.Message)
+
+	obj = &errorx.Error{}
+	assert.NoError(t, newDecodeContext(ContentTypeJson, []byte(`{"code":409,"message":"from json"}`)).Decode(obj))
+	assert.Equal(t, "from json", obj.Message)
+
+	obj = &errorx.Error{}
+	assert.NoError(t, newDecodeContext(ContentTypeYaml, []byte("code: 409\nmessage: from yaml\n")).Decode(obj))
+	assert.Equal(t, "from yaml", obj.Message)
+
+	err := newDecodeContext(ContentTypeProto, data).Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, int32(http.StatusUnsupportedMediaType), err.Code)
+}
+
+func TestDecodeCodecs(t *testing.T) {
+	expected := &upload{Name: "report", Tags: []string{"a", "b"}, Count: 2}
+
+	obj := &upload{}
+	assert.NoError(t, newDecodeContext(ContentTypeXml, []byte(`<upload><Name>report</Name><Tags>a</Tags><Tags>b</Tags><Count>2</Count></upload>`)).Decode(obj))
+	assert.Equal(t, expected, obj)
+
+	for _, contentType := range []string{ContentTypeMsgpack, ContentTypeCbor} {
+		h := map[string]codec.Handle{ContentTypeMsgpack: MsgpackHandle, ContentTypeCbor: CborHandle}[contentType]
+		data, err := encodeCodec(h, expected)
+		assert.NoError(t, err)
+
+		obj = &upload{}
+		assert.NoError(t, newDecodeContext(contentType, data).Decode(obj))
+		assert.Equal(t, expected, obj)
+	}
+}
+
+func TestDecodeForms(t *testing.T) {
+	obj := &upload{}
+	assert.NoError(t, newDecodeContext(ContentTypeForm, []byte("name=report&tag=a&tag=b&count=2")).Decode(obj))
+	assert.Equal(t, &upload{Name: "report", Tags: []string{"a", "b"}, Count: 2}, obj)
+
+	err := newDecodeContext(ContentTypeForm, []byte("count=two")).Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, map[string]string{"count": "expected an integer"}, err.Fields)
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	_ = w.WriteField("name", "report")
+	fw, _ := w.CreateFormFile("file", "report.csv")
+	_, _ = fw.Write([]byte("a,b,c"))
+	_ = w.Close()
+
+	obj = &upload{}
+	assert.NoError(t, newDecodeContext(w.FormDataContentType(), body.Bytes()).Decode(obj))
+	assert.Equal(t, "report", obj.Name)
+	assert.Equal(t, "report.csv", obj.File.Filename)
+	assert.Equal(t, int64(5), obj.File.Size)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	err := newDecodeContext("text/csv", []byte(`a,b,c`)).Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, int32(http.StatusUnsupportedMediaType), err.Code)
+
+	err = newDecodeContext(ContentTypeJson, nil).Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, int32(http.StatusBadRequest), err.Code)
+
+	err = newDecodeContext(ContentTypeJson, []byte(`{"name":`)).Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, int32(http.StatusBadRequest), err.Code)
+}
+
+func TestDecodeLimit(t *testing.T) {
+	limit := config.Rest().RequestLimitSize
+	config.Rest().RequestLimitSize = 16
+	defer func() { config.Rest().RequestLimitSize = limit }()
+
+	body := bytes.NewReader(bytes.Repeat([]byte(" "), 1<<20))
+	c := newDecodeContext(ContentTypeJson, nil)
+	c.Request.Body = ioutil.NopCloser(body)
+
+	err := c.Decode(&upload{}).(*errorx.Error)
+	assert.Equal(t, int32(http.StatusRequestEntityTooLarge), err.Code)
+	assert.True(t, body.Len() > 0)
+}
+
+func newDecodeContext(contentType string, body []byte) *Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	ctx.Request.Header.Set(HeaderContentType, contentType)
+	return NewContext(ctx, false)
+}
@@ -1,22 +1,45 @@
 package rest
 
 import (
+	"bytes"
+	"encoding/xml"
+	"mime"
+	"sync"
+
 	"github.com/jucardi/go-titan/errors"
 	"github.com/jucardi/go-titan/logx"
 	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
 )
 
 type Encoder func(c *Context, code int, obj interface{}) error
 
+const headerVary = "Vary"
+
 var (
 	encoders = &Encoders{}
 
 	encodingDefault  Encoder = encoders.Auto
 	encodingFallback Encoder = encoders.Json
 
+	// contentTypes contains the registered content types in order of preference for the Accept negotiation
+	contentTypes = []string{
+		ContentTypeJson,
+		ContentTypeProto,
+		ContentTypeYaml,
+		ContentTypeXml,
+		ContentTypeMsgpack,
+		ContentTypeCbor,
+	}
+
 	contentTypeMap = map[string]Encoder{
-		ContentTypeJson:  encoders.Json,
-		ContentTypeProto: encoders.Protobuf,
+		ContentTypeJson:    encoders.Json,
+		ContentTypeProto:   encoders.Protobuf,
+		ContentTypeYaml:    encoders.Yaml,
+		ContentTypeXml:     encoders.Xml,
+		ContentTypeMsgpack: encoders.Msgpack,
+		ContentTypeCbor:    encoders.Cbor,
 	}
 
 	encodingMap = map[config.Encoding]Encoder{
@@ -24,12 +47,61 @@ var (
 		config.EncodingJson:         encoders.Json,
 		config.EncodingIndentedJson: encoders.IndentedJson,
 		config.EncodingProto:        encoders.Protobuf,
+		config.EncodingYaml:         encoders.Yaml,
+		config.EncodingXml:          encoders.Xml,
+		config.EncodingMsgpack:      encoders.Msgpack,
+		config.EncodingCbor:         encoders.Cbor,
 	}
+
+	encodersMux sync.RWMutex
 )
 
+// RegisterEncoder registers an encoder for the provided content type, used by the `auto` encoding when
+// negotiated, and optionally for the provided encoding name, which can be set in the `response.encoding`
+// configuration. Registering an existing content type or name replaces its encoder.
+func RegisterEncoder(contentType string, enc Encoder, name ...config.Encoding) {
+	encodersMux.Lock()
+	defer encodersMux.Unlock()
+	if _, ok := contentTypeMap[contentType]; !ok {
+		contentTypes = append(contentTypes, contentType)
+	}
+	contentTypeMap[contentType] = enc
+	for _, n := range name {
+		encodingMap[n] = enc
+	}
+}
+
+// EncoderFor returns the encoder registered for the provided content type
+func EncoderFor(contentType string) (Encoder, bool) {
+	encodersMux.RLock()
+	defer encodersMux.RUnlock()
+	e, ok := contentTypeMap[contentType]
+	return e, ok
+}
+
+func encoderByName(name config.Encoding) (Encoder, bool) {
+	encodersMux.RLock()
+	defer encodersMux.RUnlock()
+	e, ok := encodingMap[name]
+	return e, ok
+}
+
+func registeredContentTypes() []string {
+	encodersMux.RLock()
+	defer encodersMux.RUnlock()
+	return append([]string{}, contentTypes...)
+}
+
 type Encoders struct {
 }
 
+// Auto determines the response encoding from the request, in the following order:
+//   - The `Response-Type` header, if it matches a registered content type
+//   - The `Accept` header, negotiated using the quality values. Ranges that only match through `*/*` do
+//     not take precedence over the request `Content-Type`
+//   - The request `Content-Type` header, if it matches a registered content type
+//
+// Returns an error if the encoding can't be determined, in which case the fallback encoding is used.
 func (e Encoders) Auto(c *Context, code int, obj interface{}) error {
 	if c.aborted {
 		c.Status(code)
@@ -40,39 +112,77 @@ func (e Encoders) Auto(c *Context, code int, obj interface{}) error {
 		return errors.New("request is nil, unable to automatically determine encoding, using default encoder")
 	}
 
-	responseType := c.Request.Header.Get(HeaderResponseType)
-	contentType := c.Request.Header.Get(HeaderContentType)
+	contentType, negotiated := negotiateContentType(c)
+	if negotiated {
+		c.Writer.Header().Add(headerVary, HeaderAccept)
+	}
+	if enc, ok := EncoderFor(contentType); ok {
+		return enc(c, code, obj)
+	}
 
-	var enc Encoder
+	responseType := c.Request.Header.Get(HeaderResponseType)
+	accept := c.Request.Header.Get(HeaderAccept)
+	contentType, _, _ = mime.ParseMediaType(c.Request.Header.Get(HeaderContentType))
 
-	// If a Response-Type header was provided in the request, attempts to determine the response encoding based on the Response-Type header value
 	if responseType != "" {
-		if e, ok := contentTypeMap[responseType]; ok {
-			enc = e
-		}
+		return errors.New("unable to determine encoder based on the request Response-Type header: ", responseType)
 	}
 
-	// If no Response-Type header was provided, attempts to determine the response encoding based on the Content-Type header of the request.
-	if enc == nil {
-		if e, ok := contentTypeMap[contentType]; ok {
-			enc = e
-		}
+	if accept != "" {
+		return errors.New("unable to determine encoder based on the request Accept header: ", accept)
 	}
 
-	// If an encoding was determined from the request
-	if enc != nil {
-		return enc(c, code, obj)
+	if contentType != "" {
+		return errors.New("unable to determine encoder based on the request Content-Type header: ", contentType)
+	}
+
+	return errors.New("failed to determine auto encoding")
+}
+
+// NegotiatedContentType returns the content type the `auto` encoding uses to encode the responses to the
+// request, or an empty string if it can't be determined and the fallback encoding is used.
+func (c *Context) NegotiatedContentType() string {
+	if c.Request == nil {
+		return ""
 	}
+	ret, _ := negotiateContentType(c)
+	return ret
+}
 
+// negotiateContentType determines the response content type of the `auto` encoding. Also indicates whether
+// it was negotiated through the `Accept` header, in which case the response varies by it.
+func negotiateContentType(c *Context) (string, bool) {
+	responseType := c.Request.Header.Get(HeaderResponseType)
+	accept := c.Request.Header.Get(HeaderAccept)
+	contentType, _, _ := mime.ParseMediaType(c.Request.Header.Get(HeaderContentType))
+
+	// If a Response-Type header was provided in the request, attempts to determine the response encoding based on the Response-Type header value
 	if responseType != "" {
-		return errors.New("unable to determine encoder based on the request Response-Type header: ", responseType)
+		if _, ok := EncoderFor(responseType); ok {
+			return responseType, false
+		}
 	}
 
-	if contentType != "" {
-		return errors.New("unable to determine encoder based on the request Content-Type header: ", contentType)
+	varies := accept != "" && !acceptsAnyOnly(accept)
+	if varies {
+		offers := registeredContentTypes()
+
+		// The request content type is preferred among equally acceptable types
+		if _, ok := EncoderFor(contentType); ok {
+			offers = append([]string{contentType}, offers...)
+		}
+		if negotiated := negotiatePreferred(accept, offers...); negotiated != "" {
+			if _, ok := EncoderFor(negotiated); ok {
+				return negotiated, true
+			}
+		}
 	}
 
-	return errors.New("failed to determine auto encoding")
+	// If no Response-Type header was provided, attempts to determine the response encoding based on the Content-Type header of the request.
+	if _, ok := EncoderFor(contentType); ok {
+		return contentType, varies
+	}
+	return "", varies
 }
 
 func (Encoders) Json(c *Context, code int, obj interface{}) (_ error) {
@@ -104,3 +214,67 @@ func (Encoders) Protobuf(c *Context, code int, obj interface{}) (_ error) {
 	c.ProtoBuf(code, obj)
 	return
 }
+
+func (Encoders) Yaml(c *Context, code int, obj interface{}) error {
+	if c.aborted {
+		c.Status(code)
+		return nil
+	}
+	logx.Trace("using YAML encoder")
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	c.Data(code, ContentTypeYaml, data)
+	return nil
+}
+
+func (Encoders) Xml(c *Context, code int, obj interface{}) error {
+	if c.aborted {
+		c.Status(code)
+		return nil
+	}
+	logx.Trace("using XML encoder")
+	data, err := xml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	c.Data(code, ContentTypeXml, data)
+	return nil
+}
+
+func (Encoders) Msgpack(c *Context, code int, obj interface{}) error {
+	if c.aborted {
+		c.Status(code)
+		return nil
+	}
+	logx.Trace("using MessagePack encoder")
+	data, err := encodeCodec(MsgpackHandle, obj)
+	if err != nil {
+		return err
+	}
+	c.Data(code, ContentTypeMsgpack, data)
+	return nil
+}
+
+func (Encoders) Cbor(c *Context, code int, obj interface{}) error {
+	if c.aborted {
+		c.Status(code)
+		return nil
+	}
+	logx.Trace("using CBOR encoder")
+	data, err := encodeCodec(CborHandle, obj)
+	if err != nil {
+		return err
+	}
+	c.Data(code, ContentTypeCbor, data)
+	return nil
+}
+
+func encodeCodec(h codec.Handle, obj interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := codec.NewEncoder(buf, h).Encode(obj); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,24 @@
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/components/resilience"
+)
+
+// AddCircuits adds the `/circuits` endpoint to the given router.
+func AddCircuits(router *gin.Engine) {
+	router.GET("/circuits", getCircuits)
+}
+
+// swagger:route GET /circuits health circuits
+//
+// Returns the state of the circuit breakers and bulkheads of this service
+//
+// Responses:
+//   200: Circuits
+func getCircuits(c *gin.Context) {
+	c.IndentedJSON(200, map[string]interface{}{
+		"breakers":  resilience.Breakers(),
+		"bulkheads": resilience.Bulkheads(),
+	})
+}
@@ -0,0 +1,49 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/components/health"
+	"github.com/jucardi/go-titan/configx"
+)
+
+// AddHealth adds the `/healthz` liveness and `/readyz` readiness endpoints to the given router. If the
+// configuration is loaded from a remote URL, the `remote-config` check is registered.
+func AddHealth(router *gin.Engine) {
+	if configx.Remote().Enabled {
+		health.RegisterRemoteConfig()
+	}
+	router.GET("/healthz", getLiveness)
+	router.GET("/readyz", getReadiness)
+}
+
+// swagger:route GET /healthz health liveness
+//
+// Returns the result of the liveness checks. Answers 503 if a critical check failed
+//
+// Responses:
+//   200: Report
+//   503: Report
+func getLiveness(c *gin.Context) {
+	sendReport(c, health.Liveness(c.Request.Context()))
+}
+
+// swagger:route GET /readyz health readiness
+//
+// Returns the result of the readiness checks. Answers 503 if a critical check failed
+//
+// Responses:
+//   200: Report
+//   503: Report
+func getReadiness(c *gin.Context) {
+	sendReport(c, health.Readiness(c.Request.Context()))
+}
+
+func sendReport(c *gin.Context, report *health.Report) {
+	code := http.StatusOK
+	if report.Status == health.StatusDown {
+		code = http.StatusServiceUnavailable
+	}
+	c.IndentedJSON(code, report)
+}
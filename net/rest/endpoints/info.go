@@ -3,14 +3,37 @@ package endpoints
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jucardi/go-streams/streams"
 	"github.com/jucardi/go-titan/info"
+	"github.com/jucardi/go-titan/net/rest/openapi"
 	"github.com/jucardi/go-titan/utils/paths"
 )
 
-var global = NewInfoHandler()
+// MethodAny is the method used to register the security of routes that handle any method
+const MethodAny = openapi.MethodAny
+
+var (
+	global        = NewInfoHandler()
+	routeSecurity sync.Map
+)
+
+// SetRouteSecurity registers the security requirement of a route, listed by `/info`
+func SetRouteSecurity(method, path, security string) {
+	routeSecurity.Store(method+" "+path, security)
+}
+
+func getRouteSecurity(method, path string) (string, bool) {
+	if v, ok := routeSecurity.Load(method + " " + path); ok {
+		return v.(string), true
+	}
+	if v, ok := routeSecurity.Load(MethodAny + " " + path); ok {
+		return v.(string), true
+	}
+	return "", false
+}
 
 type InfoRouteHandler struct {
 	routers map[string]*gin.Engine
@@ -38,7 +61,11 @@ func (h *InfoRouteHandler) updateInfo() {
 			}).
 			Map(func(i interface{}) interface{} {
 				x := i.(gin.RouteInfo)
-				return fmt.Sprintf("%-7s %s", x.Method, paths.Combine(address, x.Path))
+				route := fmt.Sprintf("%-7s %s", x.Method, paths.Combine(address, x.Path))
+				if security, ok := getRouteSecurity(x.Method, x.Path); ok {
+					route += "  [secured: " + security + "]"
+				}
+				return route
 			}).
 			ToArray().([]string)
 		list = append(list, l...)
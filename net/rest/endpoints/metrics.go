@@ -5,8 +5,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/components/prometheus"
 	"github.com/jucardi/go-titan/net/rest/middleware/metrics"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // AddLogLevel adds the `/metrics` endpoint to the given router.
@@ -22,8 +22,5 @@ func AddMetrics(router *gin.Engine) {
 		resp["metrics_latency"] = latency.String()
 		context.IndentedJSON(200, resp)
 	})
-	router.GET("/metrics/prometheus", func(context *gin.Context) {
-		h := promhttp.Handler()
-		h.ServeHTTP(context.Writer, context.Request)
-	})
+	router.GET("/metrics/prometheus", gin.WrapH(prometheus.Handler()))
 }
@@ -0,0 +1,50 @@
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/info"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/rest/middleware/secure"
+	"github.com/jucardi/go-titan/net/rest/openapi"
+)
+
+// AddOpenAPI adds the `/openapi.json` endpoint to the given router, which serves the OpenAPI document
+// generated from the routes of `source`, and the `/docs` endpoint that renders it. The `/docs` page replaces
+// the Content Security Policy set by the security headers middleware with `openapi.UIContentSecurityPolicy`.
+func AddOpenAPI(router *gin.Engine, source *gin.Engine) {
+	router.GET("/openapi.json", func(c *gin.Context) {
+		getOpenAPI(c, source)
+	})
+	router.GET("/docs", getDocs)
+}
+
+// swagger:route GET /openapi.json health openapi
+//
+// Returns the OpenAPI document of this service
+//
+// Responses:
+//   200: Document
+func getOpenAPI(c *gin.Context, source *gin.Engine) {
+	doc := openapi.Generate(openapi.Info{
+		Title:   configx.Get().AppName(),
+		Version: info.Version,
+	}, source.Routes(), authx.Default())
+	c.IndentedJSON(http.StatusOK, doc)
+}
+
+// swagger:route GET /docs health docs
+//
+// Renders the OpenAPI document of this service
+//
+// Responses:
+//   200: HTML
+func getDocs(c *gin.Context) {
+	// The policy of the security headers middleware blocks the inline script and style of the page
+	if c.Writer.Header().Get(secure.HeaderContentSecurityPolicy) != "" {
+		c.Header(secure.HeaderContentSecurityPolicy, openapi.UIContentSecurityPolicy())
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.UI())
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+// ErrorRenderer writes the response of an error, already sanitized according to the response configuration
+type ErrorRenderer func(c *Context, code int, err *errorx.Error) error
+
+var (
+	errorRenderers = map[config.ErrorFormat]ErrorRenderer{
+		config.ErrorFormatAuto:    renderAuto,
+		config.ErrorFormatErrorx:  renderErrorx,
+		config.ErrorFormatProblem: renderProblem,
+	}
+	renderersMux sync.RWMutex
+)
+
+// RegisterErrorRenderer registers a renderer for error responses that can be selected by name in the
+// `response.error_format` configuration. Registering an existing name replaces its renderer.
+func RegisterErrorRenderer(format config.ErrorFormat, renderer ErrorRenderer) {
+	renderersMux.Lock()
+	defer renderersMux.Unlock()
+	errorRenderers[format] = renderer
+}
+
+func errorRenderer(format config.ErrorFormat) ErrorRenderer {
+	renderersMux.RLock()
+	defer renderersMux.RUnlock()
+	if r, ok := errorRenderers[format]; ok {
+		return r
+	}
+	logx.Warn("unrecognized error format configured, ", format)
+	return renderErrorx
+}
+
+// renderAuto renders a problem if the client prefers `application/problem+json` over the registered
+// content types, otherwise renders the error as any other response
+func renderAuto(c *Context, code int, err *errorx.Error) error {
+	accept := ""
+	if c.Request != nil {
+		accept = c.Request.Header.Get(HeaderAccept)
+	}
+	if accept == "" || acceptsAnyOnly(accept) {
+		return renderErrorx(c, code, err)
+	}
+	offers := append(registeredContentTypes(), ContentTypeProblemJson)
+	if Negotiate(accept, offers...) == ContentTypeProblemJson {
+		return renderProblem(c, code, err)
+	}
+	return renderErrorx(c, code, err)
+}
+
+func renderErrorx(c *Context, code int, err *errorx.Error) error {
+	c.encode(code, err)
+	return nil
+}
+
+func renderProblem(c *Context, code int, err *errorx.Error) error {
+	instance := ""
+	if c.Request != nil && c.Request.URL != nil {
+		instance = c.Request.URL.Path
+	}
+	p := err.ToProblem(instance)
+	p.Status = code
+	data, e := json.Marshal(p)
+	if e != nil {
+		return e
+	}
+	c.Data(code, ContentTypeProblemJson, data)
+	return nil
+}
@@ -0,0 +1,80 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/router"
+	"github.com/jucardi/go-titan/utils/testutils"
+)
+
+func prepareErrors() {
+	testutils.Prepare(router.Bare(), func(r router.IRouter) {
+		r.GET("/orders/:id", func(c *rest.Context) {
+			c.SendError(c.InvalidFields("invalid order", map[string]string{"id": "failed on 'uuid'"}))
+		})
+		r.GET("/proto/:id", func(c *rest.Context) {
+			c.SendErrorProtobuf(errorx.NewNotFound("order not found"))
+		})
+	})
+}
+
+func TestProblemNegotiation(t *testing.T) {
+	prepareErrors()
+
+	req := testutils.RequestNoBody(http.MethodGet, "/orders/o-1")
+	req.Header.Set(rest.HeaderAccept, "application/problem+json, application/json;q=0.5")
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusBadRequest, res.GetCode())
+	assert.Equal(t, rest.ContentTypeProblemJson, res.Header().Get(rest.HeaderContentType))
+
+	body := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &body))
+	assert.Equal(t, errorx.ProblemTypeDefault, body["type"])
+	assert.Equal(t, float64(http.StatusBadRequest), body["status"])
+	assert.Equal(t, "invalid order", body["detail"])
+	assert.Equal(t, "/orders/o-1", body["instance"])
+	assert.Equal(t, map[string]interface{}{"id": "failed on 'uuid'"}, body["fields"])
+
+	err := res.UnmarshalError().(*errorx.Error)
+	assert.Equal(t, int32(http.StatusBadRequest), err.Code)
+	assert.Equal(t, "invalid order", err.Message)
+	assert.Equal(t, map[string]string{"id": "failed on 'uuid'"}, err.Fields)
+
+	// Without a preference for problems, the errorx schema is used
+	req = testutils.RequestNoBody(http.MethodGet, "/orders/o-1")
+	req.Header.Set(rest.HeaderAccept, "application/json")
+	res = testutils.Serve(req)
+	assert.Equal(t, rest.ContentTypeJson+"; charset=utf-8", res.Header().Get(rest.HeaderContentType))
+	assert.Equal(t, "invalid order", res.UnmarshalError().(*errorx.Error).Message)
+
+	// Explicit encodings are not overridden
+	req = testutils.RequestNoBody(http.MethodGet, "/proto/o-1")
+	req.Header.Set(rest.HeaderAccept, rest.ContentTypeProblemJson)
+	res = testutils.Serve(req)
+	assert.Equal(t, rest.ContentTypeProto, res.Header().Get(rest.HeaderContentType))
+}
+
+func TestProblemRoundTrip(t *testing.T) {
+	e := errorx.NewConflict("duplicated order")
+	e.Inner = []*errorx.InnerError{{Error: "duplicated key", Details: "orders.id"}}
+
+	data, err := json.Marshal(e.ToProblem("/orders"))
+	assert.NoError(t, err)
+
+	p := &errorx.Problem{}
+	assert.NoError(t, json.Unmarshal(data, p))
+	assert.Equal(t, "/orders", p.Instance)
+
+	ret := p.ToError()
+	assert.Equal(t, e.Code, ret.Code)
+	assert.Equal(t, e.Title, ret.Title)
+	assert.Equal(t, e.Message, ret.Message)
+	assert.Equal(t, e.Timestamp, ret.Timestamp)
+	assert.Equal(t, "duplicated key", ret.Inner[0].Error)
+	assert.Equal(t, "orders.id", ret.Inner[0].Details)
+}
@@ -0,0 +1,36 @@
+package rest
+
+import "github.com/jucardi/go-titan/net/errorx"
+
+const invalidFieldsKey = "invalid-fields"
+
+// InvalidFields creates a 400 Bad Request error with a message per invalid field of the request in `Fields`.
+//
+// The `Fields` of an error are only sent to the client for the errors created by this function or by
+// `Decode`, which describe the request of the client. The `Fields` of any other error, e.g. the errors
+// returned by downstream services which carry the details of the outbound request, are not sent.
+func (c *Context) InvalidFields(msg string, fields map[string]string) *errorx.Error {
+	ret := errorx.NewBadRequest(msg)
+	ret.Fields = fields
+	c.addInvalidFields(ret)
+	return ret
+}
+
+func (c *Context) addInvalidFields(e *errorx.Error) {
+	errs, _ := c.Get(invalidFieldsKey)
+	list, _ := errs.([]*errorx.Error)
+	c.Set(invalidFieldsKey, append(list, e))
+}
+
+// hasInvalidFields indicates whether the `Fields` of the error describe the request of the client, see
+// `InvalidFields`
+func (c *Context) hasInvalidFields(e *errorx.Error) bool {
+	errs, _ := c.Get(invalidFieldsKey)
+	list, _ := errs.([]*errorx.Error)
+	for _, x := range list {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
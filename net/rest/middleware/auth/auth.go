@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+const (
+	HeaderWWWAuthenticate = "WWW-Authenticate"
+)
+
+// Handler is a middleware function that authenticates requests using the `auth` configuration and assigns
+// the authenticated principal to the context. Requests with invalid credentials are rejected with
+// 401 Unauthorized, as well as requests without credentials if authentication is required.
+//
+// Has no effect if no authentication method is enabled.
+func Handler(c *rest.Context) {
+	handle(c, authx.Default())
+}
+
+// New creates a middleware function that authenticates requests using the provided authenticator
+func New(a *authx.Authenticator) func(c *rest.Context) {
+	return func(c *rest.Context) {
+		handle(c, a)
+	}
+}
+
+// Required is a middleware function that rejects anonymous requests with 401 Unauthorized. Must be
+// used after the authentication middleware.
+func Required(c *rest.Context) {
+	if c.Principal() == nil {
+		reject(c, authx.Default(), errorx.NewUnauthorized("authentication required"))
+		return
+	}
+	c.Next()
+}
+
+func handle(c *rest.Context, a *authx.Authenticator) {
+	if !a.Enabled() {
+		c.Next()
+		return
+	}
+
+	p, err := a.Authenticate(c.Request)
+	if err != nil {
+		reject(c, a, err)
+		return
+	}
+	if p != nil {
+		c.SetPrincipal(p)
+	}
+	c.Next()
+}
+
+func reject(c *rest.Context, a *authx.Authenticator, err error) {
+	for _, m := range a.Methods() {
+		switch m {
+		case authx.MethodJWT:
+			c.Writer.Header().Add(HeaderWWWAuthenticate, "Bearer")
+		case authx.MethodHMAC:
+			c.Writer.Header().Add(HeaderWWWAuthenticate, authx.SchemeHMAC)
+		}
+	}
+	c.SendError(err)
+}
+
+// Authorize creates a middleware function that rejects requests whose principal does not satisfy the
+// provided requirement, evaluated by `authx.Authorizer()`. Anonymous requests are rejected with
+// 401 Unauthorized and unauthorized principals with 403 Forbidden. Must be used after the authentication
+// middleware.
+//
+// Panics if the requirement policy is not a valid expression.
+func Authorize(req authx.Requirement) func(c *rest.Context) {
+	if req.Policy != "" {
+		if _, err := authx.CompilePolicy(req.Policy); err != nil {
+			panic(err)
+		}
+	}
+	return func(c *rest.Context) {
+		p := c.Principal()
+		if p == nil {
+			reject(c, authx.Default(), errorx.NewUnauthorized("authentication required"))
+			return
+		}
+		if err := authx.Authorizer().Authorize(p, req); err != nil {
+			c.SendError(err)
+			return
+		}
+		c.Next()
+	}
+}
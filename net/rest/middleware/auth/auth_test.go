@@ -0,0 +1,80 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/middleware/auth"
+	"github.com/jucardi/go-titan/net/rest/router"
+	"github.com/jucardi/go-titan/utils/testutils"
+)
+
+var authenticator *authx.Authenticator
+
+func init() {
+	cfg := authx.DefaultConfig()
+	cfg.APIKey.Enabled = true
+	cfg.APIKey.Keys = []authx.APIKey{
+		{Key: "reader-key", Subject: "reader", Scopes: []string{"orders:read"}},
+		{Key: "admin-key", Subject: "admin", Roles: []string{"admin"}},
+	}
+	authenticator, _ = authx.New(cfg)
+
+	r := router.Bare()
+	r.Use(auth.New(authenticator))
+	testutils.Prepare(r, func(r router.IRouter) {
+		handler := func(c *rest.Context) { c.String(http.StatusOK, c.Principal().Subject) }
+		r.GET("/public", func(c *rest.Context) { c.String(http.StatusOK, "OK") })
+		r.Secured("orders:read").GET("/orders", handler)
+		r.SecuredBy(authx.Requirement{Roles: []string{"admin"}}).DELETE("/orders", handler)
+	})
+}
+
+func TestSecuredRoutes(t *testing.T) {
+	res := testutils.Serve(request(http.MethodGet, "/public", ""))
+	assert.Equal(t, http.StatusOK, res.GetCode())
+
+	res = testutils.Serve(request(http.MethodGet, "/orders", ""))
+	assert.Equal(t, http.StatusUnauthorized, res.GetCode())
+
+	res = testutils.Serve(request(http.MethodGet, "/orders", "invalid-key"))
+	assert.Equal(t, http.StatusUnauthorized, res.GetCode())
+
+	res = testutils.Serve(request(http.MethodGet, "/orders", "reader-key"))
+	assert.Equal(t, http.StatusOK, res.GetCode())
+	assert.Equal(t, "reader", res.Body.String())
+
+	res = testutils.Serve(request(http.MethodDelete, "/orders", "reader-key"))
+	assert.Equal(t, http.StatusForbidden, res.GetCode())
+
+	res = testutils.Serve(request(http.MethodDelete, "/orders", "admin-key"))
+	assert.Equal(t, http.StatusOK, res.GetCode())
+}
+
+func TestSecuredRoutesGroupAuthentication(t *testing.T) {
+	r := router.Bare()
+	group := r.Group("/api")
+	group.Before(auth.New(authenticator))
+	group.Secured("orders:read").GET("/orders", func(c *rest.Context) { c.String(http.StatusOK, c.Principal().Subject) })
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, request(http.MethodGet, "/api/orders", "reader-key"))
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "reader", res.Body.String())
+
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, request(http.MethodGet, "/api/orders", ""))
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
+
+func request(method, url, key string) *http.Request {
+	req := testutils.RequestNoBody(method, url)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	return req
+}
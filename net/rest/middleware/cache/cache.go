@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/utils/paths"
+)
+
+const (
+	HeaderETag         = "ETag"
+	HeaderIfNoneMatch  = "If-None-Match"
+	HeaderCacheControl = "Cache-Control"
+
+	// HeaderCache indicates whether a response was served from the server-side cache
+	HeaderCache = "X-Cache"
+
+	ETagStrong = "strong"
+	ETagWeak   = "weak"
+	ETagNone   = "none"
+
+	headerContentLength = "Content-Length"
+)
+
+var (
+	cfg          config.CacheConfig
+	maxEntrySize int
+	store        = newLRU(1000)
+	mux          sync.RWMutex
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		mux.Lock()
+		defer mux.Unlock()
+		cfg = config.Cache
+		maxEntrySize = config.Cache.MaxEntrySize
+		store.resize(config.Cache.MaxEntries)
+	})
+}
+
+// Handler is a middleware function that applies the cache policies of the `cache` configuration to the GET
+// and HEAD requests. The policy of the longest matching group prefix is applied, or the default policy
+// otherwise. Has no effect unless caching is enabled.
+//
+// See `New` for how the policies are applied.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	enabled, policy := cfg.Enabled, cfg.Default
+	if enabled && len(cfg.Groups) > 0 {
+		var prefixes []string
+		for k := range cfg.Groups {
+			prefixes = append(prefixes, k)
+		}
+		if match, ok := paths.MatchPrefix(c.Request.URL.Path, prefixes...); ok {
+			policy = cfg.Groups[match]
+		}
+	}
+	mux.RUnlock()
+
+	if !enabled {
+		c.Next()
+		return
+	}
+	handle(c, policy)
+}
+
+// New creates a middleware function that applies the provided cache policy regardless of the `cache`
+// configuration. Useful to apply a specific policy to a route or route group.
+//
+//   - The `ETag` of successful GET and HEAD responses is generated from the encoded body, and requests
+//     with a matching `If-None-Match` header are answered with 304 Not Modified.
+//   - The `Cache-Control` of the policy is set in successful responses, unless set by the handler.
+//   - If the policy has a TTL, successful responses are kept in a server-side LRU cache keyed by method,
+//     path, query, negotiated encoding and authenticated principal, and served with `X-Cache: HIT`. A
+//     response is only served to requests whose principal, when the cache is looked up, is the one that
+//     response was generated for. Responses of routes authenticated after this middleware are therefore
+//     not served from the cache; apply the policy to such routes with `New` after the authentication.
+//   - Successful responses to unsafe methods (POST, PUT, PATCH, DELETE) invalidate the cached responses of
+//     the request path. See `Invalidate` and `Invalidating` to invalidate other paths.
+//
+// Streamed responses are sent as they are flushed, without ETag nor server-side caching. WebSocket upgrade
+// requests are skipped.
+func New(policy config.CachePolicy) func(c *rest.Context) {
+	return func(c *rest.Context) {
+		handle(c, policy)
+	}
+}
+
+// Invalidate removes the responses of the paths starting with any of the provided prefixes from the
+// server-side cache, or all the responses if no prefix is provided.
+func Invalidate(prefixes ...string) {
+	store.invalidate(prefixes...)
+}
+
+// Invalidating creates a middleware function that invalidates the cached responses of the paths starting
+// with any of the provided prefixes when the request succeeds. Useful for routes which modify resources
+// served under other paths.
+//
+//	r.Use(cache.Invalidating("/user/v1/catalog")).POST("/products", createProduct)
+func Invalidating(prefixes ...string) func(c *rest.Context) {
+	return func(c *rest.Context) {
+		c.Next()
+		if succeeded(c.Writer.Status()) {
+			Invalidate(prefixes...)
+		}
+	}
+}
+
+func handle(c *rest.Context, policy config.CachePolicy) {
+	// Upgraded connections are hijacked, there is no response to cache
+	if c.IsWebsocket() {
+		c.Next()
+		return
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		c.Next()
+		if succeeded(c.Writer.Status()) {
+			Invalidate(c.Request.URL.Path)
+		}
+		return
+	default:
+		c.Next()
+		return
+	}
+
+	ttl := time.Duration(policy.TTL) * time.Millisecond
+	if ttl > 0 {
+		if e := store.get(keyOf(c)); e != nil {
+			serveEntry(c, e)
+			return
+		}
+	}
+
+	w := &writer{ResponseWriter: c.Writer}
+	before := w.Header().Clone()
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	if w.streaming {
+		return
+	}
+
+	status := w.Status()
+	if status != http.StatusOK {
+		if err := w.flushBuffer(); err != nil {
+			logx.Warn("failed to write response, ", err.Error())
+		}
+		return
+	}
+
+	h := w.Header()
+	body := w.buf.Bytes()
+	etag := etagOf(policy.ETag, body)
+	if etag != "" {
+		h.Set(HeaderETag, etag)
+	}
+	if policy.CacheControl != "" && h.Get(HeaderCacheControl) == "" {
+		h.Set(HeaderCacheControl, policy.CacheControl)
+	}
+
+	mux.RLock()
+	maxSize := maxEntrySize
+	mux.RUnlock()
+
+	if ttl > 0 && (maxSize <= 0 || len(body) <= maxSize) {
+		// The key is computed again, the principal may be authenticated by the handlers of the route
+		store.set(&entry{
+			key:     keyOf(c),
+			path:    c.Request.URL.Path,
+			status:  status,
+			header:  changedHeaders(before, h),
+			body:    append([]byte{}, body...),
+			etag:    h.Get(HeaderETag),
+			expires: time.Now().Add(ttl),
+		})
+		h.Set(HeaderCache, "MISS")
+	}
+
+	if notModified(c, h.Get(HeaderETag)) {
+		writeNotModified(c)
+		return
+	}
+	if err := w.flushBuffer(); err != nil {
+		logx.Warn("failed to write response, ", err.Error())
+	}
+}
+
+func serveEntry(c *rest.Context, e *entry) {
+	h := c.Writer.Header()
+	for k, v := range e.header {
+		h[k] = v
+	}
+	h.Set(HeaderCache, "HIT")
+	c.Abort()
+
+	if notModified(c, e.etag) {
+		writeNotModified(c)
+		return
+	}
+	c.Writer.WriteHeader(e.status)
+	if _, err := c.Writer.Write(e.body); err != nil {
+		logx.Warn("failed to write cached response, ", err.Error())
+	}
+}
+
+func writeNotModified(c *rest.Context) {
+	c.Writer.Header().Del(headerContentLength)
+	c.Writer.WriteHeader(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+}
+
+// keyOf returns the key of the request in the server-side cache
+func keyOf(c *rest.Context) string {
+	encoding := string(config.Rest().Response.Encoding)
+	if encoding == string(config.EncodingAuto) {
+		encoding = c.NegotiatedContentType()
+	}
+	subject := ""
+	if p := c.Principal(); p != nil {
+		subject = p.Subject
+	}
+	return c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.Query().Encode() + "|" + encoding + "|" + subject
+}
+
+func etagOf(mode string, body []byte) string {
+	if strings.EqualFold(mode, ETagNone) {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	tag := `"` + base64.RawURLEncoding.EncodeToString(sum[:18]) + `"`
+	if strings.EqualFold(mode, ETagWeak) {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// notModified indicates whether the `If-None-Match` header of the request matches the ETag using the weak
+// comparison, as required for GET and HEAD requests
+func notModified(c *rest.Context, etag string) bool {
+	header := c.GetHeader(HeaderIfNoneMatch)
+	if header == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// changedHeaders returns the headers set while handling the request, so headers set by the middleware
+// for every request, e.g. the correlation ID, are not replayed from the cache
+func changedHeaders(before, after http.Header) http.Header {
+	ret := http.Header{}
+	for k, v := range after {
+		if strings.Join(before[k], ",") != strings.Join(v, ",") {
+			ret[k] = append([]string{}, v...)
+		}
+	}
+	ret.Del(headerContentLength)
+	return ret
+}
+
+func succeeded(status int) bool {
+	return status >= 200 && status < 300
+}
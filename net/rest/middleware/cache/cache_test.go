@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	testUri = "/v1/products"
+)
+
+func TestHandlerConditionalRequests(t *testing.T) {
+	router := createRouter(config.CachePolicy{CacheControl: "public, max-age=60"}, nil)
+
+	res := serve(router, http.MethodGet, testUri, nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "products ", res.Body.String())
+	assert.Equal(t, "public, max-age=60", res.Header().Get(HeaderCacheControl))
+	etag := res.Header().Get(HeaderETag)
+	assert.True(t, strings.HasPrefix(etag, `"`))
+
+	res = serve(router, http.MethodGet, testUri, map[string]string{HeaderIfNoneMatch: `"other", W/` + etag})
+	assert.Equal(t, http.StatusNotModified, res.Code)
+	assert.Equal(t, "", res.Body.String())
+	assert.Equal(t, etag, res.Header().Get(HeaderETag))
+
+	router = createRouter(config.CachePolicy{ETag: ETagWeak}, nil)
+	res = serve(router, http.MethodGet, testUri, nil)
+	assert.Equal(t, "W/"+etag, res.Header().Get(HeaderETag))
+}
+
+func TestHandlerServerSideCache(t *testing.T) {
+	var calls int32
+	router := createRouter(config.CachePolicy{TTL: 60000}, &calls)
+
+	res := serve(router, http.MethodGet, testUri+"?page=1", nil)
+	assert.Equal(t, "MISS", res.Header().Get(HeaderCache))
+
+	res = serve(router, http.MethodGet, testUri+"?page=1", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "HIT", res.Header().Get(HeaderCache))
+	assert.Equal(t, "products 1", res.Body.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.NotEmpty(t, res.Header().Get(HeaderETag))
+
+	serve(router, http.MethodGet, testUri+"?page=2", nil)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// A successful unsafe request invalidates the cached responses of the path
+	serve(router, http.MethodPost, testUri, nil)
+	res = serve(router, http.MethodGet, testUri+"?page=1", nil)
+	assert.Equal(t, "MISS", res.Header().Get(HeaderCache))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	Invalidate()
+	serve(router, http.MethodGet, testUri+"?page=1", nil)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func TestHandlerServerSideCacheAuthenticatedRoute(t *testing.T) {
+	var calls int32
+	router := createRouter(config.CachePolicy{TTL: 60000}, &calls)
+	router.GET("/v1/orders", func(c *gin.Context) {
+		ctx := rest.NewContext(c, false)
+		if user := c.GetHeader("X-User"); user != "" {
+			ctx.SetPrincipal(&authx.Principal{Subject: user})
+		} else {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		c.String(http.StatusOK, "orders of %s", ctx.Principal().Subject)
+	})
+
+	res := serve(router, http.MethodGet, "/v1/orders", map[string]string{"X-User": "alice"})
+	assert.Equal(t, "orders of alice", res.Body.String())
+	assert.Equal(t, "MISS", res.Header().Get(HeaderCache))
+
+	// The response is cached for the principal authenticated by the route, not for anonymous requests
+	res = serve(router, http.MethodGet, "/v1/orders", nil)
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Equal(t, "", res.Body.String())
+
+	res = serve(router, http.MethodGet, "/v1/orders", map[string]string{"X-User": "bob"})
+	assert.Equal(t, "orders of bob", res.Body.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLRUEviction(t *testing.T) {
+	l := newLRU(2)
+	l.set(&entry{key: "a", path: "/a", expires: l.now().Add(time.Minute)})
+	l.set(&entry{key: "b", path: "/b", expires: l.now().Add(time.Minute)})
+	assert.NotNil(t, l.get("a"))
+
+	l.set(&entry{key: "c", path: "/c", expires: l.now().Add(time.Minute)})
+	assert.Nil(t, l.get("b"))
+	assert.NotNil(t, l.get("a"))
+	assert.NotNil(t, l.get("c"))
+}
+
+func serve(router *gin.Engine, method, uri string, headers map[string]string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, uri, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func createRouter(policy config.CachePolicy, calls *int32) *gin.Engine {
+	if calls == nil {
+		calls = new(int32)
+	}
+	store = newLRU(10)
+	mw := New(policy)
+	router := gin.New()
+	router.Use(func(context *gin.Context) {
+		mw(rest.NewContext(context, false))
+	})
+	router.GET(testUri, func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		c.String(http.StatusOK, "products %s", c.Query("page"))
+	})
+	router.POST(testUri, func(c *gin.Context) {
+		atomic.AddInt32(calls, 1)
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
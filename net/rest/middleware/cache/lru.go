@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entry is a cached response
+type entry struct {
+	key     string
+	path    string
+	status  int
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// lru is a least recently used cache of responses
+type lru struct {
+	entries map[string]*list.Element
+	order   *list.List
+	max     int
+	mux     sync.Mutex
+	now     func() time.Time
+}
+
+func newLRU(max int) *lru {
+	return &lru{
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		max:     max,
+		now:     time.Now,
+	}
+}
+
+func (l *lru) get(key string) *entry {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	el, ok := l.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*entry)
+	if !l.now().Before(e.expires) {
+		l.remove(el)
+		return nil
+	}
+	l.order.MoveToFront(el)
+	return e
+}
+
+func (l *lru) set(e *entry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if el, ok := l.entries[e.key]; ok {
+		el.Value = e
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[e.key] = l.order.PushFront(e)
+	for l.max > 0 && l.order.Len() > l.max {
+		l.remove(l.order.Back())
+	}
+}
+
+// invalidate removes the entries whose path starts with any of the provided prefixes, or all the entries
+// if no prefix is provided
+func (l *lru) invalidate(prefixes ...string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for el := l.order.Front(); el != nil; {
+		next := el.Next()
+		if len(prefixes) == 0 || hasPrefix(el.Value.(*entry).path, prefixes) {
+			l.remove(el)
+		}
+		el = next
+	}
+}
+
+func (l *lru) resize(max int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.max = max
+	for l.max > 0 && l.order.Len() > l.max {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *lru) remove(el *list.Element) {
+	l.order.Remove(el)
+	delete(l.entries, el.Value.(*entry).key)
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writer buffers the response to generate the ETag from the whole body. Flushing the response, e.g. when
+// streaming, writes the buffered data and disables the buffering.
+type writer struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	streaming bool
+}
+
+func (w *writer) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *writer) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *writer) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	return w.buf.Len()
+}
+
+func (w *writer) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		_ = w.flushBuffer()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *writer) flushBuffer() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
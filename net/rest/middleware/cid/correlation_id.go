@@ -83,3 +83,24 @@ func processName() string {
 	}
 	return curProcessName
 }
+
+// Propagate sets the correlation identifier and the correlation trace of the current request scope
+// into the provided headers, so they can be forwarded to downstream services.
+func Propagate(c *rest.Context, h http.Header) {
+	correlationId, exists := c.Get(correlationIdStore)
+	if !exists {
+		correlationId = getCorrelationId(c)
+		c.Set(correlationIdStore, correlationId)
+	}
+	h.Set(HeaderCorrelationId, correlationId.(string))
+	h.Set(HeaderCorrelationTrace, GetTrace(c))
+}
+
+// GetTrace returns the current correlation trace if it was set during the current request scope,
+// otherwise it returns the trace from the request headers with this application appended.
+func GetTrace(c *rest.Context) string {
+	if trace, exists := c.Get(correlationTraceStore); exists {
+		return trace.(string)
+	}
+	return addTrace(c)
+}
@@ -0,0 +1,239 @@
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/encoding"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	HeaderVary = "Vary"
+
+	identity = "identity"
+)
+
+var (
+	defaultEncodings = []string{
+		string(encoding.Zstd),
+		string(encoding.Gzip),
+		string(encoding.Deflate),
+	}
+
+	defaultContentTypes = []string{
+		rest.ContentTypeJson,
+		"application/*+json",
+		rest.ContentTypeYaml,
+		"application/yaml",
+		rest.ContentTypeXml,
+		"application/*+xml",
+		rest.ContentTypeProto,
+		rest.ContentTypeMsgpack,
+		rest.ContentTypeCbor,
+		rest.ContentType,
+		"text/*",
+	}
+
+	current = newCompressor(config.CompressionConfig{})
+	mux     sync.RWMutex
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		c := newCompressor(config.Compression)
+		mux.Lock()
+		defer mux.Unlock()
+		current = c
+	})
+}
+
+// Handler is a middleware function that compresses the responses using the coding negotiated from the
+// `Accept-Encoding` request header, and decompresses the request bodies sent with a `Content-Encoding`.
+// Response compression has no effect unless enabled in the `compression` configuration.
+//
+// Only responses of an allowed content type and of at least the configured minimum size are compressed.
+// Decompressed request bodies are still limited to the configured `request_limit_size`. Requests with an
+// unsupported coding are rejected with (415) Unsupported Media Type.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	x := current
+	mux.RUnlock()
+	x.handle(c)
+}
+
+// New creates a middleware function that compresses the responses using the provided configuration,
+// regardless of the `compression` configuration and of its `Enabled` flag.
+func New(cfg config.CompressionConfig) func(c *rest.Context) {
+	cfg.Enabled = true
+	return newCompressor(cfg).handle
+}
+
+type compressor struct {
+	enabled      bool
+	decompress   bool
+	minSize      int
+	encodings    []string
+	contentTypes []string
+	pools        map[string]*sync.Pool
+}
+
+func newCompressor(cfg config.CompressionConfig) *compressor {
+	ret := &compressor{
+		enabled:      cfg.Enabled,
+		decompress:   !cfg.DisableDecompression,
+		minSize:      cfg.MinSize,
+		encodings:    cfg.Encodings,
+		contentTypes: cfg.ContentTypes,
+		pools:        map[string]*sync.Pool{},
+	}
+	if len(ret.encodings) == 0 {
+		ret.encodings = defaultEncodings
+	}
+	if len(ret.contentTypes) == 0 {
+		ret.contentTypes = defaultContentTypes
+	}
+
+	var supported []string
+	for _, name := range ret.encodings {
+		name = strings.ToLower(name)
+		pool := newPool(name, cfg.Level)
+		if pool == nil {
+			logx.Warnf("unsupported compression coding '%s', ignoring", name)
+			continue
+		}
+		ret.pools[name] = pool
+		supported = append(supported, name)
+	}
+	ret.encodings = supported
+	return ret
+}
+
+func (x *compressor) handle(c *rest.Context) {
+	if x.decompress && !decompressRequest(c) {
+		return
+	}
+	if !x.enabled || c.Request.Method == http.MethodHead || c.IsWebsocket() {
+		c.Next()
+		return
+	}
+
+	c.Writer.Header().Add(HeaderVary, rest.HeaderAcceptEncoding)
+	coding := negotiate(c.GetHeader(rest.HeaderAcceptEncoding), x.encodings)
+	if coding == "" {
+		c.Next()
+		return
+	}
+
+	w := &writer{ResponseWriter: c.Writer, compressor: x, coding: coding}
+	c.Writer = w
+	defer func() {
+		c.Writer = w.ResponseWriter
+		if err := w.close(); err != nil {
+			logx.Warn("failed to write compressed response, ", err.Error())
+		}
+	}()
+	c.Next()
+}
+
+// allowed indicates whether responses of the provided content type should be compressed
+func (x *compressor) allowed(contentType string) bool {
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	if contentType == "" {
+		return false
+	}
+	for _, pattern := range x.contentTypes {
+		if ok, _ := path.Match(pattern, contentType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newPool(coding string, level int) *sync.Pool {
+	var create func() encoder
+	switch coding {
+	case string(encoding.Gzip):
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		create = func() encoder {
+			w, _ := gzip.NewWriterLevel(nil, level)
+			return w
+		}
+	case string(encoding.Deflate):
+		if level == 0 {
+			level = zlib.DefaultCompression
+		}
+		create = func() encoder {
+			w, _ := zlib.NewWriterLevel(nil, level)
+			return w
+		}
+	case string(encoding.Zstd):
+		zstdLevel := zstd.SpeedDefault
+		if level > 0 {
+			zstdLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		create = func() encoder {
+			w, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstdLevel), zstd.WithEncoderConcurrency(1))
+			return w
+		}
+	default:
+		return nil
+	}
+	return &sync.Pool{New: func() interface{} { return create() }}
+}
+
+// negotiate returns the offered coding with the highest quality value in the `Accept-Encoding` header,
+// using the order of the offers to break ties. Returns empty if the header is missing or no offered
+// coding is acceptable, in which case the response is not compressed.
+func negotiate(header string, offers []string) string {
+	if strings.TrimSpace(header) == "" {
+		return ""
+	}
+
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		if name == "" {
+			continue
+		}
+		if name == "x-gzip" {
+			name = string(encoding.Gzip)
+		}
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil && v >= 0 && v <= 1 {
+					q = v
+				}
+			}
+		}
+		accepted[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, ok := accepted[offer]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
@@ -0,0 +1,124 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/configx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/net/rest/middleware/compress"
+	"github.com/jucardi/go-titan/net/rest/router"
+	"github.com/jucardi/go-titan/utils/testutils"
+	"github.com/klauspost/compress/zstd"
+)
+
+var large = strings.Repeat("compressible ", 200)
+
+func prepare() {
+	r := router.Bare()
+	r.Use(compress.New(config.CompressionConfig{MinSize: 1024}))
+	testutils.Prepare(r, func(r router.IRouter) {
+		r.GET("/large", func(c *rest.Context) { c.String(http.StatusOK, large) })
+		r.GET("/small", func(c *rest.Context) { c.String(http.StatusOK, "small") })
+		r.GET("/binary", func(c *rest.Context) { c.Data(http.StatusOK, "image/png", []byte(large)) })
+		r.POST("/echo", func(c *rest.Context) {
+			data, _ := ioutil.ReadAll(c.Request.Body)
+			c.String(http.StatusOK, string(data))
+		})
+	})
+}
+
+func TestCompressResponse(t *testing.T) {
+	prepare()
+
+	req := testutils.RequestNoBody(http.MethodGet, "/large")
+	req.Header.Set(rest.HeaderAcceptEncoding, "gzip;q=0.8, deflate;q=0.5")
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusOK, res.GetCode())
+	assert.Equal(t, "gzip", res.Header().Get(rest.HeaderContentEncoding))
+	assert.Equal(t, rest.HeaderAcceptEncoding, res.Header().Get(compress.HeaderVary))
+
+	gz, err := gzip.NewReader(res.Body)
+	assert.NoError(t, err)
+	data, _ := ioutil.ReadAll(gz)
+	assert.Equal(t, large, string(data))
+
+	req = testutils.RequestNoBody(http.MethodGet, "/large")
+	req.Header.Set(rest.HeaderAcceptEncoding, "gzip, zstd")
+	res = testutils.Serve(req)
+	assert.Equal(t, "zstd", res.Header().Get(rest.HeaderContentEncoding))
+
+	dec, _ := zstd.NewReader(res.Body)
+	data, _ = ioutil.ReadAll(dec)
+	assert.Equal(t, large, string(data))
+}
+
+func TestSkipCompression(t *testing.T) {
+	prepare()
+
+	for _, tc := range []struct{ path, accept string }{
+		{"/small", "gzip"},
+		{"/binary", "gzip"},
+		{"/large", ""},
+		{"/large", "br, gzip;q=0"},
+	} {
+		req := testutils.RequestNoBody(http.MethodGet, tc.path)
+		req.Header.Set(rest.HeaderAcceptEncoding, tc.accept)
+		res := testutils.Serve(req)
+		assert.Equal(t, http.StatusOK, res.GetCode())
+		assert.Equal(t, "", res.Header().Get(rest.HeaderContentEncoding))
+	}
+}
+
+func TestDecompressRequest(t *testing.T) {
+	prepare()
+
+	body := &bytes.Buffer{}
+	gz := gzip.NewWriter(body)
+	_, _ = gz.Write([]byte(`{"name":"report"}`))
+	_ = gz.Close()
+
+	req := testutils.RequestFromBytes(http.MethodPost, "/echo", body.Bytes())
+	req.Header.Set(rest.HeaderContentEncoding, "gzip")
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusOK, res.GetCode())
+	assert.Equal(t, `{"name":"report"}`, res.Body.String())
+
+	req = testutils.RequestFromBytes(http.MethodPost, "/echo", []byte("not gzip"))
+	req.Header.Set(rest.HeaderContentEncoding, "gzip")
+	assert.Equal(t, http.StatusBadRequest, testutils.Serve(req).GetCode())
+
+	req = testutils.RequestFromBytes(http.MethodPost, "/echo", []byte("data"))
+	req.Header.Set(rest.HeaderContentEncoding, "br")
+	assert.Equal(t, http.StatusUnsupportedMediaType, testutils.Serve(req).GetCode())
+}
+
+func TestDecompressRequestLimit(t *testing.T) {
+	loadConfig(t, "rest:\n  request_limit_size: 1024\n")
+	defer loadConfig(t, "rest:\n  request_limit_size: 5242880\n")
+	prepare()
+
+	// The compressed body is under the limit, but not once decompressed
+	body := &bytes.Buffer{}
+	gz := gzip.NewWriter(body)
+	_, _ = gz.Write([]byte(strings.Repeat("a", 4096)))
+	_ = gz.Close()
+	assert.True(t, body.Len() < 1024)
+
+	req := testutils.RequestFromBytes(http.MethodPost, "/echo", body.Bytes())
+	req.Header.Set(rest.HeaderContentEncoding, "gzip")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, testutils.Serve(req).GetCode())
+}
+
+func loadConfig(t *testing.T, data string) {
+	file := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(data), 0600))
+	assert.NoError(t, configx.FromFile(file))
+}
@@ -0,0 +1,74 @@
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/jucardi/go-titan/net/encoding"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/middleware/limits"
+	"github.com/klauspost/compress/zstd"
+)
+
+var decoders = map[string]func(r io.Reader) (io.ReadCloser, error){
+	string(encoding.Gzip): func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	"x-gzip": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	string(encoding.Deflate): zlib.NewReader,
+	string(encoding.Zstd): func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// body closes both the decompressing reader and the original request body
+type body struct {
+	io.ReadCloser
+	orig io.Closer
+}
+
+func (b *body) Close() error {
+	_ = b.ReadCloser.Close()
+	return b.orig.Close()
+}
+
+// decompressRequest replaces the body of requests sent with a `Content-Encoding` with a decompressing
+// reader limited to the configured request size. Returns false if the request was aborted.
+func decompressRequest(c *rest.Context) bool {
+	coding := strings.ToLower(strings.TrimSpace(c.GetHeader(rest.HeaderContentEncoding)))
+	if coding == "" || coding == identity || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return true
+	}
+
+	decode, ok := decoders[coding]
+	if !ok {
+		code := http.StatusUnsupportedMediaType
+		c.Header(rest.HeaderAcceptEncoding, strings.Join(defaultEncodings, ", "))
+		c.AbortWithError(code, errorx.New(code, http.StatusText(code), "unsupported content encoding "+coding))
+		return false
+	}
+
+	r, err := decode(c.Request.Body)
+	if err != nil {
+		if !c.IsAborted() {
+			c.AbortWithError(http.StatusBadRequest, errorx.WrapBadRequest(err, "unable to decompress the request body"))
+		}
+		return false
+	}
+
+	c.Request.Body = limits.Reader(c, &body{ReadCloser: r, orig: c.Request.Body})
+	c.Request.Header.Del(rest.HeaderContentEncoding)
+	c.Request.Header.Del(headerContentLength)
+	c.Request.ContentLength = -1
+	return true
+}
@@ -0,0 +1,132 @@
+package compress
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+const (
+	headerContentLength = "Content-Length"
+	headerETag          = "ETag"
+)
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// writer buffers the response until the minimum size is reached to decide whether it should be compressed
+type writer struct {
+	gin.ResponseWriter
+	*compressor
+	coding  string
+	buf     []byte
+	enc     encoder
+	started bool
+}
+
+func (w *writer) Write(data []byte) (int, error) {
+	if w.started {
+		if w.enc != nil {
+			return w.enc.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *writer) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush compresses the response regardless of the minimum size, since streamed responses are flushed as
+// they are written
+func (w *writer) Flush() {
+	if !w.started {
+		_ = w.start(true)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start decides whether the response is compressed and writes the buffered data
+func (w *writer) start(compress bool) error {
+	w.started = true
+	h := w.Header()
+
+	if compress &&
+		!w.ResponseWriter.Written() &&
+		h.Get(rest.HeaderContentEncoding) == "" &&
+		bodyAllowed(w.Status()) &&
+		w.allowed(h.Get(rest.HeaderContentType)) {
+		h.Set(rest.HeaderContentEncoding, w.coding)
+		h.Del(headerContentLength)
+
+		// The compressed representation is not byte-for-byte identical to the one the ETag was generated from
+		if etag := h.Get(headerETag); strings.HasPrefix(etag, `"`) {
+			h.Set(headerETag, "W/"+etag)
+		}
+		w.enc = w.pools[w.coding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close writes the pending data, without compressing it if the response is smaller than the minimum size
+func (w *writer) close() error {
+	if !w.started {
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+	if w.enc == nil {
+		return nil
+	}
+	err := w.enc.Close()
+	w.enc.Reset(nil)
+	w.pools[w.coding].Put(w.enc)
+	w.enc = nil
+	return err
+}
+
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
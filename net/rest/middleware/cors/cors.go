@@ -0,0 +1,210 @@
+package cors
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/net/rest/middleware/cid"
+)
+
+const (
+	HeaderOrigin           = "Origin"
+	HeaderVary             = "Vary"
+	HeaderAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderMaxAge           = "Access-Control-Max-Age"
+	HeaderRequestMethod    = "Access-Control-Request-Method"
+	HeaderRequestHeaders   = "Access-Control-Request-Headers"
+	wildcard               = "*"
+	correlationHeaders     = "X-CID, X-CID-Trace"
+)
+
+var (
+	defaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPost, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	defaultHeaders = []string{rest.HeaderContentType, rest.HeaderAuthorization, "X-Requested-By", rest.HeaderResponseType, cid.HeaderCorrelationId, cid.HeaderCorrelationTrace}
+	defaultExposed = []string{cid.HeaderCorrelationId, cid.HeaderCorrelationTrace}
+
+	current = newPolicy(config.CorsConfig{})
+	mux     sync.RWMutex
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		p := newPolicy(config.Cors)
+		mux.Lock()
+		defer mux.Unlock()
+		current = p
+	})
+}
+
+// Handler is a middleware function that handles Cross-Origin Resource Sharing using the `cors`
+// configuration.
+//
+//   - Requests without an `Origin` header are not affected.
+//   - Requests from origins which are not allowed continue without CORS headers, so browsers block
+//     the response.
+//   - Preflight requests (OPTIONS with `Access-Control-Request-Method`) are answered with (204) No Content
+//     and do not reach the route handlers.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	p := current
+	mux.RUnlock()
+	p.handle(c)
+}
+
+// New creates a middleware function that handles CORS using the provided configuration regardless of the
+// `cors` configuration. Useful to apply a specific policy to a route group.
+func New(cfg config.CorsConfig) func(c *rest.Context) {
+	return newPolicy(cfg).handle
+}
+
+type policy struct {
+	disabled         bool
+	allowAllOrigins  bool
+	origins          map[string]bool
+	patterns         []string
+	methods          map[string]bool
+	allowAllHeaders  bool
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+func newPolicy(cfg config.CorsConfig) *policy {
+	ret := &policy{
+		disabled:         cfg.Disabled,
+		origins:          map[string]bool{},
+		methods:          map[string]bool{},
+		allowCredentials: cfg.AllowCredentials,
+	}
+
+	origins := cfg.AllowedOrigins
+	if len(origins) == 0 {
+		origins = []string{wildcard}
+	}
+	for _, o := range origins {
+		o = strings.ToLower(strings.TrimSuffix(o, "/"))
+		switch {
+		case o == wildcard:
+			ret.allowAllOrigins = true
+		case strings.Contains(o, wildcard):
+			ret.patterns = append(ret.patterns, o)
+		default:
+			ret.origins[o] = true
+		}
+	}
+
+	methods := orDefault(cfg.AllowedMethods, defaultMethods)
+	for _, m := range methods {
+		ret.methods[strings.ToUpper(m)] = true
+	}
+	ret.allowMethods = strings.ToUpper(strings.Join(methods, ", "))
+
+	headers := orDefault(cfg.AllowedHeaders, defaultHeaders)
+	for _, h := range headers {
+		if h == wildcard {
+			ret.allowAllHeaders = true
+		}
+	}
+	ret.allowHeaders = strings.Join(headers, ", ")
+	ret.exposeHeaders = strings.Join(orDefault(cfg.ExposedHeaders, defaultExposed), ", ")
+
+	if cfg.MaxAge > 0 {
+		ret.maxAge = strconv.Itoa(cfg.MaxAge)
+	}
+
+	// Reflecting any origin with credentials would let any site make authenticated requests
+	if ret.allowAllOrigins && ret.allowCredentials {
+		logx.Warn("CORS allowed origins must be listed when credentials are allowed, ignoring '*'")
+		ret.allowAllOrigins = false
+	}
+	return ret
+}
+
+func (p *policy) handle(c *rest.Context) {
+	origin := c.GetHeader(HeaderOrigin)
+	if p.disabled || origin == "" {
+		c.Next()
+		return
+	}
+
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader(HeaderRequestMethod) != ""
+	header := c.Writer.Header()
+	header.Add(HeaderVary, HeaderOrigin)
+
+	if preflight {
+		header.Add(HeaderVary, HeaderRequestMethod)
+		header.Add(HeaderVary, HeaderRequestHeaders)
+	}
+
+	if !p.isOriginAllowed(origin) || (preflight && !p.methods[strings.ToUpper(c.GetHeader(HeaderRequestMethod))]) {
+		if preflight {
+			c.AbortWithCode(http.StatusNoContent)
+			return
+		}
+		c.Next()
+		return
+	}
+
+	if p.allowAllOrigins && !p.allowCredentials {
+		header.Set(HeaderAllowOrigin, wildcard)
+	} else {
+		header.Set(HeaderAllowOrigin, origin)
+	}
+	if p.allowCredentials {
+		header.Set(HeaderAllowCredentials, "true")
+	}
+
+	if !preflight {
+		if p.exposeHeaders != "" {
+			header.Set(HeaderExposeHeaders, p.exposeHeaders)
+		}
+		c.Next()
+		return
+	}
+
+	header.Set(HeaderAllowMethods, p.allowMethods)
+	if requested := c.GetHeader(HeaderRequestHeaders); p.allowAllHeaders && requested != "" {
+		header.Set(HeaderAllowHeaders, requested)
+	} else if p.allowHeaders != "" {
+		header.Set(HeaderAllowHeaders, p.allowHeaders)
+	}
+	if p.maxAge != "" {
+		header.Set(HeaderMaxAge, p.maxAge)
+	}
+	c.AbortWithCode(http.StatusNoContent)
+}
+
+func (p *policy) isOriginAllowed(origin string) bool {
+	if p.allowAllOrigins {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if ok, _ := path.Match(pattern, origin); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func orDefault(values, def []string) []string {
+	if len(values) == 0 {
+		return def
+	}
+	return values
+}
@@ -0,0 +1,87 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	testUri = "/test"
+)
+
+func TestWildcardOrigin(t *testing.T) {
+	res := serve(config.CorsConfig{}, http.MethodGet, "https://app.example.com")
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "*", res.Header().Get(HeaderAllowOrigin))
+	assert.Equal(t, "", res.Header().Get(HeaderAllowCredentials))
+}
+
+func TestReflectedOriginWithCredentials(t *testing.T) {
+	cfg := config.CorsConfig{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowCredentials: true,
+	}
+
+	res := serve(cfg, http.MethodGet, "https://app.example.com")
+	assert.Equal(t, "https://app.example.com", res.Header().Get(HeaderAllowOrigin))
+	assert.Equal(t, "true", res.Header().Get(HeaderAllowCredentials))
+
+	res = serve(cfg, http.MethodGet, "https://example.org")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "", res.Header().Get(HeaderAllowOrigin))
+
+	// Any origin is not allowed with credentials
+	for _, origins := range [][]string{nil, {"*", "https://app.example.com"}} {
+		cfg = config.CorsConfig{AllowedOrigins: origins, AllowCredentials: true}
+		res = serve(cfg, http.MethodGet, "https://evil.example.org")
+		assert.Equal(t, "", res.Header().Get(HeaderAllowOrigin))
+		assert.Equal(t, "", res.Header().Get(HeaderAllowCredentials))
+	}
+	res = serve(cfg, http.MethodGet, "https://app.example.com")
+	assert.Equal(t, "https://app.example.com", res.Header().Get(HeaderAllowOrigin))
+}
+
+func TestPreflight(t *testing.T) {
+	cfg := config.CorsConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		MaxAge:         300,
+	}
+
+	res := serve(cfg, http.MethodOptions, "https://app.example.com", http.MethodPost)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "https://app.example.com", res.Header().Get(HeaderAllowOrigin))
+	assert.Equal(t, "GET, POST", res.Header().Get(HeaderAllowMethods))
+	assert.Equal(t, "300", res.Header().Get(HeaderMaxAge))
+
+	res = serve(cfg, http.MethodOptions, "https://app.example.com", http.MethodDelete)
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "", res.Header().Get(HeaderAllowOrigin))
+}
+
+func serve(cfg config.CorsConfig, method, origin string, requestMethod ...string) *httptest.ResponseRecorder {
+	handler := New(cfg)
+	router := gin.New()
+	router.Use(func(context *gin.Context) {
+		handler(rest.NewContext(context, false))
+	})
+	router.Handle(method, testUri, func(c *gin.Context) {
+		c.String(200, "OK")
+	})
+
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, testUri, nil)
+	req.Header.Set(HeaderOrigin, origin)
+	if len(requestMethod) > 0 {
+		req.Header.Set(HeaderRequestMethod, requestMethod[0])
+	}
+	router.ServeHTTP(res, req)
+	return res
+}
@@ -2,13 +2,13 @@ package headers
 
 import (
 	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/middleware/cors"
 )
 
-// Handler allows cross-origin requests to ease development
+// Handler allows cross-origin requests using the `cors` configuration.
+//
+// Deprecated: Handler exists for historical compatibility and should not be used. Use `cors.Handler` instead
+//
 func Handler(c *rest.Context) {
-	c.Header("Access-Control-Allow-Origin", "*")
-	c.Header("Access-Control-Allow-Credentials", "true")
-	c.Header("Access-Control-Allow-Headers", "Content-Type, x-requested-by, *")
-	c.Header("Access-Control-Allow-Methods", "GET, PUT, POST, PATCH, DELETE, OPTIONS")
-	c.Next()
+	cors.Handler(c)
 }
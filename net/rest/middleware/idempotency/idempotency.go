@@ -0,0 +1,247 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	// HeaderIdempotentReplayed is set in the responses replayed from a previous request
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+var (
+	cfg   config.IdempotencyConfig
+	store = NewMemoryStore()
+	mux   sync.RWMutex
+
+	defaultMethods = []string{http.MethodPost, http.MethodPatch}
+
+	// Headers which describe the encoding of the response as it was written, not the captured body
+	skippedHeaders = []string{"Content-Encoding", "Content-Length", "Transfer-Encoding"}
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		mux.Lock()
+		defer mux.Unlock()
+		cfg = config.Idempotency
+	})
+}
+
+// SetStore replaces the store that keeps the idempotency records. Use it to deduplicate requests across
+// multiple instances, e.g. with `NewMongoStore`.
+func SetStore(s IStore) {
+	mux.Lock()
+	defer mux.Unlock()
+	store = s
+}
+
+// Handler is a middleware function that deduplicates requests with an idempotency key using the
+// `idempotency` configuration. Has no effect unless idempotency is enabled.
+//
+// The first request with a key reserves it and its response is stored, to be replayed to the retries of
+// that request with the `Idempotent-Replayed` header. Keys are scoped to the authenticated principal, so
+// it must be used after the authentication middleware.
+//
+//   - Retries received while the first request is in progress are rejected with 409 Conflict.
+//   - Reusing a key with a different method, path, query or body is rejected with 422 Unprocessable Entity.
+//   - Server errors (5xx) and timeouts (408) are not stored, so the request can be retried.
+//
+// If the store is unavailable the request is processed without deduplication.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	x := cfg
+	mux.RUnlock()
+
+	if !x.Enabled {
+		c.Next()
+		return
+	}
+	handle(c, x)
+}
+
+// New creates a middleware function that deduplicates requests using the provided configuration regardless
+// of the `idempotency` configuration. Useful to apply idempotency keys to a specific route group.
+func New(cfg config.IdempotencyConfig) func(c *rest.Context) {
+	return func(c *rest.Context) {
+		handle(c, cfg)
+	}
+}
+
+func handle(c *rest.Context, cfg config.IdempotencyConfig) {
+	if !applies(c.Request.Method, cfg.Methods) {
+		c.Next()
+		return
+	}
+
+	header := cfg.Header
+	if header == "" {
+		header = "Idempotency-Key"
+	}
+	key := c.GetHeader(header)
+	if key == "" {
+		if cfg.Required {
+			c.SendError(errorx.NewBadRequest("the " + header + " header is required"))
+			return
+		}
+		c.Next()
+		return
+	}
+	if len(key) > maxKeyLength {
+		c.SendError(errorx.NewBadRequest("the " + header + " header is too long"))
+		return
+	}
+
+	fingerprint, err := fingerprintOf(c)
+	if err != nil {
+		if !c.IsAborted() {
+			c.SendError(errorx.NewBadRequest("failed to read the request body", err))
+		}
+		return
+	}
+
+	mux.RLock()
+	s := store
+	mux.RUnlock()
+
+	if p := c.Principal(); p != nil {
+		key = p.Subject + "|" + key
+	}
+
+	record, err := s.Reserve(c, key, fingerprint, millis(cfg.LockTimeout, time.Minute))
+	if err != nil {
+		// Failing open, an unavailable store should not take the service down
+		logx.Warn("idempotency store failed, skipping deduplication, ", err.Error())
+		c.Next()
+		return
+	}
+
+	switch {
+	case record == nil:
+		process(c, s, key, millis(cfg.TTL, 24*time.Hour))
+	case record.Fingerprint != fingerprint:
+		code := http.StatusUnprocessableEntity
+		c.SendError(errorx.New(code, http.StatusText(code), "the idempotency key was used with a different request"))
+	case record.Response == nil:
+		c.SendError(errorx.NewConflict("a request with the same idempotency key is in progress"))
+	default:
+		replay(c, record.Response)
+	}
+}
+
+func process(c *rest.Context, s IStore, key string, ttl time.Duration) {
+	w := &recorder{ResponseWriter: c.Writer}
+	completed := false
+
+	// Releasing the key if the request fails or panics, so it can be retried
+	defer func() {
+		if !completed {
+			if err := s.Release(context.Background(), key); err != nil {
+				logx.Warn("failed to release idempotency key, ", err.Error())
+			}
+		}
+	}()
+
+	c.Writer = w
+	c.Next()
+	c.Writer = w.ResponseWriter
+
+	status := w.Status()
+	if status >= http.StatusInternalServerError || status == http.StatusRequestTimeout {
+		return
+	}
+
+	header := w.Header().Clone()
+	for _, h := range skippedHeaders {
+		header.Del(h)
+	}
+	resp := &Response{Status: status, Header: header, Body: w.body.Bytes()}
+	if err := s.Complete(context.Background(), key, resp, ttl); err != nil {
+		logx.Warn("failed to store idempotent response, ", err.Error())
+		return
+	}
+	completed = true
+}
+
+func replay(c *rest.Context, resp *Response) {
+	h := c.Writer.Header()
+	for k, v := range resp.Header {
+		// Headers set by the middleware for this request take precedence, e.g. the correlation ID
+		if _, ok := h[k]; !ok {
+			h[k] = v
+		}
+	}
+	h.Set(HeaderIdempotentReplayed, "true")
+	c.Writer.WriteHeader(resp.Status)
+	_, _ = c.Writer.Write(resp.Body)
+	c.Abort()
+}
+
+// fingerprintOf hashes the method, path, query and body of the request
+func fingerprintOf(c *rest.Context) (string, error) {
+	var body []byte
+	if c.Request.Body != nil {
+		data, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", err
+		}
+		body = data
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func applies(method string, methods []string) bool {
+	if len(methods) == 0 {
+		methods = defaultMethods
+	}
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func millis(v int64, def time.Duration) time.Duration {
+	if v <= 0 {
+		return def
+	}
+	return time.Duration(v) * time.Millisecond
+}
+
+// recorder captures the response body while it is written
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
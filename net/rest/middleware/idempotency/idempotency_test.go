@@ -0,0 +1,118 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	testUri = "/v1/orders"
+)
+
+func TestHandlerReplaysResponse(t *testing.T) {
+	var calls int32
+	router := createRouter(func(c *gin.Context) {
+		n := atomic.AddInt32(&calls, 1)
+		c.Header("X-Order", "o-1")
+		c.String(http.StatusCreated, "created %d", n)
+	})
+
+	res := serve(router, "key-1", `{"sku":"a"}`)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "created 1", res.Body.String())
+
+	res = serve(router, "key-1", `{"sku":"a"}`)
+	assert.Equal(t, http.StatusCreated, res.Code)
+	assert.Equal(t, "created 1", res.Body.String())
+	assert.Equal(t, "o-1", res.Header().Get("X-Order"))
+	assert.Equal(t, "true", res.Header().Get(HeaderIdempotentReplayed))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Reusing the key with a different payload
+	res = serve(router, "key-1", `{"sku":"b"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	// Requests without a key are not deduplicated
+	serve(router, "", `{"sku":"a"}`)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestHandlerInProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	router := createRouter(func(c *gin.Context) {
+		close(started)
+		<-release
+		c.String(http.StatusCreated, "created")
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve(router, "key-2", `{}`) }()
+	<-started
+
+	assert.Equal(t, http.StatusConflict, serve(router, "key-2", `{}`).Code)
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+}
+
+func TestHandlerReleasesFailedRequests(t *testing.T) {
+	var calls int32
+	router := createRouter(func(c *gin.Context) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			c.String(http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+		c.String(http.StatusCreated, "created")
+	})
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(router, "key-3", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, serve(router, "key-3", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, serve(router, "key-3", `{}`).Code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestMemoryStoreLockTimeout(t *testing.T) {
+	s := NewMemoryStore().(*memoryStore)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	r, _ := s.Reserve(context.Background(), "key", "fp", time.Second)
+	assert.Nil(t, r)
+	r, _ = s.Reserve(context.Background(), "key", "fp", time.Second)
+	assert.NotNil(t, r)
+
+	// The request did not complete before the lock elapsed
+	now = now.Add(2 * time.Second)
+	r, _ = s.Reserve(context.Background(), "key", "fp", time.Second)
+	assert.Nil(t, r)
+}
+
+func serve(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, testUri, bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func createRouter(handler gin.HandlerFunc) *gin.Engine {
+	SetStore(NewMemoryStore())
+	mw := New(config.IdempotencyConfig{Header: "Idempotency-Key", TTL: 60000, LockTimeout: 60000})
+	router := gin.New()
+	router.Use(func(context *gin.Context) {
+		mw(rest.NewContext(context, false))
+	})
+	router.POST(testUri, handler)
+	return router
+}
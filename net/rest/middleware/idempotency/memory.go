@@ -0,0 +1,86 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ IStore = (*memoryStore)(nil)
+)
+
+const cleanupInterval = time.Minute
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() IStore {
+	return &memoryStore{
+		entries: map[string]*entry{},
+		now:     time.Now,
+	}
+}
+
+type entry struct {
+	record  Record
+	expires time.Time
+}
+
+type memoryStore struct {
+	entries     map[string]*entry
+	mux         sync.Mutex
+	now         func() time.Time
+	lastCleanup time.Time
+}
+
+func (s *memoryStore) Reserve(_ context.Context, key, fingerprint string, lock time.Duration) (*Record, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+	s.cleanup(now)
+
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		ret := e.record
+		return &ret, nil
+	}
+	s.entries[key] = &entry{
+		record:  Record{Fingerprint: fingerprint},
+		expires: now.Add(lock),
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, resp *Response, ttl time.Duration) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.record.Response = resp
+		e.expires = s.now().Add(ttl)
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.record.Response == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// cleanup removes the expired entries
+func (s *memoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+	for k, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+}
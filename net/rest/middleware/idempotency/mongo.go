@@ -0,0 +1,134 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/components/mongo"
+	"github.com/jucardi/go-titan/logx"
+	"go.mongodb.org/mongo-driver/bson"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ IStore = (*mongoStore)(nil)
+)
+
+const defaultCollection = "idempotency_keys"
+
+// NewMongoStore creates a store that keeps the records in the provided collection (`idempotency_keys` if
+// empty) of the default database of a mongo connection, deduplicating requests across instances. Expired
+// records are removed by a TTL index on `expires_at`, created on first use.
+//
+//   {collection} - The collection where the records are stored.
+//   {connection} - (Optional) The name of the mongo connection, see `mongo.Get`. The connection is
+//                  resolved on every operation, so it may be dialed after the store is created.
+//
+func NewMongoStore(collection string, connection ...string) IStore {
+	if collection == "" {
+		collection = defaultCollection
+	}
+	return &mongoStore{
+		collection: collection,
+		connection: connection,
+		now:        time.Now,
+	}
+}
+
+type mongoRecord struct {
+	Key         string              `bson:"_id"`
+	Fingerprint string              `bson:"fingerprint"`
+	Completed   bool                `bson:"completed"`
+	Status      int                 `bson:"status,omitempty"`
+	Header      map[string][]string `bson:"header,omitempty"`
+	Body        []byte              `bson:"body,omitempty"`
+	ExpiresAt   time.Time           `bson:"expires_at"`
+}
+
+type mongoStore struct {
+	collection string
+	connection []string
+	now        func() time.Time
+	indexOnce  sync.Once
+}
+
+func (s *mongoStore) Reserve(ctx context.Context, key, fingerprint string, lock time.Duration) (*Record, error) {
+	now := s.now()
+	doc := &mongoRecord{Key: key, Fingerprint: fingerprint, ExpiresAt: now.Add(lock)}
+	var ret *Record
+
+	err := s.execute(ctx, func(ctx context.Context, c *driver.Collection) error {
+		s.indexOnce.Do(func() { s.ensureIndex(ctx, c) })
+
+		_, err := c.InsertOne(ctx, doc)
+		if !driver.IsDuplicateKeyError(err) {
+			return err
+		}
+
+		// The TTL monitor removes expired documents periodically, so they may still exist
+		res, err := c.ReplaceOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$lte": now}}, doc)
+		if err != nil || res.MatchedCount > 0 {
+			return err
+		}
+
+		existing := &mongoRecord{}
+		if err := c.FindOne(ctx, bson.M{"_id": key}).Decode(existing); err != nil {
+			return err
+		}
+		ret = existing.toRecord()
+		return nil
+	})
+	return ret, err
+}
+
+func (s *mongoStore) Complete(ctx context.Context, key string, resp *Response, ttl time.Duration) error {
+	return s.execute(ctx, func(ctx context.Context, c *driver.Collection) error {
+		_, err := c.UpdateOne(ctx, bson.M{"_id": key, "completed": false}, bson.M{"$set": bson.M{
+			"completed":  true,
+			"status":     resp.Status,
+			"header":     map[string][]string(resp.Header),
+			"body":       resp.Body,
+			"expires_at": s.now().Add(ttl),
+		}})
+		return err
+	})
+}
+
+func (s *mongoStore) Release(ctx context.Context, key string) error {
+	return s.execute(ctx, func(ctx context.Context, c *driver.Collection) error {
+		_, err := c.DeleteOne(ctx, bson.M{"_id": key, "completed": false})
+		return err
+	})
+}
+
+func (s *mongoStore) execute(ctx context.Context, fn func(ctx context.Context, c *driver.Collection) error) error {
+	client := mongo.Get(s.connection...)
+	if client == nil {
+		return errors.New("mongo connection not found for the idempotency store")
+	}
+	return client.Execute(ctx, func(ctx context.Context, db *driver.Database) error {
+		return fn(ctx, db.Collection(s.collection))
+	})
+}
+
+func (s *mongoStore) ensureIndex(ctx context.Context, c *driver.Collection) {
+	_, err := c.Indexes().CreateOne(ctx, driver.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		logx.Warn("failed to create the TTL index of the idempotency keys collection, ", err.Error())
+	}
+}
+
+func (r *mongoRecord) toRecord() *Record {
+	ret := &Record{Fingerprint: r.Fingerprint}
+	if r.Completed {
+		ret.Response = &Response{Status: r.Status, Header: r.Header, Body: r.Body}
+	}
+	return ret
+}
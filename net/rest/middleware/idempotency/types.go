@@ -0,0 +1,39 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// IStore defines the storage of the idempotency records. The default implementation keeps the records in
+// memory, which only deduplicates requests received by the same instance. Use `NewMongoStore` or implement
+// this interface over a shared store to deduplicate requests across multiple instances.
+type IStore interface {
+	// Reserve atomically creates an in-progress record for the key, valid until `lock` elapses. If the key
+	// is already in use returns the existing record instead. In-progress records whose lock elapsed are
+	// replaced.
+	Reserve(ctx context.Context, key, fingerprint string, lock time.Duration) (*Record, error)
+
+	// Complete stores the response of the request that reserved the key, to be replayed until `ttl` elapses
+	Complete(ctx context.Context, key string, resp *Response, ttl time.Duration) error
+
+	// Release deletes the in-progress record of the key, allowing the request to be retried
+	Release(ctx context.Context, key string) error
+}
+
+// Record is the state of an idempotency key
+type Record struct {
+	// Fingerprint identifies the request that reserved the key
+	Fingerprint string
+
+	// Response is the captured response. Nil while the request is in progress
+	Response *Response
+}
+
+// Response is a captured response to be replayed
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
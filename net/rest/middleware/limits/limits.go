@@ -89,14 +89,22 @@ func (mbr *maxBytesReader) Close() error {
 // * Error 413 will be sent to the client (http.StatusRequestEntityTooLarge)
 // * Current context will be aborted
 func Handler(ctx *rest.Context) {
-	if maxSize > 0 {
-		ctx.Request.Body = &maxBytesReader{
-			ctx:        ctx,
-			rdr:        ctx.Request.Body,
-			remaining:  maxSize,
-			wasAborted: false,
-			sawEOF:     false,
-		}
-	}
+	ctx.Request.Body = Reader(ctx, ctx.Request.Body)
 	ctx.Next()
 }
+
+// Reader wraps the provided request body reader to enforce the configured `request_limit_size`, aborting
+// the request in the same way as `Handler` when the limit is exceeded. Useful for middleware that replaces
+// the request body, e.g. to limit the decompressed size of a compressed body.
+func Reader(ctx *rest.Context, rdr io.ReadCloser) io.ReadCloser {
+	if maxSize <= 0 || rdr == nil {
+		return rdr
+	}
+	return &maxBytesReader{
+		ctx:        ctx,
+		rdr:        rdr,
+		remaining:  maxSize,
+		wasAborted: false,
+		sawEOF:     false,
+	}
+}
@@ -22,16 +22,26 @@ type RouteStats struct {
 	mux       sync.Mutex
 }
 
-func (r *RouteStats) getStatusData(status int) *ResponseStats {
+func (r *RouteStats) record(status int, latency time.Duration) {
 	r.mux.Lock()
 	defer r.mux.Unlock()
 
-	if data, ok := r.RouteData[status]; ok {
-		return data
+	responseData, ok := r.RouteData[status]
+	if !ok {
+		responseData = &ResponseStats{}
+		r.RouteData[status] = responseData
+	}
+
+	if latency > responseData.maxDuration {
+		responseData.maxDuration = latency
+		responseData.MaxDuration = latency.String()
 	}
-	responseData := &ResponseStats{}
-	r.RouteData[status] = responseData
-	return responseData
+
+	totalLatency := responseData.meanDuration*time.Duration(responseData.Count) + latency
+	responseData.Count += 1
+	totalLatency = totalLatency / time.Duration(responseData.Count)
+	responseData.meanDuration = totalLatency
+	responseData.MeanDuration = totalLatency.String()
 }
 
 type ResponseStats struct {
@@ -58,8 +68,9 @@ func getRouteData(route string) *RouteStats {
 	return routeData
 }
 
+// Handler is a middleware function that records the count, maximum and mean latency of the responses by
+// route and status class, see `Route` and `GetStats`. The latency is available through `GetMeasuredLatency`.
 func Handler(c *rest.Context) {
-	key := c.Request.URL.Path
 	start := time.Now()
 	c.Next()
 	latency := time.Since(start)
@@ -77,20 +88,7 @@ func Handler(c *rest.Context) {
 		status = 500
 	}
 
-	routeData := getRouteData(key)
-	responseData := routeData.getStatusData(status)
-
-	if latency > responseData.maxDuration {
-		responseData.maxDuration = latency
-		responseData.MaxDuration = latency.String()
-	}
-
-	totalLatency := responseData.meanDuration*time.Duration(responseData.Count) + latency
-	responseData.Count += 1
-	totalLatency = totalLatency / time.Duration(responseData.Count)
-	responseData.meanDuration = totalLatency
-	responseData.MeanDuration = totalLatency.String()
-
+	getRouteData(Route(c)).record(status, latency)
 	c.Set(LatencyContextKey, latency)
 }
 
@@ -111,10 +109,12 @@ func GetStats() map[string]*RouteStats {
 		cloned := &RouteStats{
 			RouteData: map[int]*ResponseStats{},
 		}
+		v.mux.Lock()
 		for x, y := range v.RouteData {
 			val := *y
 			cloned.RouteData[x] = &val
 		}
+		v.mux.Unlock()
 		ret[k] = cloned
 	}
 	return ret
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+func TestHandlerRoutes(t *testing.T) {
+	router := createRouter(2)
+	serve(router, "/users/1")
+	serve(router, "/users/2")
+	serve(router, "/missing")
+	serve(router, "/orders/1")
+
+	stats := GetStats()
+	assert.Equal(t, int64(2), stats["/users/:id"].RouteData[200].Count)
+	assert.Equal(t, int64(1), stats[NoRoute].RouteData[400].Count)
+	assert.Equal(t, int64(1), stats[OtherRoutes].RouteData[200].Count)
+	assert.Nil(t, stats["/users/1"])
+}
+
+func TestPathNormalizer(t *testing.T) {
+	router := createRouter(-1)
+	SetPathNormalizer(func(c *rest.Context) string {
+		if strings.HasPrefix(c.FullPath(), "/users") {
+			return "/users"
+		}
+		return ""
+	})
+	defer SetPathNormalizer(nil)
+
+	serve(router, "/users/1")
+	serve(router, "/orders/1")
+
+	stats := GetStats()
+	assert.Equal(t, int64(1), stats["/users"].RouteData[200].Count)
+	assert.Equal(t, int64(1), stats["/orders/:id"].RouteData[200].Count)
+}
+
+func createRouter(max int) *gin.Engine {
+	mux.Lock()
+	routerStats = map[string]*RouteStats{}
+	mux.Unlock()
+	routesMux.Lock()
+	tracked, maxRoutes = map[string]struct{}{}, max
+	routesMux.Unlock()
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		Handler(rest.NewContext(c, false))
+	})
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/users/:id", handler)
+	router.GET("/orders/:id", handler)
+	return router
+}
+
+func serve(router *gin.Engine, uri string) {
+	req, _ := http.NewRequest(http.MethodGet, uri, nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
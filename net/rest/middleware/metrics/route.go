@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	// NoRoute is the route reported for the requests which did not match any route
+	NoRoute = "NO_ROUTE"
+
+	// OtherRoutes is the route reported for the requests of the routes beyond the `max_routes` cap
+	OtherRoutes = "OTHER"
+)
+
+// PathNormalizer returns the route reported by the metrics for a request, e.g. to keep the labels of
+// existing dashboards. Returning an empty string reports the default route.
+type PathNormalizer func(c *rest.Context) string
+
+var (
+	normalizer PathNormalizer
+	maxRoutes  = config.Rest().Metrics.MaxRoutes
+	tracked    = map[string]struct{}{}
+	routesMux  sync.RWMutex
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		routesMux.Lock()
+		defer routesMux.Unlock()
+		maxRoutes = config.Metrics.MaxRoutes
+	})
+}
+
+// SetPathNormalizer sets the function that returns the route reported by the metrics for a request, or
+// restores the default if nil. The cap of tracked routes applies to the normalized routes as well.
+//
+//	metrics.SetPathNormalizer(func(c *rest.Context) string {
+//		return strings.TrimSuffix(c.FullPath(), "/")
+//	})
+func SetPathNormalizer(fn PathNormalizer) {
+	routesMux.Lock()
+	defer routesMux.Unlock()
+	normalizer = fn
+}
+
+// Route returns the route reported by the metrics for a request: the template of the matched route, e.g.
+// `/users/:id`, or `NO_ROUTE` if none matched, unless a path normalizer is set. Once `max_routes` distinct
+// routes are tracked, the requests of any other route are reported as `OTHER`.
+func Route(c *rest.Context) string {
+	routesMux.RLock()
+	fn := normalizer
+	routesMux.RUnlock()
+
+	var route string
+	if fn != nil {
+		route = fn(c)
+	}
+	if route == "" {
+		route = c.FullPath()
+	}
+	if route == "" {
+		route = NoRoute
+	}
+	return track(route)
+}
+
+// track returns the provided route if it is tracked or can be tracked without exceeding the cap
+func track(route string) string {
+	routesMux.RLock()
+	_, ok := tracked[route]
+	routesMux.RUnlock()
+	if ok {
+		return route
+	}
+
+	routesMux.Lock()
+	defer routesMux.Unlock()
+	if _, ok := tracked[route]; ok {
+		return route
+	}
+	if maxRoutes >= 0 && len(tracked) >= maxRoutes {
+		return OtherRoutes
+	}
+	tracked[route] = struct{}{}
+	return route
+}
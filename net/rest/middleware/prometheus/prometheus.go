@@ -1,33 +1,80 @@
 package prometheus
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/jucardi/go-titan/components/prometheus"
 	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/middleware/metrics"
 )
 
+var (
+	// sizeBuckets range from 100B to 100MB
+	sizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000, 100000000}
+
+	requestsCounter = prometheus.Counter(prometheus.Opts{
+		Name:   "http_server_requests_total",
+		Help:   "Total number of handled HTTP requests",
+		Labels: []string{"method", "route", "code"},
+	})
+
+	requestDuration = prometheus.Histogram(prometheus.Opts{
+		Name:   "http_server_request_duration_seconds",
+		Help:   "Duration of handled HTTP requests in seconds",
+		Labels: []string{"method", "route"},
+	})
+
+	requestsInFlight = prometheus.Gauge(prometheus.Opts{
+		Name: "http_server_requests_in_flight",
+		Help: "Number of HTTP requests being handled",
+	})
+
+	requestSize = prometheus.Histogram(prometheus.Opts{
+		Name:    "http_server_request_size_bytes",
+		Help:    "Size of the bodies of handled HTTP requests in bytes",
+		Labels:  []string{"method", "route"},
+		Buckets: sizeBuckets,
+	})
+
+	responseSize = prometheus.Histogram(prometheus.Opts{
+		Name:    "http_server_response_size_bytes",
+		Help:    "Size of the bodies of HTTP responses in bytes",
+		Labels:  []string{"method", "route"},
+		Buckets: sizeBuckets,
+	})
+)
+
+// Handler is a middleware function that records the RED metrics of the requests in the default Prometheus
+// registry, labeled by method and route, see `metrics.Route`:
+//
+//   - `http_server_requests_total`: count of requests by method, route and status code.
+//   - `http_server_request_duration_seconds`: histogram of the request durations.
+//   - `http_server_requests_in_flight`: number of requests being handled.
+//   - `http_server_request_size_bytes` and `http_server_response_size_bytes`: histograms of the body sizes.
+//     Requests of unknown length are not observed.
+//
+// The buckets of the histograms can be configured by metric name in the `metric_buckets` of the
+// `prometheus` configuration. Must be used before the compression middleware, so the size of the
+// compressed responses is recorded once they are written.
 func Handler(c *rest.Context) {
 	start := time.Now()
+	requestsInFlight.Inc()
+	defer requestsInFlight.Dec()
+
 	c.Next()
+
 	elapsed := time.Since(start)
-	elapsedMs := float64(elapsed.Milliseconds())
-	endpointName := c.Request.Method + " " + c.Request.URL.Path
-
-	client := prometheus.GetSingleton()
-
-	// Observe the elapsed time
-	labels := make(map[string]string)
-	labels["endpointName"] = endpointName
-	client.ObserveHistogramValue(prometheus.HistogramMetricNameRequestTime, elapsedMs, labels)
-	client.SetGaugeValue(prometheus.GaugeMetricNameRequestTime, elapsedMs, labels)
-
-	// Increase error counter
-	status := c.Writer.Status()
-	if status >= 400 {
-		counterLabels := make(map[string]string)
-		counterLabels["statusCode"] = string(rune(status))
-		counterLabels["endpointName"] = endpointName
-		client.IncreaseCounter(prometheus.CounterApiErrors, counterLabels)
+	method, route := c.Request.Method, metrics.Route(c)
+
+	requestsCounter.Inc(method, route, strconv.Itoa(c.Writer.Status()))
+	requestDuration.Observe(elapsed.Seconds(), method, route)
+	if c.Request.ContentLength >= 0 {
+		requestSize.Observe(float64(c.Request.ContentLength), method, route)
+	}
+	size := c.Writer.Size()
+	if size < 0 {
+		size = 0
 	}
+	responseSize.Observe(float64(size), method, route)
 }
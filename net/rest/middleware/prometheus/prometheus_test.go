@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/components/prometheus"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/net/rest/middleware/compress"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestHandler(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		Handler(rest.NewContext(c, false))
+	})
+	router.POST("/orders/:id", func(c *gin.Context) {
+		c.String(http.StatusNotFound, "not found")
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, "/orders/42", strings.NewReader(`{"qty":1}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	labels := map[string]string{"method": http.MethodPost, "route": "/orders/:id", "code": "404"}
+	counter := find(t, "http_server_requests_total", labels)
+	assert.Equal(t, float64(1), counter.GetCounter().GetValue())
+
+	delete(labels, "code")
+	duration := find(t, "http_server_request_duration_seconds", labels)
+	assert.Equal(t, uint64(1), duration.GetHistogram().GetSampleCount())
+	assert.Equal(t, float64(9), find(t, "http_server_request_size_bytes", labels).GetHistogram().GetSampleSum())
+	assert.Equal(t, float64(9), find(t, "http_server_response_size_bytes", labels).GetHistogram().GetSampleSum())
+	assert.Equal(t, float64(0), find(t, "http_server_requests_in_flight", nil).GetGauge().GetValue())
+}
+
+func TestHandlerCompressedResponseSize(t *testing.T) {
+	compressor := compress.New(config.CompressionConfig{MinSize: 1024})
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		Handler(rest.NewContext(c, false))
+	}, func(c *gin.Context) {
+		compressor(rest.NewContext(c, false))
+	})
+	router.GET("/catalog/:size", func(c *gin.Context) {
+		size := 11
+		if c.Param("size") == "large" {
+			size = 5000
+		}
+		c.String(http.StatusOK, strings.Repeat("a", size))
+	})
+
+	labels := map[string]string{"method": http.MethodGet, "route": "/catalog/:size"}
+	var written float64
+	for _, size := range []string{"small", "large"} {
+		req, _ := http.NewRequest(http.MethodGet, "/catalog/"+size, nil)
+		req.Header.Set(rest.HeaderAcceptEncoding, "gzip")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		written += float64(res.Body.Len())
+	}
+
+	assert.Equal(t, written, find(t, "http_server_response_size_bytes", labels).GetHistogram().GetSampleSum())
+	assert.True(t, written < 5000)
+}
+
+func find(t *testing.T, name string, labels map[string]string) *dto.Metric {
+	families, err := prometheus.Default().Gather()
+	assert.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.Metric {
+			if matches(m, labels) {
+				return m
+			}
+		}
+	}
+	t.Fatalf("metric %s %v not found", name, labels)
+	return nil
+}
+
+func matches(m *dto.Metric, labels map[string]string) bool {
+	for _, l := range m.Label {
+		if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+			return false
+		}
+	}
+	return true
+}
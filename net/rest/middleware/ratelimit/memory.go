@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	// To validate the interface implementation at compile time.
+	_ IStore = (*memoryStore)(nil)
+)
+
+const cleanupInterval = time.Minute
+
+// NewMemoryStore creates a new in-memory store
+func NewMemoryStore() IStore {
+	return &memoryStore{
+		entries: map[string]*entry{},
+		now:     time.Now,
+	}
+}
+
+type entry struct {
+	period time.Duration
+	last   time.Time
+
+	// token bucket
+	tokens float64
+
+	// sliding window
+	windowStart time.Time
+	current     int
+	previous    int
+}
+
+type memoryStore struct {
+	entries     map[string]*entry
+	mux         sync.Mutex
+	now         func() time.Time
+	lastCleanup time.Time
+}
+
+func (s *memoryStore) Take(key string, limit Limit) (Result, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+	s.cleanup(now)
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &entry{tokens: float64(limit.Burst), last: now, windowStart: now}
+		s.entries[key] = e
+	}
+	e.period = limit.Period
+
+	if limit.Algorithm == AlgorithmSlidingWindow {
+		e.last = now
+		return e.slidingWindow(now, limit), nil
+	}
+	return e.tokenBucket(now, limit), nil
+}
+
+func (e *entry) tokenBucket(now time.Time, limit Limit) Result {
+	rate := float64(limit.Limit) / float64(limit.Period)
+
+	e.tokens = math.Min(float64(limit.Burst), e.tokens+float64(now.Sub(e.last))*rate)
+	e.last = now
+
+	ret := Result{Limit: limit.Limit}
+	if e.tokens >= 1 {
+		e.tokens--
+		ret.Allowed = true
+	} else {
+		ret.RetryAfter = time.Duration(math.Ceil((1 - e.tokens) / rate))
+	}
+	ret.Remaining = int(e.tokens)
+	ret.Reset = time.Duration(math.Ceil((float64(limit.Burst) - e.tokens) / rate))
+	return ret
+}
+
+// slidingWindow approximates a sliding log by weighting the count of the previous fixed window by the
+// portion of it that still overlaps with the sliding window.
+func (e *entry) slidingWindow(now time.Time, limit Limit) Result {
+	elapsed := now.Sub(e.windowStart)
+	if elapsed >= 2*limit.Period {
+		e.previous, e.current = 0, 0
+		e.windowStart = now
+		elapsed = 0
+	} else if elapsed >= limit.Period {
+		e.previous, e.current = e.current, 0
+		e.windowStart = e.windowStart.Add(limit.Period)
+		elapsed -= limit.Period
+	}
+
+	weight := 1 - float64(elapsed)/float64(limit.Period)
+	count := float64(e.previous)*weight + float64(e.current)
+
+	ret := Result{Limit: limit.Limit, Reset: limit.Period - elapsed}
+	if count+1 <= float64(limit.Limit) {
+		e.current++
+		ret.Allowed = true
+		count++
+	} else {
+		ret.RetryAfter = limit.Period - elapsed
+	}
+	ret.Remaining = int(math.Max(0, float64(limit.Limit)-count))
+	return ret
+}
+
+// cleanup removes the entries which have been idle long enough to be fully restored
+func (s *memoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+	for k, e := range s.entries {
+		if now.Sub(e.last) > 2*e.period {
+			delete(s.entries, k)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/utils/paths"
+)
+
+const (
+	HeaderRetryAfter         = "Retry-After"
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+
+	KeyIP           = "ip"
+	KeyRoute        = "route"
+	KeyPrincipal    = "principal"
+	keyHeaderPrefix = "header:"
+)
+
+// KeyExtractor returns the key that identifies the client of a request
+type KeyExtractor func(c *rest.Context) string
+
+var (
+	cfg        config.RateLimitConfig
+	prefixes   []string
+	store      = NewMemoryStore()
+	extractors = map[string]KeyExtractor{
+		KeyIP:    func(c *rest.Context) string { return c.ClientIP() },
+		KeyRoute: func(c *rest.Context) string { return c.Request.Method + " " + c.FullPath() },
+		KeyPrincipal: func(c *rest.Context) string {
+			if p := c.Principal(); p != nil {
+				return "principal:" + p.Subject
+			}
+			return c.ClientIP()
+		},
+	}
+	policies int32
+	mux      sync.RWMutex
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		setConfig(config.RateLimit)
+	})
+}
+
+// setConfig assigns the configuration and the group prefixes matched by the requests
+func setConfig(c config.RateLimitConfig) {
+	var groups []string
+	for k := range c.Groups {
+		groups = append(groups, k)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	cfg, prefixes = c, groups
+}
+
+// SetStore replaces the store that keeps the rate limiting state. Use it to share the state across
+// multiple instances.
+func SetStore(s IStore) {
+	mux.Lock()
+	defer mux.Unlock()
+	store = s
+}
+
+// RegisterKeyExtractor registers a custom key extractor that can be referenced by name in the `key`
+// field of a rate limit policy.
+func RegisterKeyExtractor(name string, extractor KeyExtractor) {
+	mux.Lock()
+	defer mux.Unlock()
+	extractors[name] = extractor
+}
+
+// Handler is a middleware function that rate limits requests using the policies in the `rate_limit`
+// configuration. Has no effect unless rate limiting is enabled. Must be used after the authentication
+// middleware for the `principal` and `header:{name}` keys to identify the authenticated clients.
+//
+// When a request is over the limit, a (429) Too Many Requests error is sent including the `Retry-After`
+// header. The `RateLimit-Limit`, `RateLimit-Remaining` and `RateLimit-Reset` headers are set on every
+// rate limited response.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	enabled, prefix, policy := cfg.Enabled, "", cfg.Default
+	if enabled && len(prefixes) > 0 {
+		if match, ok := paths.MatchPrefix(c.Request.URL.Path, prefixes...); ok {
+			prefix, policy = match, cfg.Groups[match]
+		}
+	}
+	mux.RUnlock()
+
+	if !enabled {
+		c.Next()
+		return
+	}
+	limit(c, prefix, policy)
+}
+
+// New creates a middleware function that rate limits requests using the provided policy regardless of
+// the `rate_limit` configuration. Useful to apply a specific policy to a route group.
+func New(policy config.RateLimitPolicy) func(c *rest.Context) {
+	id := "policy-" + strconv.Itoa(int(atomic.AddInt32(&policies, 1)))
+	return func(c *rest.Context) {
+		limit(c, id, policy)
+	}
+}
+
+func limit(c *rest.Context, prefix string, policy config.RateLimitPolicy) {
+	if policy.Limit <= 0 {
+		c.Next()
+		return
+	}
+
+	mux.RLock()
+	s := store
+	key := extractKey(c, policy.Key)
+	mux.RUnlock()
+
+	l := toLimit(policy)
+	result, err := s.Take(prefix+"|"+key, l)
+	if err != nil {
+		// Failing open, an unavailable store should not take the service down
+		logx.Warn("rate limit store failed, skipping rate limiting, ", err.Error())
+		c.Next()
+		return
+	}
+
+	c.Header(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+	c.Header(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+	c.Header(HeaderRateLimitReset, seconds(result.Reset))
+
+	if !result.Allowed {
+		c.Header(HeaderRetryAfter, seconds(result.RetryAfter))
+		code := http.StatusTooManyRequests
+		c.AbortWithError(code, errorx.New(code, http.StatusText(code), "rate limit exceeded, retry later"))
+		return
+	}
+	c.Next()
+}
+
+func extractKey(c *rest.Context, key string) string {
+	if key == "" {
+		key = KeyIP
+	}
+	if strings.HasPrefix(key, keyHeaderPrefix) {
+		// The header is only trusted once the request is authenticated, otherwise a client could get a new
+		// allowance on every request by changing its value
+		if ret := c.GetHeader(strings.TrimPrefix(key, keyHeaderPrefix)); ret != "" && c.Principal() != nil {
+			return "header:" + ret
+		}
+		return c.ClientIP()
+	}
+	if e, ok := extractors[key]; ok {
+		return e(c)
+	}
+	logx.Warnf("unknown rate limit key extractor '%s', using client IP", key)
+	return c.ClientIP()
+}
+
+func toLimit(policy config.RateLimitPolicy) Limit {
+	ret := Limit{
+		Algorithm: policy.Algorithm,
+		Limit:     policy.Limit,
+		Burst:     policy.Burst,
+		Period:    time.Duration(policy.Period) * time.Millisecond,
+	}
+	if ret.Algorithm == "" {
+		ret.Algorithm = AlgorithmTokenBucket
+	}
+	if ret.Burst <= 0 {
+		ret.Burst = ret.Limit
+	}
+	if ret.Period <= 0 {
+		ret.Period = time.Second
+	}
+	return ret
+}
+
+// seconds formats a duration as delta-seconds, rounding up
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	testUri = "/v1/test"
+)
+
+func TestTokenBucket(t *testing.T) {
+	s := NewMemoryStore().(*memoryStore)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	l := Limit{Algorithm: AlgorithmTokenBucket, Limit: 2, Burst: 2, Period: time.Second}
+
+	for i := 0; i < 2; i++ {
+		r, _ := s.Take("key", l)
+		assert.True(t, r.Allowed)
+	}
+	r, _ := s.Take("key", l)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, 500*time.Millisecond, r.RetryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	r, _ = s.Take("key", l)
+	assert.True(t, r.Allowed)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	s := NewMemoryStore().(*memoryStore)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	l := Limit{Algorithm: AlgorithmSlidingWindow, Limit: 4, Period: time.Second}
+
+	for i := 0; i < 4; i++ {
+		r, _ := s.Take("key", l)
+		assert.True(t, r.Allowed)
+	}
+	r, _ := s.Take("key", l)
+	assert.False(t, r.Allowed)
+
+	// Half of the previous window still overlaps, so only 2 more requests are allowed
+	now = now.Add(1500 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		r, _ = s.Take("key", l)
+		assert.True(t, r.Allowed)
+	}
+	r, _ = s.Take("key", l)
+	assert.False(t, r.Allowed)
+}
+
+func TestHandlerRespondsTooManyRequests(t *testing.T) {
+	router := createRouter(New(config.RateLimitPolicy{Limit: 1, Period: 60000, Key: "header:X-API-Key"}))
+
+	res := serve(router, "key-1")
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "1", res.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "0", res.Header().Get(HeaderRateLimitRemaining))
+
+	res = serve(router, "key-1")
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "60", res.Header().Get(HeaderRetryAfter))
+
+	// A different key has its own allowance
+	res = serve(router, "key-2")
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	// Clients without the header are limited by IP
+	for _, addr := range []string{"10.0.0.1:1234", "10.0.0.2:1234"} {
+		res = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, testUri, nil)
+		req.RemoteAddr = addr
+		router.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusOK, res.Code)
+	}
+}
+
+func TestHandlerUnauthenticatedHeader(t *testing.T) {
+	router := createRouter(New(config.RateLimitPolicy{Limit: 1, Period: 60000, Key: "header:X-API-Key"}))
+
+	// Unauthenticated requests are limited by IP regardless of the header value
+	assert.Equal(t, http.StatusOK, serve(router, "forged-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "forged-2").Code)
+}
+
+func TestHandlerPrincipal(t *testing.T) {
+	router := createRouter(New(config.RateLimitPolicy{Limit: 1, Period: 60000, Key: KeyPrincipal}))
+
+	assert.Equal(t, http.StatusOK, serve(router, "key-1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "key-1").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "key-2").Code)
+	assert.Equal(t, http.StatusOK, serve(router, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, "forged").Code)
+}
+
+func serve(router *gin.Engine, apiKey string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, testUri, nil)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	router.ServeHTTP(res, req)
+	return res
+}
+
+// createRouter creates a router which authenticates the requests with the API keys prefixed with "key-"
+func createRouter(handler func(c *rest.Context)) *gin.Engine {
+	router := gin.New()
+	router.Use(func(context *gin.Context) {
+		c := rest.NewContext(context, false)
+		if key := c.GetHeader("X-API-Key"); strings.HasPrefix(key, "key-") {
+			c.SetPrincipal(&authx.Principal{Subject: key})
+		}
+		handler(c)
+	})
+	router.GET(testUri, func(c *gin.Context) {
+		c.String(200, "OK")
+	})
+	return router
+}
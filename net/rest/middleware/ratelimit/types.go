@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+const (
+	AlgorithmTokenBucket   = "token_bucket"
+	AlgorithmSlidingWindow = "sliding_window"
+)
+
+// IStore defines the storage of the rate limiting state. The default implementation keeps the state in
+// memory, which is only accurate for a single instance. Implement this interface over a shared store to
+// rate limit across multiple instances.
+type IStore interface {
+	// Take consumes one request from the allowance of the provided key
+	Take(key string, limit Limit) (Result, error)
+}
+
+// Limit is the resolved rate limit applied to a key
+type Limit struct {
+	Algorithm string
+	Limit     int
+	Burst     int
+	Period    time.Duration
+}
+
+// Result is the outcome of a `Take` operation
+type Result struct {
+	// Allowed indicates whether the request is allowed
+	Allowed bool
+
+	// Limit is the amount of requests allowed per period
+	Limit int
+
+	// Remaining is the amount of requests remaining in the current period
+	Remaining int
+
+	// Reset is the time until the allowance is fully restored
+	Reset time.Duration
+
+	// RetryAfter is the time until the next request is allowed. Only set if `Allowed` is false
+	RetryAfter time.Duration
+}
@@ -0,0 +1,132 @@
+package secure
+
+import (
+	"sync"
+
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/utils/paths"
+)
+
+const (
+	HeaderStrictTransportSecurity         = "Strict-Transport-Security"
+	HeaderContentTypeOptions              = "X-Content-Type-Options"
+	HeaderFrameOptions                    = "X-Frame-Options"
+	HeaderReferrerPolicy                  = "Referrer-Policy"
+	HeaderPermissionsPolicy               = "Permissions-Policy"
+	HeaderContentSecurityPolicy           = "Content-Security-Policy"
+	HeaderContentSecurityPolicyReportOnly = "Content-Security-Policy-Report-Only"
+
+	omit = "-"
+)
+
+var (
+	defaultPolicy = config.SecurityPolicy{
+		StrictTransportSecurity: "max-age=31536000; includeSubDomains",
+		ContentTypeOptions:      "nosniff",
+		FrameOptions:            "DENY",
+		ReferrerPolicy:          "strict-origin-when-cross-origin",
+		ContentSecurityPolicy:   "default-src 'none'; frame-ancestors 'none'",
+	}
+
+	current = newHeaders(config.SecurityConfig{})
+	mux     sync.RWMutex
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		h := newHeaders(config.Security)
+		mux.Lock()
+		defer mux.Unlock()
+		current = h
+	})
+}
+
+// Handler is a middleware function that sets the security hardening headers configured in `security`.
+// Has no effect unless the security headers are enabled.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	h := current
+	mux.RUnlock()
+	h.handle(c)
+}
+
+// New creates a middleware function that sets the security headers using the provided configuration,
+// regardless of the `security` configuration and of its `Enabled` flag.
+func New(cfg config.SecurityConfig) func(c *rest.Context) {
+	cfg.Enabled = true
+	return newHeaders(cfg).handle
+}
+
+type headers struct {
+	enabled  bool
+	def      [][2]string
+	groups   map[string][][2]string
+	prefixes []string
+}
+
+func newHeaders(cfg config.SecurityConfig) *headers {
+	def := merge(defaultPolicy, cfg.Default)
+	ret := &headers{
+		enabled: cfg.Enabled,
+		def:     toHeaders(def),
+		groups:  map[string][][2]string{},
+	}
+	for prefix, p := range cfg.Groups {
+		ret.groups[prefix] = toHeaders(merge(def, p))
+		ret.prefixes = append(ret.prefixes, prefix)
+	}
+	return ret
+}
+
+func (h *headers) handle(c *rest.Context) {
+	if !h.enabled {
+		c.Next()
+		return
+	}
+
+	values := h.def
+	if prefix, ok := paths.MatchPrefix(c.Request.URL.Path, h.prefixes...); ok {
+		values = h.groups[prefix]
+	}
+	for _, v := range values {
+		c.Header(v[0], v[1])
+	}
+	c.Next()
+}
+
+// merge returns the base policy with the non-empty values of the override policy
+func merge(base, override config.SecurityPolicy) config.SecurityPolicy {
+	pick := func(b, o string) string {
+		if o != "" {
+			return o
+		}
+		return b
+	}
+	return config.SecurityPolicy{
+		StrictTransportSecurity:         pick(base.StrictTransportSecurity, override.StrictTransportSecurity),
+		ContentTypeOptions:              pick(base.ContentTypeOptions, override.ContentTypeOptions),
+		FrameOptions:                    pick(base.FrameOptions, override.FrameOptions),
+		ReferrerPolicy:                  pick(base.ReferrerPolicy, override.ReferrerPolicy),
+		PermissionsPolicy:               pick(base.PermissionsPolicy, override.PermissionsPolicy),
+		ContentSecurityPolicy:           pick(base.ContentSecurityPolicy, override.ContentSecurityPolicy),
+		ContentSecurityPolicyReportOnly: pick(base.ContentSecurityPolicyReportOnly, override.ContentSecurityPolicyReportOnly),
+	}
+}
+
+func toHeaders(p config.SecurityPolicy) [][2]string {
+	var ret [][2]string
+	add := func(name, value string) {
+		if value != "" && value != omit {
+			ret = append(ret, [2]string{name, value})
+		}
+	}
+	add(HeaderStrictTransportSecurity, p.StrictTransportSecurity)
+	add(HeaderContentTypeOptions, p.ContentTypeOptions)
+	add(HeaderFrameOptions, p.FrameOptions)
+	add(HeaderReferrerPolicy, p.ReferrerPolicy)
+	add(HeaderPermissionsPolicy, p.PermissionsPolicy)
+	add(HeaderContentSecurityPolicy, p.ContentSecurityPolicy)
+	add(HeaderContentSecurityPolicyReportOnly, p.ContentSecurityPolicyReportOnly)
+	return ret
+}
@@ -0,0 +1,103 @@
+package secure_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/net/rest/endpoints"
+	"github.com/jucardi/go-titan/net/rest/middleware/secure"
+	"github.com/jucardi/go-titan/net/rest/openapi"
+	"github.com/jucardi/go-titan/net/rest/router"
+	"github.com/jucardi/go-titan/utils/testutils"
+)
+
+func prepare(cfg config.SecurityConfig) {
+	r := router.Bare()
+	r.Use(secure.New(cfg))
+	testutils.Prepare(r, func(r router.IRouter) {
+		handler := func(c *rest.Context) { c.String(http.StatusOK, "OK") }
+		r.GET("/api/test", handler)
+		r.GET("/docs/index", handler)
+	})
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	prepare(config.SecurityConfig{})
+
+	res := testutils.Serve(testutils.RequestNoBody(http.MethodGet, "/api/test"))
+	assert.Equal(t, http.StatusOK, res.GetCode())
+	assert.Equal(t, "max-age=31536000; includeSubDomains", res.Header().Get(secure.HeaderStrictTransportSecurity))
+	assert.Equal(t, "nosniff", res.Header().Get(secure.HeaderContentTypeOptions))
+	assert.Equal(t, "DENY", res.Header().Get(secure.HeaderFrameOptions))
+	assert.Equal(t, "strict-origin-when-cross-origin", res.Header().Get(secure.HeaderReferrerPolicy))
+	assert.Equal(t, "default-src 'none'; frame-ancestors 'none'", res.Header().Get(secure.HeaderContentSecurityPolicy))
+	assert.Equal(t, "", res.Header().Get(secure.HeaderPermissionsPolicy))
+}
+
+func TestGroupOverrides(t *testing.T) {
+	prepare(config.SecurityConfig{
+		Default: config.SecurityPolicy{
+			PermissionsPolicy: "geolocation=()",
+		},
+		Groups: map[string]config.SecurityPolicy{
+			"/docs": {
+				FrameOptions:            "SAMEORIGIN",
+				ContentSecurityPolicy:   "default-src 'self'",
+				StrictTransportSecurity: "-",
+			},
+		},
+	})
+
+	res := testutils.Serve(testutils.RequestNoBody(http.MethodGet, "/docs/index"))
+	assert.Equal(t, http.StatusOK, res.GetCode())
+	assert.Equal(t, "SAMEORIGIN", res.Header().Get(secure.HeaderFrameOptions))
+	assert.Equal(t, "default-src 'self'", res.Header().Get(secure.HeaderContentSecurityPolicy))
+	assert.Equal(t, "", res.Header().Get(secure.HeaderStrictTransportSecurity))
+	assert.Equal(t, "geolocation=()", res.Header().Get(secure.HeaderPermissionsPolicy))
+	assert.Equal(t, "nosniff", res.Header().Get(secure.HeaderContentTypeOptions))
+
+	res = testutils.Serve(testutils.RequestNoBody(http.MethodGet, "/api/test"))
+	assert.Equal(t, "DENY", res.Header().Get(secure.HeaderFrameOptions))
+	assert.Equal(t, "geolocation=()", res.Header().Get(secure.HeaderPermissionsPolicy))
+}
+
+func TestDisabledHandler(t *testing.T) {
+	r := router.Bare()
+	r.Use(secure.Handler)
+	testutils.Prepare(r, func(r router.IRouter) {
+		r.GET("/api/test", func(c *rest.Context) { c.String(http.StatusOK, "OK") })
+	})
+
+	res := testutils.Serve(testutils.RequestNoBody(http.MethodGet, "/api/test"))
+	assert.Equal(t, http.StatusOK, res.GetCode())
+	assert.Equal(t, "", res.Header().Get(secure.HeaderContentTypeOptions))
+}
+
+func TestDocsPolicy(t *testing.T) {
+	handler := secure.New(config.SecurityConfig{})
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { handler(rest.NewContext(c, false)) })
+	endpoints.AddOpenAPI(engine, engine)
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, res.Code)
+
+	policy := res.Header().Get(secure.HeaderContentSecurityPolicy)
+	assert.Equal(t, openapi.UIContentSecurityPolicy(), policy)
+	assert.True(t, strings.Contains(policy, "connect-src 'self'"))
+	for _, tag := range []string{"script", "style"} {
+		page := res.Body.String()
+		inline := page[strings.Index(page, "<"+tag+">")+len(tag)+2 : strings.Index(page, "</"+tag+">")]
+		sum := sha256.Sum256([]byte(inline))
+		assert.True(t, strings.Contains(policy, tag+"-src 'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'"))
+	}
+}
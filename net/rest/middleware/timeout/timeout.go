@@ -0,0 +1,130 @@
+package timeout
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/utils/paths"
+)
+
+var (
+	cfg      config.TimeoutConfig
+	prefixes []string
+	mux      sync.RWMutex
+
+	grpcUnits = map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+)
+
+func init() {
+	config.AddReloadCallback(func(config *config.RestConfig) {
+		setConfig(config.Timeout)
+	})
+}
+
+// setConfig assigns the configuration and the group prefixes matched by the requests
+func setConfig(c config.TimeoutConfig) {
+	var groups []string
+	for k := range c.Groups {
+		groups = append(groups, k)
+	}
+	mux.Lock()
+	defer mux.Unlock()
+	cfg, prefixes = c, groups
+}
+
+// Handler is a middleware function that bounds the duration of requests using the `timeout` configuration.
+// The timeout of the longest matching group prefix is applied, or the default timeout otherwise.
+//
+// Clients may request a different timeout with the `X-Request-Timeout` or `grpc-timeout` headers, capped
+// by the configured `max`, or by the route timeout if `max` is not set. If neither is set, the requested
+// timeout is applied as is.
+//
+// See `New` for how the timeout is enforced.
+func Handler(c *rest.Context) {
+	mux.RLock()
+	timeout, max, headers := cfg.Default, cfg.Max, !cfg.DisableHeaders
+	if len(prefixes) > 0 {
+		if match, ok := paths.MatchPrefix(c.Request.URL.Path, prefixes...); ok {
+			timeout = cfg.Groups[match]
+		}
+	}
+	mux.RUnlock()
+
+	d := time.Duration(timeout) * time.Millisecond
+	if headers {
+		if requested, ok := requestedTimeout(c); ok {
+			limit := time.Duration(max) * time.Millisecond
+			if limit <= 0 {
+				limit = d
+			}
+			if limit > 0 && requested > limit {
+				requested = limit
+			}
+			d = requested
+		}
+	}
+	apply(c, d)
+}
+
+// New creates a middleware function that bounds the duration of requests to the provided timeout regardless
+// of the `timeout` configuration. Useful to apply a specific timeout to a route group. Nested timeouts can
+// only shorten the deadline of the request.
+//
+// The deadline is set in the request context, available to handlers through `rest.Context`, which is
+// cancelled when the timeout elapses. Handlers are expected to pass it to downstream calls and return when
+// it is done; if the handler returns without writing a response after the deadline, a (408) Request Timeout
+// error is sent.
+func New(timeout time.Duration) func(c *rest.Context) {
+	return func(c *rest.Context) {
+		apply(c, timeout)
+	}
+}
+
+func apply(c *rest.Context, timeout time.Duration) {
+	if timeout <= 0 {
+		c.Next()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+
+	if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
+		c.SendError(errorx.NewRequestTimeout("the request exceeded its deadline of " + timeout.String()))
+	}
+}
+
+// requestedTimeout parses the timeout requested by the client. `X-Request-Timeout` accepts a duration
+// (`1.5s`, `300ms`) or milliseconds, and `grpc-timeout` uses the gRPC format, e.g. `300m`.
+func requestedTimeout(c *rest.Context) (time.Duration, bool) {
+	if v := strings.TrimSpace(c.GetHeader(rest.HeaderRequestTimeout)); v != "" {
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond, true
+		}
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d, true
+		}
+	}
+	if v := strings.TrimSpace(c.GetHeader(rest.HeaderGrpcTimeout)); len(v) > 1 {
+		unit, ok := grpcUnits[v[len(v)-1]]
+		if n, err := strconv.ParseInt(v[:len(v)-1], 10, 64); ok && err == nil && n > 0 {
+			return time.Duration(n) * unit, true
+		}
+	}
+	return 0, false
+}
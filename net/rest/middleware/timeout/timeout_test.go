@@ -0,0 +1,89 @@
+package timeout
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	testUri = "/v1/test"
+)
+
+func TestHandlerRespondsRequestTimeout(t *testing.T) {
+	router := createRouter(New(20*time.Millisecond), func(c *rest.Context) {
+		<-c.Done()
+		assert.Equal(t, context.DeadlineExceeded, c.Err())
+	})
+
+	res := serve(router, nil)
+	assert.Equal(t, http.StatusRequestTimeout, res.Code)
+
+	// Responses written by the handler are kept
+	router = createRouter(New(time.Second), func(c *rest.Context) {
+		c.String(http.StatusOK, "OK")
+	})
+	assert.Equal(t, http.StatusOK, serve(router, nil).Code)
+}
+
+func TestHandlerRequestedTimeout(t *testing.T) {
+	setConfig(config.TimeoutConfig{Default: 1000, Groups: map[string]int64{"/v1": 5000}})
+	defer setConfig(config.TimeoutConfig{})
+
+	var remaining time.Duration
+	router := createRouter(Handler, func(c *rest.Context) {
+		deadline, ok := c.Deadline()
+		assert.True(t, ok)
+		remaining = time.Until(deadline)
+		c.Status(http.StatusNoContent)
+	})
+
+	serve(router, nil)
+	assert.True(t, remaining > 4*time.Second && remaining <= 5*time.Second)
+
+	serve(router, map[string]string{rest.HeaderRequestTimeout: "250ms"})
+	assert.True(t, remaining <= 250*time.Millisecond)
+
+	serve(router, map[string]string{rest.HeaderGrpcTimeout: "300m"})
+	assert.True(t, remaining > 250*time.Millisecond && remaining <= 300*time.Millisecond)
+
+	// Capped by the route timeout if no max is configured
+	serve(router, map[string]string{rest.HeaderRequestTimeout: "60000"})
+	assert.True(t, remaining > 4*time.Second && remaining <= 5*time.Second)
+
+	cfg.Max = 10000
+	serve(router, map[string]string{rest.HeaderRequestTimeout: "60000"})
+	assert.True(t, remaining > 9*time.Second && remaining <= 10*time.Second)
+
+	cfg.DisableHeaders = true
+	serve(router, map[string]string{rest.HeaderRequestTimeout: "250ms"})
+	assert.True(t, remaining > 4*time.Second)
+}
+
+func serve(router *gin.Engine, headers map[string]string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, testUri, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	router.ServeHTTP(res, req)
+	return res
+}
+
+func createRouter(handler func(c *rest.Context), route func(c *rest.Context)) *gin.Engine {
+	router := gin.New()
+	router.Use(func(context *gin.Context) {
+		handler(rest.NewContext(context, false))
+	})
+	router.GET(testUri, func(c *gin.Context) {
+		route(rest.NewContext(c, false))
+	})
+	return router
+}
@@ -0,0 +1,135 @@
+package rest
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptRange is a media range of an `Accept` header with its quality value
+type AcceptRange struct {
+	Type    string
+	Subtype string
+	Q       float64
+}
+
+// Matches indicates whether the media range matches the provided media type
+func (a AcceptRange) Matches(mediaType string) bool {
+	t, s := splitMediaType(mediaType)
+	return (a.Type == "*" || a.Type == t) && (a.Subtype == "*" || a.Subtype == s)
+}
+
+// specificity orders the ranges from the most specific (type/subtype) to the least specific (*/*)
+func (a AcceptRange) specificity() int {
+	switch {
+	case a.Type == "*":
+		return 0
+	case a.Subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// ParseAccept parses an `Accept` header into its media ranges, sorted by quality and specificity
+func ParseAccept(header string) []AcceptRange {
+	var ret []AcceptRange
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		r := AcceptRange{Q: 1}
+		r.Type, r.Subtype = splitMediaType(mediaType)
+		if q, ok := params["q"]; ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v >= 0 && v <= 1 {
+				r.Q = v
+			}
+		}
+		ret = append(ret, r)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Q != ret[j].Q {
+			return ret[i].Q > ret[j].Q
+		}
+		return ret[i].specificity() > ret[j].specificity()
+	})
+	return ret
+}
+
+// Negotiate returns the offered media type that best matches the `Accept` header, or empty if none of the
+// offers is acceptable. Offers are in order of preference, used to break ties. An empty header accepts the
+// first offer.
+func Negotiate(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := ParseAccept(accept)
+	best, bestQ, bestSpec := "", 0.0, -1
+	for _, offer := range offers {
+		q, spec := quality(ranges, offer)
+		if q > bestQ || (q == bestQ && q > 0 && spec > bestSpec) {
+			best, bestQ, bestSpec = offer, q, spec
+		}
+	}
+	return best
+}
+
+// negotiatePreferred returns the offer negotiated by `Negotiate`, or empty if the `Accept` header also
+// accepts any media type and prefers media types which are not offered. Browsers send such headers, e.g.
+// `text/html,application/xml;q=0.9,*/*;q=0.8`, and the fallback encoding should be used rather than an
+// offer they only accept in passing.
+func negotiatePreferred(accept string, offers ...string) string {
+	ret := Negotiate(accept, offers...)
+	ranges := ParseAccept(accept)
+	if ret == "" || len(ranges) == 0 {
+		return ret
+	}
+	if q, _ := quality(ranges, ret); q < ranges[0].Q {
+		for _, r := range ranges {
+			if r.specificity() == 0 && r.Q > 0 {
+				return ""
+			}
+		}
+	}
+	return ret
+}
+
+// quality returns the quality of an offer, given by the most specific range that matches it, and the
+// specificity of that range
+func quality(ranges []AcceptRange, offer string) (float64, int) {
+	q, spec := -1.0, -1
+	for _, r := range ranges {
+		if r.Matches(offer) && r.specificity() > spec {
+			q, spec = r.Q, r.specificity()
+		}
+	}
+	return q, spec
+}
+
+// acceptsAnyOnly indicates whether the `Accept` header only contains the `*/*` range, as sent by default
+// by most clients
+func acceptsAnyOnly(accept string) bool {
+	for _, r := range ParseAccept(accept) {
+		if r.specificity() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	parts := strings.SplitN(strings.ToLower(mediaType), "/", 2)
+	if len(parts) != 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
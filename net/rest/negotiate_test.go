@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	offers := []string{ContentTypeJson, ContentTypeXml, ContentTypeYaml}
+
+	assert.Equal(t, ContentTypeJson, Negotiate("", offers...))
+	assert.Equal(t, ContentTypeXml, Negotiate("application/xml", offers...))
+	assert.Equal(t, ContentTypeXml, Negotiate("application/json;q=0.5, application/xml", offers...))
+	assert.Equal(t, ContentTypeJson, Negotiate("application/*", offers...))
+	assert.Equal(t, ContentTypeYaml, Negotiate("application/*;q=0.2, application/x-yaml;q=0.9", offers...))
+	assert.Equal(t, ContentTypeXml, Negotiate("*/*, application/json;q=0", offers...))
+	assert.Equal(t, "", Negotiate("text/html", offers...))
+	assert.Equal(t, "", Negotiate("application/json;q=0", offers...))
+}
+
+func TestAutoEncoding(t *testing.T) {
+	obj := &upload{Name: "report", Tags: []string{"a"}, Count: 1}
+
+	cases := []struct {
+		accept      string
+		contentType string
+		expected    string
+	}{
+		{"application/x-yaml", "", ContentTypeYaml},
+		{"application/xml;q=0.5, application/cbor", ContentTypeJson, ContentTypeCbor},
+		{"application/msgpack;q=0.1, application/x-msgpack", "", ContentTypeMsgpack},
+		{"application/xml", "", ContentTypeXml},
+		{"*/*", ContentTypeYaml, ContentTypeYaml},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", ContentTypeYaml, ContentTypeYaml},
+		{"text/csv, application/x-yaml;q=0.5", "", ContentTypeYaml},
+	}
+
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		ctx.Request.Header.Set(HeaderAccept, tc.accept)
+		ctx.Request.Header.Set(HeaderContentType, tc.contentType)
+
+		assert.NoError(t, encoders.Auto(NewContext(ctx, false), http.StatusOK, obj))
+		assert.Equal(t, tc.expected, w.Header().Get(HeaderContentType))
+	}
+
+	// Accepting anything without a known Content-Type leaves the choice to the fallback encoding
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx.Request.Header.Set(HeaderAccept, "*/*")
+	assert.Error(t, encoders.Auto(NewContext(ctx, false), http.StatusOK, obj))
+
+	// Browsers accept XML in passing, which is left to the fallback encoding as well
+	ctx.Request.Header.Set(HeaderAccept, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	assert.Error(t, encoders.Auto(NewContext(ctx, false), http.StatusOK, obj))
+}
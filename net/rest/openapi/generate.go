@@ -0,0 +1,267 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+const (
+	contentTypeJson  = "application/json"
+	contentTypeProto = "application/x-protobuf"
+
+	schemeBearer = "bearerAuth"
+	schemeAPIKey = "apiKeyAuth"
+	schemeHMAC   = "hmacAuth"
+)
+
+var (
+	errorType  = reflect.TypeOf(errorx.Error{})
+	pathParams = regexp.MustCompile(`[:*]([^/]+)`)
+	nonAlnum   = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// Generate creates the OpenAPI document of the provided gin routes using the metadata registered with
+// `Describe`. The security schemes are taken from the provided authenticator, if any.
+func Generate(info Info, routes gin.RoutesInfo, auth ...*authx.Authenticator) *Document {
+	g := &generator{
+		schemas: newSchemas(),
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   map[string]PathItem{},
+		},
+	}
+	if len(auth) > 0 {
+		g.securitySchemes(auth[0])
+	}
+
+	// Always documenting the error model, since any route may respond with it
+	g.errorRef = g.schemas.schema(errorType)
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+
+	tags := map[string]bool{}
+	for _, r := range routes {
+		if strings.Contains(r.Path, "/__") {
+			continue
+		}
+		if meta := lookup(r.Method, r.Path); meta != nil && meta.doc != nil && meta.doc.Hidden {
+			continue
+		}
+		op := g.operation(r)
+		p := pathParams.ReplaceAllString(r.Path, "{$1}")
+		if g.doc.Paths[p] == nil {
+			g.doc.Paths[p] = PathItem{}
+		}
+		g.doc.Paths[p][strings.ToLower(r.Method)] = op
+		for _, t := range op.Tags {
+			tags[t] = true
+		}
+	}
+
+	for t := range tags {
+		g.doc.Tags = append(g.doc.Tags, Tag{Name: t})
+	}
+	sort.Slice(g.doc.Tags, func(i, j int) bool { return g.doc.Tags[i].Name < g.doc.Tags[j].Name })
+
+	g.doc.Components.Schemas = g.schemas.components
+	return g.doc
+}
+
+type generator struct {
+	doc      *Document
+	schemas  *schemas
+	errorRef *Schema
+	security []string
+}
+
+func (g *generator) securitySchemes(a *authx.Authenticator) {
+	if !a.Enabled() {
+		return
+	}
+	schemes := map[string]*SecurityScheme{}
+	for _, m := range a.Methods() {
+		switch m {
+		case authx.MethodJWT:
+			schemes[schemeBearer] = &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+			g.security = append(g.security, schemeBearer)
+		case authx.MethodAPIKey:
+			schemes[schemeAPIKey] = &SecurityScheme{Type: "apiKey", In: "header", Name: a.APIKeyHeader()}
+			g.security = append(g.security, schemeAPIKey)
+		case authx.MethodHMAC:
+			schemes[schemeHMAC] = &SecurityScheme{
+				Type:        "http",
+				Scheme:      authx.SchemeHMAC,
+				Description: "Requests signed with HMAC-SHA256, see `authx.SignRequest`",
+			}
+			g.security = append(g.security, schemeHMAC)
+		}
+	}
+	g.doc.Components.SecuritySchemes = schemes
+}
+
+func (g *generator) operation(r gin.RouteInfo) *Operation {
+	op := &Operation{
+		OperationId: operationId(r.Method, r.Path),
+		Responses:   map[string]*Response{},
+	}
+	for _, m := range pathParams.FindAllStringSubmatch(r.Path, -1) {
+		op.Parameters = append(op.Parameters, &Parameter{Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	meta := lookup(r.Method, r.Path)
+	if meta == nil {
+		meta = &routeMeta{}
+	}
+
+	if doc := meta.doc; doc != nil {
+		op.Summary = doc.Summary
+		op.Description = doc.Description
+		op.Tags = doc.Tags
+		op.Deprecated = doc.Deprecated
+		if doc.OperationId != "" {
+			op.OperationId = doc.OperationId
+		}
+		g.request(r.Method, op, doc.Request)
+		for code, resp := range doc.Responses {
+			op.Responses[strconv.Itoa(code)] = g.response(code, resp)
+		}
+	}
+
+	if len(op.Responses) == 0 {
+		op.Responses[strconv.Itoa(http.StatusOK)] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	if req := meta.security; req != nil {
+		op.RequiredAccess = req.String()
+		for _, s := range g.security {
+			scopes := []string{}
+			if s == schemeBearer {
+				scopes = append(scopes, req.Scopes...)
+			}
+			op.Security = append(op.Security, SecurityRequirement{s: scopes})
+		}
+		for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+			if _, ok := op.Responses[strconv.Itoa(code)]; !ok {
+				op.Responses[strconv.Itoa(code)] = g.response(code, nil)
+			}
+		}
+	}
+
+	op.Responses["default"] = &Response{Description: "Error", Content: g.content(g.errorRef, true)}
+	return op
+}
+
+// request documents the parameters and body of the request type
+func (g *generator) request(method string, op *Operation, req interface{}) {
+	if req == nil {
+		return
+	}
+	t := reflect.TypeOf(req)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		g.body(method, op, g.schemas.of(req), isProto(req))
+		return
+	}
+
+	hasBody := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		switch {
+		case f.Tag.Get("uri") != "":
+			for _, p := range op.Parameters {
+				if p.In == "path" && p.Name == f.Tag.Get("uri") {
+					p.Schema = g.schemas.schema(f.Type)
+				}
+			}
+		case f.Tag.Get("header") != "":
+			op.Parameters = append(op.Parameters, g.param(f, "header", f.Tag.Get("header")))
+		case f.Tag.Get("query") != "":
+			op.Parameters = append(op.Parameters, g.param(f, "query", f.Tag.Get("query")))
+		case f.Tag.Get("form") != "" && !acceptsBody(method):
+			op.Parameters = append(op.Parameters, g.param(f, "query", f.Tag.Get("form")))
+		default:
+			if _, _, skip := jsonName(f); !skip {
+				hasBody = true
+			}
+		}
+	}
+	if hasBody {
+		g.body(method, op, g.schemas.of(req), isProto(req))
+	}
+}
+
+func (g *generator) param(f reflect.StructField, in, name string) *Parameter {
+	return &Parameter{
+		Name:     strings.Split(name, ",")[0],
+		In:       in,
+		Required: isRequired(f),
+		Schema:   g.schemas.schema(f.Type),
+	}
+}
+
+func (g *generator) body(method string, op *Operation, schema *Schema, proto bool) {
+	if !acceptsBody(method) {
+		return
+	}
+	op.RequestBody = &RequestBody{Required: true, Content: g.content(schema, proto)}
+}
+
+func (g *generator) response(code int, resp interface{}) *Response {
+	ret := &Response{Description: http.StatusText(code)}
+	if ret.Description == "" {
+		ret.Description = strconv.Itoa(code)
+	}
+	switch {
+	case resp != nil:
+		ret.Content = g.content(g.schemas.of(resp), isProto(resp))
+	case code >= http.StatusBadRequest:
+		ret.Content = g.content(g.errorRef, true)
+	}
+	return ret
+}
+
+func (g *generator) content(schema *Schema, proto bool) map[string]MediaType {
+	ret := map[string]MediaType{contentTypeJson: {Schema: schema}}
+	if proto {
+		ret[contentTypeProto] = MediaType{Schema: schema}
+	}
+	return ret
+}
+
+func acceptsBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+// operationId generates an operation id from the method and path, e.g. `getUsersId` for `GET /users/:id`
+func operationId(method, path string) string {
+	ret := strings.ToLower(method)
+	for _, p := range nonAlnum.Split(path, -1) {
+		if p != "" {
+			ret += strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return ret
+}
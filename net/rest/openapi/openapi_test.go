@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/errorx"
+)
+
+type listOrdersRequest struct {
+	Page   int    `form:"page"`
+	Tenant string `header:"X-Tenant" binding:"required"`
+}
+
+type createOrderRequest struct {
+	Id    string   `uri:"id"`
+	Items []string `json:"items" binding:"required"`
+	Note  string   `json:"note,omitempty"`
+}
+
+type order struct {
+	Id     string        `json:"id"`
+	Parent *order        `json:"parent,omitempty"`
+	Error  *errorx.Error `json:"error,omitempty"`
+}
+
+func TestGenerate(t *testing.T) {
+	handler := func(c *gin.Context) {}
+	engine := gin.New()
+	engine.GET("/api/orders", handler)
+	engine.POST("/api/orders/:id", handler)
+	engine.GET("/api/__internal", handler)
+	engine.GET("/info", handler)
+
+	Describe(http.MethodGet, "/info", &RouteDoc{Hidden: true}, nil)
+
+	Describe(http.MethodGet, "/api/orders", &RouteDoc{
+		Summary:   "Lists the orders",
+		Tags:      []string{"orders"},
+		Request:   &listOrdersRequest{},
+		Responses: map[int]interface{}{http.StatusOK: []*order{}},
+	}, nil)
+	Describe(http.MethodPost, "/api/orders/:id", &RouteDoc{
+		Request:   &createOrderRequest{},
+		Responses: map[int]interface{}{http.StatusCreated: &order{}, http.StatusConflict: nil},
+	}, &authx.Requirement{Scopes: []string{"orders:write"}})
+
+	cfg := authx.DefaultConfig()
+	cfg.APIKey.Enabled = true
+	a, _ := authx.New(cfg)
+
+	doc := Generate(Info{Title: "orders", Version: "1.0"}, engine.Routes(), a)
+	_, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(doc.Paths))
+	assert.NotNil(t, doc.Components.Schemas["errorx.Error"])
+	assert.NotNil(t, doc.Components.Schemas["errorx.InnerError"])
+	assert.Equal(t, "X-API-Key", doc.Components.SecuritySchemes[schemeAPIKey].Name)
+
+	list := doc.Paths["/api/orders"]["get"]
+	assert.Equal(t, "Lists the orders", list.Summary)
+	assert.Equal(t, 2, len(list.Parameters))
+	assert.Equal(t, "page", list.Parameters[0].Name)
+	assert.Equal(t, "query", list.Parameters[0].In)
+	assert.Equal(t, "header", list.Parameters[1].In)
+	assert.True(t, list.Parameters[1].Required)
+	assert.Nil(t, list.RequestBody)
+	assert.Equal(t, "array", list.Responses["200"].Content[contentTypeJson].Schema.Type)
+	assert.Equal(t, schemaRefPrefix+"order", list.Responses["200"].Content[contentTypeJson].Schema.Items.Ref)
+	assert.Nil(t, list.Security)
+
+	create := doc.Paths["/api/orders/{id}"]["post"]
+	assert.Equal(t, "postApiOrdersId", create.OperationId)
+	assert.Equal(t, "path", create.Parameters[0].In)
+	assert.Equal(t, "id", create.Parameters[0].Name)
+	assert.NotNil(t, create.RequestBody)
+	assert.Equal(t, "scopes=orders:write", create.RequiredAccess)
+	assert.Equal(t, 1, len(create.Security))
+	assert.Equal(t, schemaRefPrefix+"errorx.Error", create.Responses["409"].Content[contentTypeProto].Schema.Ref)
+	assert.NotNil(t, create.Responses["401"])
+	assert.NotNil(t, create.Responses["403"])
+
+	body := doc.Components.Schemas["createOrderRequest"]
+	assert.Equal(t, []string{"items"}, body.Required)
+	assert.Equal(t, schemaRefPrefix+"order", doc.Components.Schemas["order"].Properties["parent"].Ref)
+}
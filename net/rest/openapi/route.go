@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"sync"
+
+	"github.com/jucardi/go-titan/net/authx"
+)
+
+// RouteDoc is the optional metadata of a route used to generate its OpenAPI operation
+type RouteDoc struct {
+	// Summary is a short summary of what the route does
+	Summary string
+
+	// Description is a verbose explanation of the route behavior
+	Description string
+
+	// Tags are used to group the routes in the documentation
+	Tags []string
+
+	// OperationId is the unique identifier of the operation. Generated from the method and path if empty
+	OperationId string
+
+	// Request is a value of the request type, e.g. `&pb.CreateOrderRequest{}`. Fields tagged with `form`
+	// or `query` are documented as query parameters, `header` as headers and `uri` as path parameters. The
+	// remaining fields are documented as the request body for methods that accept one.
+	Request interface{}
+
+	// Responses maps the response status codes to a value of the response type. Use nil for responses
+	// without a body. Error statuses without a type are documented with the `errorx.Error` schema.
+	Responses map[int]interface{}
+
+	// Deprecated marks the route as deprecated
+	Deprecated bool
+
+	// Hidden excludes the route from the document
+	Hidden bool
+}
+
+// MethodAny is the method used to describe routes that handle any method
+const MethodAny = "ANY"
+
+type routeKey struct {
+	method string
+	path   string
+}
+
+type routeMeta struct {
+	doc      *RouteDoc
+	security *authx.Requirement
+}
+
+var (
+	routes = map[routeKey]*routeMeta{}
+	mux    sync.RWMutex
+)
+
+// Describe registers the metadata of a route. `path` is the full route path as registered in gin, and
+// `security` is the route security requirement, if any.
+func Describe(method, path string, doc *RouteDoc, security *authx.Requirement) {
+	mux.Lock()
+	defer mux.Unlock()
+	routes[routeKey{method: method, path: path}] = &routeMeta{doc: doc, security: security}
+}
+
+func lookup(method, path string) *routeMeta {
+	mux.RLock()
+	defer mux.RUnlock()
+	if m, ok := routes[routeKey{method: method, path: path}]; ok {
+		return m
+	}
+	return routes[routeKey{method: MethodAny, path: path}]
+}
@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	rawJsonType   = reflect.TypeOf(json.RawMessage{})
+	protoMsgType  = reflect.TypeOf((*proto.Message)(nil)).Elem()
+	protoEnumType = reflect.TypeOf((*protoreflect.Enum)(nil)).Elem()
+)
+
+// schemas generates the JSON schemas of Go types, registering named structs as components. Field names
+// follow the `json` tags since the responses are encoded with `encoding/json`, which also applies to the
+// generated proto messages.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+// of returns the schema of the type of the provided value
+func (s *schemas) of(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(reflect.Type); ok {
+		return s.schema(t)
+	}
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	case t == rawJsonType:
+		return &Schema{}
+	case t.Implements(protoEnumType) || reflect.PtrTo(t).Implements(protoEnumType):
+		return s.enum(t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	}
+	// Interfaces, including proto oneof fields, accept any value
+	return &Schema{}
+}
+
+// ref registers a named struct as a component and returns a reference to it
+func (s *schemas) ref(t reflect.Type) *Schema {
+	if name, ok := s.names[t]; ok {
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	name := s.name(t)
+	s.names[t] = name
+
+	// Registering the name before generating the fields supports recursive types
+	s.components[name] = &Schema{}
+	obj := s.object(t)
+	if msg, ok := reflect.New(t).Interface().(proto.Message); ok {
+		obj.Title = string(msg.ProtoReflect().Descriptor().FullName())
+	}
+	s.components[name] = obj
+	return &Schema{Ref: schemaRefPrefix + name}
+}
+
+// name returns the component name of a type, the proto full name for proto messages or the Go type name,
+// prefixed with the package name if it collides with another type
+func (s *schemas) name(t reflect.Type) string {
+	name := t.Name()
+	if msg, ok := reflect.New(t).Interface().(proto.Message); ok {
+		name = string(msg.ProtoReflect().Descriptor().FullName())
+	}
+	if _, exists := s.components[name]; !exists {
+		return name
+	}
+	name = path.Base(t.PkgPath()) + "." + t.Name()
+	for i := 2; ; i++ {
+		if _, exists := s.components[name]; !exists {
+			return name
+		}
+		name = fmt.Sprintf("%s.%s%d", path.Base(t.PkgPath()), t.Name(), i)
+	}
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	ret := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.fields(t, ret)
+	return ret
+}
+
+func (s *schemas) fields(t reflect.Type, obj *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name, _, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened, as done by encoding/json
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && f.Tag.Get("json") == "" && ft.Kind() == reflect.Struct {
+			s.fields(ft, obj)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		obj.Properties[name] = s.schema(f.Type)
+		if isRequired(f) {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
+
+// enum returns the schema of a proto enum, encoded by `encoding/json` as its number
+func (s *schemas) enum(t reflect.Type) *Schema {
+	e, ok := reflect.Zero(t).Interface().(protoreflect.Enum)
+	if !ok {
+		e, _ = reflect.New(t).Interface().(protoreflect.Enum)
+	}
+	ret := &Schema{Type: "integer", Format: "int32"}
+	if e == nil {
+		return ret
+	}
+
+	values := e.Descriptor().Values()
+	var names []string
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		ret.Enum = append(ret.Enum, int32(v.Number()))
+		names = append(names, fmt.Sprintf("%d: %s", v.Number(), v.Name()))
+	}
+	ret.Description = fmt.Sprintf("%s (%s)", e.Descriptor().FullName(), strings.Join(names, ", "))
+	return ret
+}
+
+// isProto indicates whether the type is a proto message
+func isProto(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	t := reflect.TypeOf(v)
+	if rt, ok := v.(reflect.Type); ok {
+		t = rt
+	}
+	if t.Kind() != reflect.Ptr {
+		t = reflect.PtrTo(t)
+	}
+	return t.Implements(protoMsgType)
+}
+
+func jsonName(f reflect.StructField) (name string, omitempty, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// isRequired indicates whether the field is marked as required by the binding or validation tags
+func isRequired(f reflect.StructField) bool {
+	for _, tag := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(f.Tag.Get(tag), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package openapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+)
+
+//go:embed ui.html
+var ui []byte
+
+var uiPolicy = fmt.Sprintf("default-src 'none'; script-src %s; style-src %s; connect-src 'self'; frame-ancestors 'none'",
+	inlineHash(ui, "script"), inlineHash(ui, "style"))
+
+// UI returns the bundled HTML page that renders the document served at `openapi.json`, relative to the page
+func UI() []byte {
+	return ui
+}
+
+// UIContentSecurityPolicy returns the Content Security Policy that allows the page returned by `UI`, which
+// uses an inline script and style sheet and fetches the document from the same origin.
+func UIContentSecurityPolicy() string {
+	return uiPolicy
+}
+
+// inlineHash returns the CSP hash source of the content of the first `tag` element of the page
+func inlineHash(page []byte, tag string) string {
+	start := bytes.Index(page, []byte("<"+tag+">"))
+	end := bytes.Index(page, []byte("</"+tag+">"))
+	if start < 0 || end < start {
+		return "'none'"
+	}
+	sum := sha256.Sum256(page[start+len(tag)+2 : end])
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
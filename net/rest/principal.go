@@ -0,0 +1,20 @@
+package rest
+
+import "github.com/jucardi/go-titan/net/authx"
+
+const principalKey = "auth-principal"
+
+// Principal returns the principal authenticated for the current request, or nil if the request is anonymous
+func (c *Context) Principal() *authx.Principal {
+	if v, ok := c.Get(principalKey); ok {
+		if p, ok := v.(*authx.Principal); ok {
+			return p
+		}
+	}
+	return nil
+}
+
+// SetPrincipal assigns the principal authenticated for the current request
+func (c *Context) SetPrincipal(p *authx.Principal) {
+	c.Set(principalKey, p)
+}
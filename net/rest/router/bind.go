@@ -0,0 +1,134 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+const (
+	tagUri    = "uri"
+	tagForm   = "form"
+	tagQuery  = "query"
+	tagHeader = "header"
+)
+
+// bind populates `obj` from the request body, path parameters, query parameters (fields tagged with `form` or
+// `query`) and headers, and runs the struct validation. Failures are returned by `rest.Context.InvalidFields`
+// with a message per field.
+func bind(c *rest.Context, obj interface{}) error {
+	fields := map[string]string{}
+
+	if hasBody(c.Request) {
+		if err := c.Decode(obj); err != nil {
+			e, ok := err.(*errorx.Error)
+			if !ok || e.Code != http.StatusBadRequest || len(e.Fields) == 0 {
+				return err
+			}
+			merge(fields, e.Fields)
+		}
+	}
+
+	query := c.Request.URL.Query()
+	merge(fields, rest.BindValues(obj, tagUri, func(name string) ([]string, bool) {
+		v, ok := c.Params.Get(name)
+		return []string{v}, ok
+	}))
+	for _, tag := range []string{tagForm, tagQuery} {
+		merge(fields, rest.BindValues(obj, tag, func(name string) ([]string, bool) {
+			v, ok := query[name]
+			return v, ok
+		}))
+	}
+	merge(fields, rest.BindValues(obj, tagHeader, func(name string) ([]string, bool) {
+		v, ok := c.Request.Header[http.CanonicalHeaderKey(name)]
+		return v, ok
+	}))
+
+	if len(fields) == 0 && binding.Validator != nil {
+		validationFields(reflect.TypeOf(obj), binding.Validator.ValidateStruct(obj), fields)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return c.InvalidFields("invalid request", fields)
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+func merge(dst, src map[string]string) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// validationFields records a message per field that failed the validation, named as in the request
+func validationFields(t reflect.Type, err error, fields map[string]string) {
+	if err == nil {
+		return
+	}
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		fields["request"] = err.Error()
+		return
+	}
+	for _, fe := range errs {
+		msg := "failed on '" + fe.Tag() + "'"
+		if fe.Param() != "" {
+			msg = fmt.Sprintf("failed on '%s=%s'", fe.Tag(), fe.Param())
+		}
+		fields[fieldPath(t, fe.StructNamespace())] = msg
+	}
+}
+
+// fieldPath converts the validator struct namespace (e.g. `Request.Items[0].Name`) to the request names
+// of the fields (e.g. `items[0].name`)
+func fieldPath(t reflect.Type, namespace string) string {
+	parts := strings.Split(namespace, ".")[1:]
+	var ret []string
+	for _, p := range parts {
+		name, index := p, ""
+		if i := strings.Index(p, "["); i >= 0 {
+			name, index = p[:i], p[i:]
+		}
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			ret = append(ret, p)
+			continue
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			ret = append(ret, p)
+			continue
+		}
+		ret = append(ret, requestName(f)+index)
+		t = f.Type
+	}
+	return strings.Join(ret, ".")
+}
+
+// requestName returns the name of the field in the request, from its binding or json tags
+func requestName(f reflect.StructField) string {
+	for _, tag := range []string{tagUri, tagForm, tagQuery, tagHeader, "json"} {
+		if name := tagName(f, tag); name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func tagName(f reflect.StructField, tag string) string {
+	return strings.Split(f.Tag.Get(tag), ",")[0]
+}
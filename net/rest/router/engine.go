@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/jucardi/go-titan/logx"
 	"github.com/jucardi/go-titan/net/rest/config"
 	"github.com/jucardi/go-titan/net/rest/endpoints"
+	"github.com/jucardi/go-titan/net/rest/openapi"
 	"github.com/jucardi/go-titan/utils/paths"
 )
 
@@ -31,13 +33,27 @@ type IEngine interface {
 	SetAddress(addr ...string) IEngine
 
 	// Run attaches the engine to a http.Server and starts listening and serving HTTP requests using
-	// the defined address with `SetAddress`. It is a shortcut for http.ListenAndServe(addr, engine)
-	// Note: this method will block the calling goroutine indefinitely unless an error happens.
+	// the defined address with `SetAddress`, with the timeouts and limits of the `server` configuration.
+	// The engine is registered as a `shutdown` hook, so it is shut down gracefully when the service is.
+	// Note: this method will block the calling goroutine until the engine is shut down, in which case it
+	// returns nil, or until an error happens.
 	Run() (err error)
 
 	// RunAdmin asynchronously runs the admin engine. Returns an error if the admin port is undefined.
 	RunAdmin() error
 
+	// Shutdown gracefully shuts down the API and admin servers. The engine reports it is no longer ready,
+	// waits for the configured `drain_delay`, stops accepting connections and waits for the in-flight
+	// requests to complete or for the context to be done, whichever happens first.
+	Shutdown(ctx context.Context) error
+
+	// Ready indicates whether the engine is accepting requests. It becomes ready once the API listener is
+	// open, and stops being ready when it starts draining on shutdown.
+	Ready() bool
+
+	// OnReadinessChange registers a callback invoked when the readiness of the engine changes
+	OnReadinessChange(fn func(ready bool)) IEngine
+
 	// NoRoute adds handlers to use when a route is not found. It returns Not Found (404) by default.
 	NoRoute(handlers ...HandlerFunc)
 }
@@ -122,6 +138,7 @@ func bare(contextPath ...string) *engine {
 
 type engine struct {
 	*router
+	lifecycle
 	engine      *gin.Engine
 	adminEngine *gin.Engine
 	contextPath string
@@ -129,6 +146,10 @@ type engine struct {
 	config      config.RestConfig
 	addr        []string
 	adminAddr   []string
+
+	// Handlers executed before the NoRoute handlers
+	noRouteMiddleware []HandlerFunc
+	noRoute           []HandlerFunc
 }
 
 func (r *engine) SetAdminAddress(addr ...string) IEngine {
@@ -150,7 +171,7 @@ func (r *engine) Run() error {
 	routerAddr, _ := r.getAddresses()
 	logx.Info("Listening on: ", routerAddr)
 	endpoints.InfoHandler().AddRouter(paths.Combine(routerAddr...), r.engine)
-	return r.engine.Run(routerAddr...)
+	return r.serve(routerAddr[0], r.engine, true)
 }
 
 func (r *engine) RunAdmin() error {
@@ -166,7 +187,13 @@ func (r *engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 func (r *engine) NoRoute(handlers ...HandlerFunc) {
-	r.engine.NoRoute(mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.noRoute = handlers
+	r.engine.NoRoute(mergeHandlerGroups(r.noRouteMiddleware, r.before, handlers, r.after)...)
+}
+
+func (r *engine) useNoRoute(handlers ...HandlerFunc) {
+	r.noRouteMiddleware = append(r.noRouteMiddleware, handlers...)
+	r.NoRoute(r.noRoute...)
 }
 
 func (r *engine) runAdmin() {
@@ -178,18 +205,18 @@ func (r *engine) runAdmin() {
 
 	if len(adminAddr) == 0 || areAddressesEqual(routerAddr, adminAddr) {
 		logx.Info("Admin endpoints registered on: ", routerAddr)
-		registerAdminRoutes(r.engine)
+		registerAdminRoutes(r.engine, r.engine)
 		return
 	}
 
 	r.adminEngine = gin.New()
-	registerAdminRoutes(r.adminEngine)
+	registerAdminRoutes(r.adminEngine, r.engine)
 	endpoints.InfoHandler().AddRouter(paths.Combine(adminAddr...), r.adminEngine)
 
 	go func() {
 		logx.Info("Admin endpoints registered at: ", adminAddr)
-		if err := r.adminEngine.Run(adminAddr...); err != nil {
-			panic(fmt.Errorf("failed to start admin listener engine, %v", err))
+		if err := r.serve(adminAddr[0], r.adminEngine, false); err != nil {
+			logx.Error("failed to start admin listener, ", err.Error())
 		}
 	}()
 }
@@ -208,10 +235,29 @@ func (r *engine) getAddresses() (routerAddr []string, adminAddr []string) {
 	return
 }
 
-func registerAdminRoutes(r *gin.Engine) {
+// registerAdminRoutes registers the admin endpoints in `r`. `source` is the engine serving the API routes.
+func registerAdminRoutes(r *gin.Engine, source *gin.Engine) {
+	existing := map[string]bool{}
+	for _, route := range r.Routes() {
+		existing[route.Method+" "+route.Path] = true
+	}
+
 	endpoints.InfoHandler().RegisterEndpoint(r)
 	endpoints.AddMetrics(r)
 	endpoints.AddLogLevel(r)
+	endpoints.AddCircuits(r)
+	endpoints.AddHealth(r)
+	endpoints.AddOpenAPI(r, source)
+
+	// The admin routes are not part of the API when they share its engine
+	if r != source {
+		return
+	}
+	for _, route := range r.Routes() {
+		if !existing[route.Method+" "+route.Path] {
+			openapi.Describe(route.Method, route.Path, &openapi.RouteDoc{Hidden: true}, nil)
+		}
+	}
 }
 
 func areAddressesEqual(a1 []string, a2 []string) bool {
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+// Handle creates a handler that binds the request into a `Req`, invokes `fn` and sends the returned `Resp`
+// or error using the configured encoder. The `context.Context` passed to `fn` is the `*rest.Context` of the
+// request.
+//
+// `Req` is populated from
+//   - The body, decoded as Protobuf if the Content-Type is `application/x-protobuf` or as JSON otherwise.
+//     Protobuf messages are decoded from JSON using `protojson`.
+//   - The path parameters, query parameters and headers into the fields tagged with `uri`, `form` or `query`,
+//     and `header` respectively.
+//
+// The request is then validated using the `binding` tags. Bind and validation failures are sent as
+// 400 Bad Request with a message per field in `Fields`.
+//
+// The response is sent with the provided status, or 200 OK if not provided. A nil `Resp` sends an empty body.
+//
+//	r.POST("/orders/:id", router.Handle(svc.UpdateOrder))
+func Handle[Req any, Resp any](fn func(ctx context.Context, req *Req) (*Resp, error), status ...int) HandlerFunc {
+	return func(c *rest.Context) {
+		req := new(Req)
+		if err := bind(c, req); err != nil {
+			sendBindError(c, err)
+			return
+		}
+		resp, err := fn(c, req)
+		if resp == nil {
+			c.StatusOrErr(err, status...)
+			return
+		}
+		c.SendOrErr(resp, err, status...)
+	}
+}
+
+// HandleNoContent creates a handler that binds the request into a `Req` as `Handle` does, invokes `fn` and
+// responds with the provided status, or 204 No Content if not provided, unless `fn` returns an error.
+func HandleNoContent[Req any](fn func(ctx context.Context, req *Req) error, status ...int) HandlerFunc {
+	if len(status) == 0 {
+		status = []int{http.StatusNoContent}
+	}
+	return func(c *rest.Context) {
+		req := new(Req)
+		if err := bind(c, req); err != nil {
+			sendBindError(c, err)
+			return
+		}
+		c.StatusOrErr(fn(c, req), status...)
+	}
+}
+
+func sendBindError(c *rest.Context, err error) {
+	if c.IsAborted() {
+		return
+	}
+	c.SendError(err)
+}
@@ -0,0 +1,123 @@
+package router_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/router"
+	"github.com/jucardi/go-titan/utils/testutils"
+)
+
+type item struct {
+	Name     string `json:"name" binding:"required"`
+	Quantity int    `json:"quantity" binding:"min=1"`
+}
+
+type updateOrderRequest struct {
+	Id     string  `uri:"id" json:"-"`
+	Notify bool    `form:"notify" json:"-"`
+	Reason string  `query:"reason" json:"-"`
+	Tenant string  `header:"X-Tenant" json:"-" binding:"required"`
+	Items  []*item `json:"items" binding:"required,dive"`
+}
+
+type updateOrderResponse struct {
+	Id     string `json:"id"`
+	Tenant string `json:"tenant"`
+	Notify bool   `json:"notify"`
+	Reason string `json:"reason"`
+	Items  int    `json:"items"`
+}
+
+type deleteOrderRequest struct {
+	Id string `uri:"id"`
+}
+
+func init() {
+	testutils.Prepare(router.Bare(), func(r router.IRouter) {
+		r.PUT("/orders/:id", router.Handle(func(ctx context.Context, req *updateOrderRequest) (*updateOrderResponse, error) {
+			return &updateOrderResponse{Id: req.Id, Tenant: req.Tenant, Notify: req.Notify, Reason: req.Reason, Items: len(req.Items)}, nil
+		}))
+		r.POST("/errors", router.Handle(func(ctx context.Context, req *errorx.Error) (*errorx.Error, error) {
+			return nil, req
+		}))
+		r.DELETE("/orders/:id", router.HandleNoContent(func(ctx context.Context, req *deleteOrderRequest) error {
+			if req.Id != "o-1" {
+				return errorx.NewNotFound("order not found")
+			}
+			return nil
+		}))
+	})
+}
+
+func TestHandleBinding(t *testing.T) {
+	req := testutils.RequestFromBytes(http.MethodPut, "/orders/o-1?notify=true&reason=restock", []byte(`{"items":[{"name":"book","quantity":2}]}`))
+	req.Header.Set(rest.HeaderContentType, rest.ContentTypeJson)
+	req.Header.Set("X-Tenant", "acme")
+
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusOK, res.GetCode())
+
+	resp := &updateOrderResponse{}
+	assert.NoError(t, res.Unmarshal(resp))
+	assert.Equal(t, &updateOrderResponse{Id: "o-1", Tenant: "acme", Notify: true, Reason: "restock", Items: 1}, resp)
+}
+
+func TestHandleValidation(t *testing.T) {
+	req := testutils.RequestFromBytes(http.MethodPut, "/orders/o-1?notify=maybe", []byte(`{"items":[{"quantity":2}]}`))
+	req.Header.Set(rest.HeaderContentType, rest.ContentTypeJson)
+
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusBadRequest, res.GetCode())
+	err := res.Unmarshal(&updateOrderResponse{}).(*errorx.Error)
+	assert.Equal(t, map[string]string{"notify": "expected a boolean"}, err.Fields)
+
+	req = testutils.RequestFromBytes(http.MethodPut, "/orders/o-1", []byte(`{"items":[{"quantity":0}]}`))
+	req.Header.Set(rest.HeaderContentType, rest.ContentTypeJson)
+
+	res = testutils.Serve(req)
+	assert.Equal(t, http.StatusBadRequest, res.GetCode())
+	err = res.Unmarshal(&updateOrderResponse{}).(*errorx.Error)
+	assert.Equal(t, map[string]string{
+		"X-Tenant":          "failed on 'required'",
+		"items[0].name":     "failed on 'required'",
+		"items[0].quantity": "failed on 'min=1'",
+	}, err.Fields)
+
+	req = testutils.RequestFromBytes(http.MethodPut, "/orders/o-1", []byte("id,sku\no-1,a"))
+	req.Header.Set(rest.HeaderContentType, "text/csv")
+	assert.Equal(t, http.StatusUnsupportedMediaType, testutils.Serve(req).GetCode())
+}
+
+func TestHandleProto(t *testing.T) {
+	req := testutils.RequestFromProtoObj(http.MethodPost, "/errors", &errorx.Error{Code: http.StatusConflict, Message: "duplicated"})
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusConflict, res.GetCode())
+
+	err := res.Unmarshal(&errorx.Error{}).(*errorx.Error)
+	assert.Equal(t, "duplicated", err.Message)
+}
+
+func TestHandleErrorFields(t *testing.T) {
+	// The fields of errors which don't describe the request, e.g. returned by a downstream service
+	req := testutils.RequestFromProtoObj(http.MethodPost, "/errors", &errorx.Error{
+		Code:    http.StatusBadGateway,
+		Message: "upstream failed",
+		Fields:  map[string]string{"url": "http://orders/v1", "headers": "Authorization:Bearer secret"},
+	})
+	res := testutils.Serve(req)
+	assert.Equal(t, http.StatusBadGateway, res.GetCode())
+	assert.Nil(t, res.Unmarshal(&errorx.Error{}).(*errorx.Error).Fields)
+}
+
+func TestHandleNoContent(t *testing.T) {
+	res := testutils.Serve(testutils.RequestNoBody(http.MethodDelete, "/orders/o-1"))
+	assert.Equal(t, http.StatusNoContent, res.GetCode())
+
+	res = testutils.Serve(testutils.RequestNoBody(http.MethodDelete, "/orders/o-2"))
+	assert.Equal(t, http.StatusNotFound, res.GetCode())
+}
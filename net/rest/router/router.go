@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/rest/middleware/auth"
 )
 
 type router struct {
@@ -34,6 +36,26 @@ func (r *router) Version(apiVersion int) IRouter {
 	return r.Group(version)
 }
 
+func (r *router) Secured(scopes ...string) IRoutes {
+	return r.SecuredBy(authx.Requirement{Scopes: scopes})
+}
+
+func (r *router) SecuredBy(req authx.Requirement) IRoutes {
+	key := req.String()
+	if s, ok := r.defaultSecured[key]; ok {
+		return s
+	}
+
+	s := &routes{
+		r:         r.group,
+		parent:    r.routes,
+		security:  &req,
+		authorize: auth.Authorize(req),
+	}
+	r.defaultSecured[key] = s
+	return s
+}
+
 func wrap(r *gin.RouterGroup) *router {
 	return &router{
 		routes:         wrapRoutes(r),
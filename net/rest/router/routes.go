@@ -2,14 +2,28 @@ package router
 
 import (
 	"net/http"
+	"path"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/net/authx"
+	"github.com/jucardi/go-titan/net/rest/endpoints"
+	"github.com/jucardi/go-titan/net/rest/openapi"
+	"github.com/jucardi/go-titan/net/rest/ws"
 )
 
 type routes struct {
 	before []HandlerFunc
 	after  []HandlerFunc
 	r      gin.IRoutes
+
+	// Secured routes share the routes they were created from, running their handlers as well
+	parent    *routes
+	security  *authx.Requirement
+	authorize HandlerFunc
+
+	// Documentation of the routes registered through these routes
+	doc *openapi.RouteDoc
 }
 
 func (r *routes) Before(handler ...HandlerFunc) IRoutes {
@@ -26,53 +40,61 @@ func (r *routes) After(handler ...HandlerFunc) IRoutes {
 	return r
 }
 
+func (r *routes) Doc(doc openapi.RouteDoc) IRoutes {
+	ret := *r
+	ret.before = append([]HandlerFunc{}, r.before...)
+	ret.after = append([]HandlerFunc{}, r.after...)
+	ret.doc = &doc
+	return &ret
+}
+
 func (r *routes) Use(handlers ...HandlerFunc) IRoutes {
 	r.r.Use(convertHandlers(handlers)...)
 	return r
 }
 
 func (r *routes) Handle(method string, relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.Handle(method, relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.Handle(method, relativePath, r.handlers(method, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) Any(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.Any(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.Any(relativePath, r.handlers(endpoints.MethodAny, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) GET(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.GET(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.GET(relativePath, r.handlers(http.MethodGet, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) POST(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.POST(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.POST(relativePath, r.handlers(http.MethodPost, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) DELETE(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.DELETE(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.DELETE(relativePath, r.handlers(http.MethodDelete, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) PATCH(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.PATCH(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.PATCH(relativePath, r.handlers(http.MethodPatch, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) PUT(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.PUT(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.PUT(relativePath, r.handlers(http.MethodPut, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) OPTIONS(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.OPTIONS(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.OPTIONS(relativePath, r.handlers(http.MethodOptions, relativePath, handlers)...)
 	return r
 }
 
 func (r *routes) HEAD(relativePath string, handlers ...HandlerFunc) IRoutes {
-	r.r.HEAD(relativePath, mergeHandlerGroups(r.before, handlers, r.after)...)
+	r.r.HEAD(relativePath, r.handlers(http.MethodHead, relativePath, handlers)...)
 	return r
 }
 
@@ -91,6 +113,37 @@ func (r *routes) StaticFS(relativePath string, fs http.FileSystem) IRoutes {
 	return r
 }
 
+func (r *routes) WebSocket(relativePath string, handler ws.Handler, handlers ...HandlerFunc) IRoutes {
+	return r.GET(relativePath, append(handlers, ws.Upgrade(handler))...)
+}
+
+// handlers returns the handlers chain of a route and registers its security requirement and documentation, if any
+func (r *routes) handlers(method, relativePath string, handlers []HandlerFunc) []gin.HandlerFunc {
+	if r.security != nil {
+		endpoints.SetRouteSecurity(method, r.fullPath(relativePath), r.security.String())
+	}
+	if r.security != nil || r.doc != nil {
+		openapi.Describe(method, r.fullPath(relativePath), r.doc, r.security)
+	}
+	if r.parent == nil {
+		return mergeHandlerGroups(r.before, handlers, r.after)
+	}
+	// The parent handlers run first, so the principal is authenticated by the time it is authorized
+	return mergeHandlerGroups(r.parent.before, []HandlerFunc{r.authorize}, r.before, handlers, r.parent.after, r.after)
+}
+
+func (r *routes) fullPath(relativePath string) string {
+	group, ok := r.r.(*gin.RouterGroup)
+	if !ok || relativePath == "" {
+		return relativePath
+	}
+	ret := path.Join(group.BasePath(), relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(ret, "/") {
+		ret += "/"
+	}
+	return ret
+}
+
 func wrapRoutes(r gin.IRoutes) *routes {
 	return &routes{r: r}
 }
@@ -0,0 +1,32 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/openapi"
+	"github.com/jucardi/go-titan/net/rest/router"
+)
+
+func TestDocRoutesHandlers(t *testing.T) {
+	mark := func(name string) router.HandlerFunc {
+		return func(c *rest.Context) { c.Writer.Header().Add("X-Handler", name) }
+	}
+	respond := func(c *rest.Context) { c.Status(http.StatusOK) }
+
+	r := router.Bare()
+	r.Before(mark("a"), mark("b"), mark("c"))
+	one := r.Doc(openapi.RouteDoc{Summary: "one"})
+	two := r.Doc(openapi.RouteDoc{Summary: "two"})
+	one.Before(mark("one"))
+	two.Before(mark("two"))
+	one.GET("/one", respond)
+	two.GET("/two", respond)
+
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/one", nil))
+	assert.Equal(t, []string{"a", "b", "c", "one"}, res.Header().Values("X-Handler"))
+}
@@ -0,0 +1,203 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/components/health"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/tlsx"
+	"github.com/jucardi/go-titan/utils/shutdown"
+)
+
+const (
+	// EngineCheckName is the name of the readiness check that fails while the engine is not serving
+	EngineCheckName = "rest-engine"
+
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+// lifecycle keeps the HTTP servers owned by the engine and its readiness
+type lifecycle struct {
+	servers   []*http.Server
+	ready     bool
+	draining  bool
+	stopped   bool
+	listeners []func(ready bool)
+	hookOnce  sync.Once
+	mux       sync.RWMutex
+}
+
+func (r *engine) Ready() bool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.ready
+}
+
+func (r *engine) OnReadinessChange(fn func(ready bool)) IEngine {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.listeners = append(r.listeners, fn)
+	return r
+}
+
+// Shutdown stops the engine gracefully. It reports the engine as not ready, waits for the configured drain
+// delay, and then stops accepting connections and waits for the in-flight requests to complete, or for the
+// context to be done. If the context is done first, the error is returned and the shutdown can be retried.
+func (r *engine) Shutdown(ctx context.Context) error {
+	r.mux.Lock()
+	if r.stopped {
+		r.mux.Unlock()
+		return nil
+	}
+	retry := r.draining
+	r.draining = true
+	servers := r.servers
+	r.mux.Unlock()
+
+	r.setReady(false)
+
+	if delay := millis(r.config.Server.DrainDelay); delay > 0 && len(servers) > 0 && !retry {
+		logx.Infof("draining, waiting %s before closing the listeners", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(servers))
+	for i, s := range servers {
+		wg.Add(1)
+		go func(i int, s *http.Server) {
+			defer wg.Done()
+			errs[i] = s.Shutdown(ctx)
+		}(i, s)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	r.mux.Lock()
+	r.stopped = true
+	r.mux.Unlock()
+	logx.Info("HTTP servers stopped")
+	return nil
+}
+
+// serve listens on the provided address and serves HTTP requests using the handler until the server is
+// shut down, in which case returns nil
+func (r *engine) serve(addr string, handler http.Handler, main bool) error {
+	tlsCfg := r.config.TLS
+	if !main {
+		tlsCfg = r.config.AdminTLS
+	}
+
+	srv := r.newServer(addr, handler)
+	if tlsCfg.Enabled {
+		loader, err := tlsx.New(tlsCfg)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = loader.TLSConfig()
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	r.mux.Lock()
+	if r.draining {
+		r.mux.Unlock()
+		_ = ln.Close()
+		return nil
+	}
+	r.servers = append(r.servers, srv)
+	r.mux.Unlock()
+
+	r.hookOnce.Do(func() {
+		shutdown.AddHook(r.shutdownHook, "rest-server")
+	})
+
+	if main {
+		health.Register(health.Check{
+			Name:     EngineCheckName,
+			Fn:       r.readinessCheck,
+			CacheTTL: -1,
+		})
+		r.setReady(true)
+	}
+
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (r *engine) newServer(addr string, handler http.Handler) *http.Server {
+	cfg := r.config.Server
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       millis(cfg.ReadTimeout),
+		ReadHeaderTimeout: millis(cfg.ReadHeaderTimeout),
+		WriteTimeout:      millis(cfg.WriteTimeout),
+		IdleTimeout:       millis(cfg.IdleTimeout),
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+func (r *engine) shutdownHook() error {
+	timeout := millis(r.config.Server.ShutdownTimeout)
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return r.Shutdown(ctx)
+}
+
+func (r *engine) setReady(ready bool) {
+	r.mux.Lock()
+	if r.ready == ready || (ready && r.draining) {
+		r.mux.Unlock()
+		return
+	}
+	r.ready = ready
+	listeners := r.listeners
+	r.mux.Unlock()
+
+	if ready {
+		logx.Info("engine is ready")
+	} else {
+		logx.Info("engine is not ready, draining requests")
+	}
+	for _, fn := range listeners {
+		fn(ready)
+	}
+}
+
+func (r *engine) readinessCheck(context.Context) error {
+	if r.Ready() {
+		return nil
+	}
+	return errors.New("the engine is draining")
+}
+
+func millis(ms int64) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
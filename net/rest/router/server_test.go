@@ -0,0 +1,117 @@
+package router_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/components/health"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/router"
+)
+
+func TestGracefulShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	readiness := make(chan bool, 2)
+
+	r := router.Bare()
+	r.SetAddress(addr)
+	r.OnReadinessChange(func(ready bool) { readiness <- ready })
+	r.GET("/slow", func(c *rest.Context) {
+		close(started)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- r.Run() }()
+	assert.True(t, <-readiness)
+	assert.True(t, r.Ready())
+	assert.Equal(t, health.StatusUp, health.Readiness(context.Background()).Checks[router.EngineCheckName].Status)
+
+	// An in-flight request completes while the engine shuts down
+	responses := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get(fmt.Sprintf("http://%s/slow", addr))
+		assert.NoError(t, err)
+		responses <- res
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- r.Shutdown(context.Background()) }()
+	assert.False(t, <-readiness)
+	assert.False(t, r.Ready())
+	assert.Equal(t, health.StatusDown, health.Readiness(context.Background()).Status)
+
+	close(release)
+	res := <-responses
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NoError(t, <-shutdownErr)
+
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+}
+
+func TestShutdownRetry(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	readiness := make(chan bool, 2)
+
+	r := router.Bare()
+	r.SetAddress(addr)
+	r.OnReadinessChange(func(ready bool) { readiness <- ready })
+	r.GET("/slow", func(c *rest.Context) {
+		close(started)
+		<-release
+		c.String(http.StatusOK, "done")
+	})
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- r.Run() }()
+	assert.True(t, <-readiness)
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get(fmt.Sprintf("http://%s/slow", addr))
+		assert.NoError(t, err)
+		responses <- res
+	}()
+	<-started
+
+	// The in-flight request outlives the first attempt, which can be retried
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, r.Shutdown(ctx))
+	assert.NoError(t, <-stopped)
+
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- r.Shutdown(context.Background()) }()
+	select {
+	case <-shutdownErr:
+		t.Fatal("the retry returned before the in-flight request completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, http.StatusOK, (<-responses).StatusCode)
+	assert.NoError(t, <-shutdownErr)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-titan/net/authx"
 	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/openapi"
+	"github.com/jucardi/go-titan/net/rest/ws"
 )
 
 type IRoutes interface {
@@ -24,8 +27,30 @@ type IRoutes interface {
 	Static(string, string) IRoutes
 	StaticFS(string, http.FileSystem) IRoutes
 
+	// WebSocket registers a GET route which upgrades the requests to WebSocket connections handled by the
+	// provided handler, after running the optional handlers. See `ws.Upgrade`
+	//
+	//    r.Secured("orders:read").WebSocket("/orders/feed", func(conn *ws.Conn) {
+	//        hub.Join(conn)
+	//        <-conn.Done()
+	//    })
+	//
+	WebSocket(relativePath string, handler ws.Handler, handlers ...HandlerFunc) IRoutes
+
 	Before(...HandlerFunc) IRoutes
 	After(...HandlerFunc) IRoutes
+
+	// Doc returns routes that document the routes registered through them with the provided metadata,
+	// used to generate the OpenAPI document served at the admin `/openapi.json`
+	//
+	//    r.Doc(openapi.RouteDoc{
+	//        Summary:   "Creates an order",
+	//        Tags:      []string{"orders"},
+	//        Request:   &pb.CreateOrderRequest{},
+	//        Responses: map[int]interface{}{201: &pb.Order{}, 409: nil},
+	//    }).POST("/orders", createOrder)
+	//
+	Doc(doc openapi.RouteDoc) IRoutes
 }
 
 // IRouter defines the engine contracts
@@ -41,6 +66,20 @@ type IRouter interface {
 	// Group creates a new engine group. You should add all the routes that have common middlwares or the same path prefix.
 	// For example, all the routes that use a common middlware for authorization could be grouped.
 	Group(relativePath string, handlers ...HandlerFunc) IRouter
+
+	// Secured returns the routes of this router that require an authenticated principal granted all the
+	// provided scopes. Requests are authenticated by the `auth` middleware, see `UseCommonMiddleware`.
+	//
+	//    r.Secured("orders:write").POST("/orders", createOrder)
+	//
+	Secured(scopes ...string) IRoutes
+
+	// SecuredBy returns the routes of this router that require an authenticated principal satisfying the
+	// provided requirement of scopes, roles and policy expression. See `authx.CompilePolicy`
+	//
+	//    r.SecuredBy(authx.Requirement{Roles: []string{"admin"}}).DELETE("/orders/:id", deleteOrder)
+	//
+	SecuredBy(req authx.Requirement) IRoutes
 }
 
 // HandlerFunc defines the handler used by any middleware as return value.
@@ -5,13 +5,20 @@ import (
 	"github.com/jucardi/go-streams/streams"
 	"github.com/jucardi/go-titan/net/rest"
 	"github.com/jucardi/go-titan/net/rest/config"
+	"github.com/jucardi/go-titan/net/rest/middleware/auth"
+	"github.com/jucardi/go-titan/net/rest/middleware/cache"
 	"github.com/jucardi/go-titan/net/rest/middleware/cid"
-	"github.com/jucardi/go-titan/net/rest/middleware/headers"
+	"github.com/jucardi/go-titan/net/rest/middleware/compress"
+	"github.com/jucardi/go-titan/net/rest/middleware/cors"
+	"github.com/jucardi/go-titan/net/rest/middleware/idempotency"
 	"github.com/jucardi/go-titan/net/rest/middleware/limits"
 	"github.com/jucardi/go-titan/net/rest/middleware/logging"
 	"github.com/jucardi/go-titan/net/rest/middleware/metrics"
 	"github.com/jucardi/go-titan/net/rest/middleware/prometheus"
+	"github.com/jucardi/go-titan/net/rest/middleware/ratelimit"
 	"github.com/jucardi/go-titan/net/rest/middleware/recovery"
+	"github.com/jucardi/go-titan/net/rest/middleware/secure"
+	"github.com/jucardi/go-titan/net/rest/middleware/timeout"
 )
 
 var (
@@ -19,17 +26,39 @@ var (
 )
 
 // UseCommonMiddleware applies the common middleware we use in microservices to the specified engine.
-// The middleware added is Recover, Logging, Handler and Correlation ID
+// The middleware added is Recover, Logging, Handler, Request Timeout, Request Decompression, CORS,
+// Correlation ID, and the opt-in Response Compression, Security Headers, Authentication, Rate Limiting,
+// Idempotency Keys and Response Caching (only effective if enabled in the configuration)
+//
+// If the router is an engine with a context path, CORS is also applied to unmatched routes so preflight
+// requests for routes that do not register OPTIONS are answered, and the metrics record them under the
+// `NO_ROUTE` route.
 func UseCommonMiddleware(router IRouter) {
 	router.Use(
 		limits.Handler,
 		logging.Handler,
 		metrics.Handler,
+		prometheus.Handler,
 		recovery.Handler,
-		headers.Handler,
+		timeout.Handler,
+		compress.Handler,
+		cors.Handler,
+		secure.Handler,
 		cid.Handler,
-		prometheus.Handler,
+		auth.Handler,
+		ratelimit.Handler,
+		idempotency.Handler,
+		cache.Handler,
 	)
+	if e, ok := router.(*engine); ok {
+		// The middleware of the root group is global, and gin runs it for unmatched routes once the NoRoute
+		// handlers are rebuilt
+		var handlers []HandlerFunc
+		if e.group != &e.engine.RouterGroup {
+			handlers = []HandlerFunc{metrics.Handler, prometheus.Handler, cors.Handler}
+		}
+		e.useNoRoute(handlers...)
+	}
 }
 
 func init() {
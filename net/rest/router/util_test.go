@@ -0,0 +1,35 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest/middleware/metrics"
+	"github.com/jucardi/go-titan/net/rest/router"
+)
+
+func TestCommonMiddlewareNoRoute(t *testing.T) {
+	for _, r := range []router.IEngine{router.Bare(), router.Bare("/api")} {
+		router.UseCommonMiddleware(r)
+
+		before := noRouteCount()
+		req, _ := http.NewRequest(http.MethodGet, r.ContextPath()+"/nope", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, []string{"Origin"}, res.Header().Values("Vary"))
+		assert.Equal(t, before+1, noRouteCount())
+	}
+}
+
+// noRouteCount returns the count of unmatched requests, consolidated as 4xx by the metrics
+func noRouteCount() int64 {
+	if stats, ok := metrics.GetStats()[metrics.NoRoute]; ok && stats.RouteData[http.StatusBadRequest] != nil {
+		return stats.RouteData[http.StatusBadRequest].Count
+	}
+	return 0
+}
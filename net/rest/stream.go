@@ -0,0 +1,231 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	headerCacheControl    = "Cache-Control"
+	headerAccelBuffering  = "X-Accel-Buffering"
+	streamErrorEvent      = "error"
+	streamHeartbeatPacket = ": heartbeat\n\n"
+)
+
+// Event is a Server-Sent Event
+type Event struct {
+	// ID is the event ID, sent back by the client in the `Last-Event-ID` header when reconnecting
+	ID string
+
+	// Event is the event type. Clients dispatch the events without a type as `message`
+	Event string
+
+	// Data is the event payload. Strings and byte slices are sent as is, any other value is encoded as JSON,
+	// using `protojson` for proto messages
+	Data interface{}
+
+	// Retry indicates how long the client should wait before reconnecting
+	Retry time.Duration
+}
+
+// StreamWriter sends the messages of a streamed response. It is safe for concurrent use.
+type StreamWriter interface {
+	// Send sends a message with the provided data. In Server-Sent Events streams it is sent as an event
+	// without type, see `Event` for how the data is encoded. In NDJSON streams it is encoded as a JSON line.
+	Send(data interface{}) error
+
+	// Event sends a Server-Sent Event. In NDJSON streams only the data is sent.
+	Event(e *Event) error
+
+	// Done returns a channel which is closed when the client disconnects or the request deadline is
+	// exceeded, after which any write fails.
+	Done() <-chan struct{}
+
+	// Context returns the request-scoped context
+	Context() context.Context
+}
+
+// Stream sends a Server-Sent Events (`text/event-stream`) response, invoking `fn` to write the events. Each
+// event is flushed to the client as it is sent, and heartbeat comments are sent every `stream_heartbeat`
+// while the stream is idle.
+//
+// `fn` should return when `Done` is closed, which happens when the client disconnects or the request
+// deadline set by the timeout middleware is exceeded, so long-lived streams need a long enough timeout.
+// If `fn` returns an error while the client is connected, it is sent as an `error` event.
+//
+//	c.Stream(func(w rest.StreamWriter) error {
+//		for {
+//			select {
+//			case <-w.Done():
+//				return nil
+//			case order := <-updates:
+//				if err := w.Event(&rest.Event{ID: order.Id, Event: "order", Data: order}); err != nil {
+//					return err
+//				}
+//			}
+//		}
+//	})
+func (c *Context) Stream(fn func(w StreamWriter) error) {
+	c.stream(false, fn)
+}
+
+// StreamNDJSON sends a newline delimited JSON (`application/x-ndjson`) response, invoking `fn` to write the
+// messages, which are encoded as JSON lines and flushed to the client as they are sent. See `Stream`.
+//
+// If `fn` returns an error while the client is connected, it is sent as the last line.
+func (c *Context) StreamNDJSON(fn func(w StreamWriter) error) {
+	c.stream(true, fn)
+}
+
+// LastEventID returns the ID of the last event received by a reconnecting Server-Sent Events client
+func (c *Context) LastEventID() string {
+	return c.GetHeader(HeaderLastEventID)
+}
+
+func (c *Context) stream(ndjson bool, fn func(w StreamWriter) error) {
+	h := c.Writer.Header()
+	if ndjson {
+		h.Set(HeaderContentType, ContentTypeNdjson)
+	} else {
+		h.Set(HeaderContentType, ContentTypeEventStream)
+	}
+	h.Set(headerCacheControl, "no-cache")
+	h.Set(headerAccelBuffering, "no")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	w := &streamWriter{c: c, ndjson: ndjson}
+	stop := make(chan struct{})
+	wg := sync.WaitGroup{}
+
+	if interval := time.Duration(config.Rest().Response.StreamHeartbeat) * time.Millisecond; !ndjson && interval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.heartbeat(interval, stop)
+		}()
+	}
+
+	err := fn(w)
+	close(stop)
+	wg.Wait()
+
+	if err == nil {
+		return
+	}
+	_ = c.Error(err)
+	if c.Err() != nil {
+		return
+	}
+
+	ex := sanitizeError(c.wrapError(err))
+	if ndjson {
+		err = w.Send(ex)
+	} else {
+		err = w.Event(&Event{Event: streamErrorEvent, Data: ex})
+	}
+	if err != nil {
+		logx.Warn("failed to send stream error, ", err.Error())
+	}
+}
+
+type streamWriter struct {
+	c      *Context
+	ndjson bool
+	mux    sync.Mutex
+}
+
+func (w *streamWriter) Send(data interface{}) error {
+	return w.Event(&Event{Data: data})
+}
+
+func (w *streamWriter) Event(e *Event) error {
+	buf := &bytes.Buffer{}
+	if w.ndjson {
+		data, err := marshalJson(e.Data)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	} else {
+		data, err := eventData(e.Data)
+		if err != nil {
+			return err
+		}
+		if err := sse.Encode(buf, sse.Event{Id: e.ID, Event: e.Event, Retry: uint(e.Retry / time.Millisecond), Data: data}); err != nil {
+			return err
+		}
+	}
+	return w.write(buf.Bytes())
+}
+
+func (w *streamWriter) Done() <-chan struct{} {
+	return w.c.Done()
+}
+
+func (w *streamWriter) Context() context.Context {
+	return w.c.Ctx()
+}
+
+func (w *streamWriter) heartbeat(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-w.Done():
+			return
+		case <-ticker.C:
+			if err := w.write([]byte(streamHeartbeatPacket)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (w *streamWriter) write(data []byte) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	// The client disconnected or the request deadline was exceeded
+	if err := w.c.Err(); err != nil {
+		return err
+	}
+	if _, err := w.c.Writer.Write(data); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+func eventData(data interface{}) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	ret, err := marshalJson(data)
+	return string(ret), err
+}
+
+// marshalJson encodes the data as single-line JSON, using `protojson` for proto messages
+func marshalJson(data interface{}) ([]byte, error) {
+	if msg, ok := data.(proto.Message); ok {
+		return protojson.Marshal(msg)
+	}
+	return json.Marshal(data)
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest/config"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestStreamEvents(t *testing.T) {
+	c, res := newStreamContext(context.Background())
+	c.Stream(func(w StreamWriter) error {
+		assert.NoError(t, w.Send("hello"))
+		assert.NoError(t, w.Event(&Event{ID: "1", Event: "order", Data: map[string]string{"id": "o-1"}, Retry: 3 * time.Second}))
+		return errorx.NewConflict("stream interrupted")
+	})
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, ContentTypeEventStream, res.Header().Get(HeaderContentType))
+	assert.True(t, res.Flushed)
+
+	body := res.Body.String()
+	assert.True(t, strings.HasPrefix(body, "data:hello\n\nid:1\nevent:order\nretry:3000\ndata:{\"id\":\"o-1\"}\n\n"))
+	assert.True(t, strings.Contains(body, "event:error\ndata:{\"code\":409"))
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	c, res := newStreamContext(context.Background())
+	c.StreamNDJSON(func(w StreamWriter) error {
+		_ = w.Send(map[string]int{"n": 1})
+		_ = w.Event(&Event{ID: "ignored", Data: map[string]int{"n": 2}})
+		_ = w.Send(durationpb.New(1500 * time.Millisecond))
+		return nil
+	})
+
+	assert.Equal(t, ContentTypeNdjson, res.Header().Get(HeaderContentType))
+	assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n\"1.500s\"\n", res.Body.String())
+}
+
+func TestStreamHeartbeatAndDisconnect(t *testing.T) {
+	heartbeat := config.Rest().Response.StreamHeartbeat
+	config.Rest().Response.StreamHeartbeat = 5
+	defer func() { config.Rest().Response.StreamHeartbeat = heartbeat }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, res := newStreamContext(ctx)
+	c.Stream(func(w StreamWriter) error {
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+		<-w.Done()
+		assert.Error(t, w.Send("lost"))
+		return context.Canceled
+	})
+
+	body := res.Body.String()
+	assert.True(t, strings.HasPrefix(body, streamHeartbeatPacket))
+	assert.False(t, strings.Contains(body, "lost"))
+	assert.False(t, strings.Contains(body, "event:error"))
+}
+
+func newStreamContext(ctx context.Context) (*Context, *httptest.ResponseRecorder) {
+	res := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(res)
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	return NewContext(c, false), res
+}
@@ -0,0 +1,14 @@
+package rest
+
+import (
+	"github.com/jucardi/go-titan/net/tlsx"
+)
+
+// ClientIdentity returns the identity of the verified client certificate when the request was received
+// over mutual TLS, or nil if the client did not present a certificate verified against the client CA.
+func (c *Context) ClientIdentity() *tlsx.Identity {
+	if c.Request == nil {
+		return nil
+	}
+	return tlsx.PeerIdentity(c.Request.TLS)
+}
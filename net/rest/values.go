@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	timeType       = reflect.TypeOf(time.Time{})
+	fileHeaderType = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// BindValues assigns the fields of the struct pointed by `obj` tagged with `tag` (e.g. `form:"page"`) from
+// the values returned by `lookup` for the tag name. Slices are populated from repeated values or comma
+// separated values. Returns a message per field that failed the conversion, keyed by the tag name.
+func BindValues(obj interface{}, tag string, lookup func(name string) ([]string, bool)) map[string]string {
+	fields := map[string]string{}
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+	walkTagged(v, tag, func(name string, fv reflect.Value) {
+		values, ok := lookup(name)
+		if !ok || len(values) == 0 {
+			return
+		}
+		if err := setValues(fv, values); err != nil {
+			fields[name] = err.Error()
+		}
+	})
+	return fields
+}
+
+// bindFiles assigns the `*multipart.FileHeader` and `[]*multipart.FileHeader` fields tagged with `form`
+func bindFiles(obj interface{}, files map[string][]*multipart.FileHeader) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	walkTagged(v, tagForm, func(name string, fv reflect.Value) {
+		f := files[name]
+		if len(f) == 0 {
+			return
+		}
+		switch {
+		case fv.Type() == fileHeaderType:
+			fv.Set(reflect.ValueOf(f[0]))
+		case fv.Kind() == reflect.Slice && fv.Type().Elem() == fileHeaderType:
+			fv.Set(reflect.ValueOf(f))
+		}
+	})
+}
+
+func walkTagged(v reflect.Value, tag string, fn func(name string, fv reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			walkTagged(v.Field(i), tag, fn)
+			continue
+		}
+		if name := strings.Split(f.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+			fn(name, v.Field(i))
+		}
+	}
+}
+
+func setValues(v reflect.Value, values []string) error {
+	if v.Type() == fileHeaderType || (v.Kind() == reflect.Slice && v.Type().Elem() == fileHeaderType) {
+		// Files are assigned by bindFiles
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValues(v.Elem(), values)
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(v.Type(), 0, len(values))
+		for _, s := range values {
+			for _, x := range strings.Split(s, ",") {
+				item := reflect.New(v.Type().Elem()).Elem()
+				if err := setString(item, x); err != nil {
+					return err
+				}
+				slice = reflect.Append(slice, item)
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	return setString(v, values[0])
+}
+
+func setString(v reflect.Value, s string) error {
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("expected a duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return errors.New("expected an RFC 3339 timestamp")
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("expected a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected a positive integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("expected a number")
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type().String())
+	}
+	return nil
+}
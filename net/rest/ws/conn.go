@@ -0,0 +1,313 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/jucardi/go-titan/net/authx"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrClosed is returned when sending or receiving through a closed connection
+var ErrClosed = errors.New("websocket connection closed")
+
+// Conn is an upgraded WebSocket connection.
+//
+// Messages are typed: `Send` encodes values as JSON text messages, or as binary Protobuf messages if the
+// `proto` subprotocol was negotiated, and `Receive` decodes text messages as JSON and binary messages as
+// Protobuf. Strings and byte slices are sent as text and binary messages as is.
+//
+// Pings are sent periodically to keep the connection alive, and the connection is closed if the client
+// does not answer in time. `Send` and `Close` are safe for concurrent use, `Receive` must be called from
+// a single goroutine.
+type Conn struct {
+	id        string
+	ws        *websocket.Conn
+	route     string
+	request   *http.Request
+	principal *authx.Principal
+	keys      map[string]interface{}
+	cfg       settings
+
+	send     chan *websocket.PreparedMessage
+	incoming chan message
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	writerDone chan struct{}
+	closeOnce  sync.Once
+	errMux     sync.Mutex
+	err        error
+}
+
+type message struct {
+	typ  int
+	data []byte
+}
+
+type settings struct {
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	writeTimeout time.Duration
+	readLimit    int64
+	sendBuffer   int
+}
+
+func newConn(ws *websocket.Conn, cfg settings) *Conn {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Conn{
+		ws:         ws,
+		cfg:        cfg,
+		send:       make(chan *websocket.PreparedMessage, cfg.sendBuffer),
+		incoming:   make(chan message),
+		ctx:        ctx,
+		cancel:     cancel,
+		writerDone: make(chan struct{}),
+	}
+}
+
+// ID returns the unique ID of the connection
+func (c *Conn) ID() string {
+	return c.id
+}
+
+// Request returns the upgrade request
+func (c *Conn) Request() *http.Request {
+	return c.request
+}
+
+// Principal returns the principal authenticated in the upgrade request, or nil if anonymous
+func (c *Conn) Principal() *authx.Principal {
+	return c.principal
+}
+
+// Get returns the value of a key set in the context of the upgrade request, e.g. by a middleware
+func (c *Conn) Get(key string) (interface{}, bool) {
+	v, ok := c.keys[key]
+	return v, ok
+}
+
+// Subprotocol returns the negotiated subprotocol, `json` or `proto`, or empty if none was requested
+func (c *Conn) Subprotocol() string {
+	return c.ws.Subprotocol()
+}
+
+// Ctx returns a context which is cancelled when the connection is closed
+func (c *Conn) Ctx() context.Context {
+	return c.ctx
+}
+
+// Done returns a channel which is closed when the connection is closed
+func (c *Conn) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Err returns the reason the connection was closed by the client or lost, if any
+func (c *Conn) Err() error {
+	c.errMux.Lock()
+	defer c.errMux.Unlock()
+	return c.err
+}
+
+// Send queues a message to be sent, blocking while the send buffer is full
+func (c *Conn) Send(msg interface{}) error {
+	pm, err := prepare(msg, c.Subprotocol() == SubprotocolProto)
+	if err != nil {
+		return err
+	}
+	select {
+	case c.send <- pm:
+		return nil
+	case <-c.ctx.Done():
+		return ErrClosed
+	}
+}
+
+// Receive waits for the next message from the client and decodes it into `msg`
+func (c *Conn) Receive(msg interface{}) error {
+	select {
+	case m := <-c.incoming:
+		return decode(m, msg)
+	case <-c.ctx.Done():
+		if err := c.Err(); err != nil {
+			return err
+		}
+		return ErrClosed
+	}
+}
+
+// Close sends the pending messages and closes the connection with the provided close code and reason,
+// e.g. `websocket.CloseNormalClosure`
+func (c *Conn) Close(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.cancel()
+		<-c.writerDone
+		err = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(c.cfg.writeTimeout))
+		_ = c.ws.Close()
+	})
+	return err
+}
+
+// trySend queues a message without blocking, returns false if the send buffer is full
+func (c *Conn) trySend(pm *websocket.PreparedMessage) bool {
+	select {
+	case c.send <- pm:
+		return true
+	case <-c.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Conn) start() {
+	if c.cfg.readLimit > 0 {
+		c.ws.SetReadLimit(c.cfg.readLimit)
+	}
+	_ = c.extendReadDeadline()
+	c.ws.SetPongHandler(func(string) error {
+		return c.extendReadDeadline()
+	})
+	go c.readPump()
+	go c.writePump()
+}
+
+func (c *Conn) readPump() {
+	for {
+		typ, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		select {
+		case c.incoming <- message{typ: typ, data: data}:
+			// The deadline may have elapsed while the message was waiting to be received
+			_ = c.extendReadDeadline()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Conn) writePump() {
+	defer close(c.writerDone)
+	var ping <-chan time.Time
+	if c.cfg.pingInterval > 0 {
+		ticker := time.NewTicker(c.cfg.pingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+
+	for {
+		select {
+		case pm := <-c.send:
+			if err := c.write(pm); err != nil {
+				c.fail(err)
+				_ = c.ws.Close()
+				return
+			}
+		case <-ping:
+			if err := c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(c.cfg.writeTimeout)); err != nil {
+				c.fail(err)
+				_ = c.ws.Close()
+				return
+			}
+		case <-c.ctx.Done():
+			c.drain()
+			return
+		}
+	}
+}
+
+// extendReadDeadline extends the time to wait for a pong or message from the client
+func (c *Conn) extendReadDeadline() error {
+	if c.cfg.pongTimeout <= 0 {
+		return nil
+	}
+	return c.ws.SetReadDeadline(time.Now().Add(c.cfg.pongTimeout))
+}
+
+// drain writes the messages queued before the connection was closed
+func (c *Conn) drain() {
+	if c.Err() != nil {
+		return
+	}
+	for {
+		select {
+		case pm := <-c.send:
+			if err := c.write(pm); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (c *Conn) write(pm *websocket.PreparedMessage) error {
+	_ = c.ws.SetWriteDeadline(time.Now().Add(c.cfg.writeTimeout))
+	return c.ws.WritePreparedMessage(pm)
+}
+
+// fail records the reason the connection was lost and cancels it
+func (c *Conn) fail(err error) {
+	c.errMux.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.errMux.Unlock()
+	c.cancel()
+}
+
+// prepare encodes a message, as binary Protobuf if `binary` is set and the message is a Protobuf message,
+// or as JSON text otherwise
+func prepare(msg interface{}, binary bool) (*websocket.PreparedMessage, error) {
+	var (
+		typ  = websocket.TextMessage
+		data []byte
+		err  error
+	)
+	switch v := msg.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		typ, data = websocket.BinaryMessage, v
+	case proto.Message:
+		if binary {
+			typ = websocket.BinaryMessage
+			data, err = proto.Marshal(v)
+		} else {
+			data, err = protojson.Marshal(v)
+		}
+	default:
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return websocket.NewPreparedMessage(typ, data)
+}
+
+func decode(m message, msg interface{}) error {
+	switch v := msg.(type) {
+	case *string:
+		*v = string(m.data)
+		return nil
+	case *[]byte:
+		*v = m.data
+		return nil
+	case proto.Message:
+		if m.typ == websocket.BinaryMessage {
+			return proto.Unmarshal(m.data, v)
+		}
+		return protojson.Unmarshal(m.data, v)
+	}
+	return json.Unmarshal(m.data, msg)
+}
@@ -0,0 +1,89 @@
+package ws
+
+import (
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Hub is a set of connections to broadcast messages to. It is safe for concurrent use.
+type Hub struct {
+	conns map[*Conn]struct{}
+	mux   sync.RWMutex
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{conns: map[*Conn]struct{}{}}
+}
+
+// Join adds a connection to the hub. The connection leaves the hub when closed.
+func (h *Hub) Join(conn *Conn) {
+	h.mux.Lock()
+	h.conns[conn] = struct{}{}
+	h.mux.Unlock()
+
+	go func() {
+		<-conn.Done()
+		h.Leave(conn)
+	}()
+}
+
+// Leave removes a connection from the hub
+func (h *Hub) Leave(conn *Conn) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	delete(h.conns, conn)
+}
+
+// Len returns the number of connections in the hub
+func (h *Hub) Len() int {
+	h.mux.RLock()
+	defer h.mux.RUnlock()
+	return len(h.conns)
+}
+
+// Broadcast sends a message to all the connections in the hub, or only to those matching all the
+// provided filters. The message is encoded once per subprotocol, see `Conn.Send`.
+//
+// Broadcasting never blocks: connections whose send buffer is full are considered slow consumers and are
+// closed with `1013 Try Again Later`.
+func (h *Hub) Broadcast(msg interface{}, filter ...func(conn *Conn) bool) error {
+	h.mux.RLock()
+	conns := make([]*Conn, 0, len(h.conns))
+	for conn := range h.conns {
+		if matches(conn, filter) {
+			conns = append(conns, conn)
+		}
+	}
+	h.mux.RUnlock()
+
+	prepared := map[bool]*websocket.PreparedMessage{}
+	for _, conn := range conns {
+		binary := conn.Subprotocol() == SubprotocolProto
+		pm, ok := prepared[binary]
+		if !ok {
+			var err error
+			if pm, err = prepare(msg, binary); err != nil {
+				return err
+			}
+			prepared[binary] = pm
+		}
+		if !conn.trySend(pm) {
+			h.Leave(conn)
+			go func(conn *Conn) {
+				_ = conn.Close(websocket.CloseTryAgainLater, "slow consumer")
+			}(conn)
+		}
+	}
+	return nil
+}
+
+func matches(conn *Conn, filter []func(conn *Conn) bool) bool {
+	for _, f := range filter {
+		if !f(conn) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,17 @@
+package ws
+
+import "github.com/jucardi/go-titan/components/prometheus"
+
+var (
+	connectionsGauge = prometheus.Gauge(prometheus.Opts{
+		Name:   "websocket_connections",
+		Help:   "Number of open WebSocket connections",
+		Labels: []string{"route"},
+	})
+
+	connectionsCounter = prometheus.Counter(prometheus.Opts{
+		Name:   "websocket_connections_total",
+		Help:   "Total number of accepted WebSocket connections",
+		Labels: []string{"route"},
+	})
+)
@@ -0,0 +1,119 @@
+package ws
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/jucardi/go-titan/logx"
+	"github.com/jucardi/go-titan/net/errorx"
+	"github.com/jucardi/go-titan/net/rest"
+	"github.com/jucardi/go-titan/net/rest/config"
+)
+
+const (
+	// SubprotocolJson is the subprotocol of connections exchanging JSON text messages, the default
+	SubprotocolJson = "json"
+
+	// SubprotocolProto is the subprotocol of connections exchanging binary Protobuf messages
+	SubprotocolProto = "proto"
+
+	headerOrigin = "Origin"
+)
+
+// Handler handles an upgraded WebSocket connection. The connection is closed with a normal closure when
+// the handler returns, sending the pending messages first.
+type Handler func(conn *Conn)
+
+// Upgrade creates a handler function that upgrades the request to a WebSocket connection (RFC 6455) and
+// invokes `handler` with it, using the `websocket` configuration. Requests which are not valid upgrade
+// requests, or from an origin not allowed, are rejected before invoking `handler`.
+//
+// Middlewares registered before it run on the upgrade request, so the authenticated principal, the
+// correlation ID and any value set in the context are available through the connection. The connection
+// is not bound to the request deadline set by the timeout middleware.
+//
+//	r.GET("/orders/feed", ws.Upgrade(func(conn *ws.Conn) {
+//		hub.Join(conn)
+//		for {
+//			msg := &pb.Subscribe{}
+//			if err := conn.Receive(msg); err != nil {
+//				return
+//			}
+//			...
+//		}
+//	}))
+func Upgrade(handler Handler) func(c *rest.Context) {
+	return func(c *rest.Context) {
+		cfg := config.Rest().WebSocket
+		upgrader := &websocket.Upgrader{
+			Subprotocols:      []string{SubprotocolJson, SubprotocolProto},
+			EnableCompression: cfg.EnableCompression,
+			CheckOrigin:       checkOrigin(cfg.AllowedOrigins),
+			Error: func(_ http.ResponseWriter, _ *http.Request, status int, reason error) {
+				c.SendError(errorx.New(status, http.StatusText(status), reason.Error()))
+			},
+		}
+
+		ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			c.Abort()
+			return
+		}
+
+		conn := newConn(ws, settings{
+			pingInterval: time.Duration(cfg.PingInterval) * time.Millisecond,
+			pongTimeout:  time.Duration(cfg.PongTimeout) * time.Millisecond,
+			writeTimeout: time.Duration(cfg.WriteTimeout) * time.Millisecond,
+			readLimit:    cfg.MaxMessageSize,
+			sendBuffer:   cfg.SendBuffer,
+		})
+		conn.id = uuid.New().String()
+		conn.route = c.FullPath()
+		conn.request = c.Request
+		conn.principal = c.Principal()
+		conn.keys = make(map[string]interface{}, len(c.Keys))
+		for k, v := range c.Keys {
+			conn.keys[k] = v
+		}
+
+		connectionsGauge.Inc(conn.route)
+		connectionsCounter.Inc(conn.route)
+		defer connectionsGauge.Dec(conn.route)
+
+		conn.start()
+		defer func() {
+			if err := conn.Close(websocket.CloseNormalClosure, ""); err != nil && conn.Err() == nil {
+				logx.Debug("failed to close websocket connection, ", err.Error())
+			}
+		}()
+		handler(conn)
+		c.Abort()
+	}
+}
+
+// checkOrigin returns the origin check of the provided allowed origins, or the default same-origin check
+// if none
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return nil
+	}
+	return func(r *http.Request) bool {
+		origin := strings.ToLower(r.Header.Get(headerOrigin))
+		if origin == "" {
+			return true
+		}
+		for _, pattern := range allowed {
+			if pattern == "*" {
+				return true
+			}
+			if ok, _ := path.Match(strings.ToLower(pattern), origin); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
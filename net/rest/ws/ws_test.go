@@ -0,0 +1,98 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/jucardi/go-testx/assert"
+	"github.com/jucardi/go-titan/net/rest"
+)
+
+type testMessage struct {
+	Text string `json:"text"`
+}
+
+func TestUpgradeEcho(t *testing.T) {
+	server := createServer(func(conn *Conn) {
+		assert.Equal(t, SubprotocolJson, conn.Subprotocol())
+		assert.Equal(t, "value", conn.keys["key"])
+		msg := &testMessage{}
+		for conn.Receive(msg) == nil {
+			_ = conn.Send(&testMessage{Text: "echo " + msg.Text})
+		}
+	})
+	defer server.Close()
+
+	client := dial(t, server, SubprotocolJson)
+	defer client.Close()
+
+	assert.NoError(t, client.WriteJSON(&testMessage{Text: "hello"}))
+	msg := &testMessage{}
+	assert.NoError(t, client.ReadJSON(msg))
+	assert.Equal(t, "echo hello", msg.Text)
+}
+
+func TestUpgradeRejected(t *testing.T) {
+	server := createServer(func(conn *Conn) {})
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/ws")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestHubBroadcast(t *testing.T) {
+	hub := NewHub()
+	server := createServer(func(conn *Conn) {
+		hub.Join(conn)
+		_ = conn.Receive(new(string))
+	})
+	defer server.Close()
+
+	first, second := dial(t, server), dial(t, server)
+	defer first.Close()
+	defer second.Close()
+
+	for i := 0; i < 100 && hub.Len() < 2; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, 2, hub.Len())
+	assert.NoError(t, hub.Broadcast("news"))
+
+	for _, client := range []*websocket.Conn{first, second} {
+		_, data, err := client.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, "news", string(data))
+	}
+
+	// The connection leaves the hub when the handler returns
+	assert.NoError(t, first.WriteMessage(websocket.TextMessage, []byte("bye")))
+	_, _, err := first.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+	for i := 0; i < 100 && hub.Len() > 1; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, 1, hub.Len())
+}
+
+func createServer(handler Handler) *httptest.Server {
+	upgrade := Upgrade(handler)
+	router := gin.New()
+	router.GET("/ws", func(c *gin.Context) {
+		c.Set("key", "value")
+		upgrade(rest.NewContext(c, false))
+	})
+	return httptest.NewServer(router)
+}
+
+func dial(t *testing.T, server *httptest.Server, subprotocols ...string) *websocket.Conn {
+	dialer := &websocket.Dialer{Subprotocols: subprotocols}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	assert.NoError(t, err)
+	return conn
+}
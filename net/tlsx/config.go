@@ -0,0 +1,47 @@
+package tlsx
+
+// Client authentication policies of `Config.ClientAuth`
+const (
+	ClientAuthNone          = "none"
+	ClientAuthRequest       = "request"
+	ClientAuthRequireAny    = "require_any"
+	ClientAuthVerifyIfGiven = "verify_if_given"
+	ClientAuthRequire       = "require"
+)
+
+// Config is the TLS configuration of a listener
+type Config struct {
+	// Enabled indicates whether the listener serves TLS
+	Enabled bool `json:"enabled" yaml:"enabled"`
+
+	// CertFile is the path to the PEM encoded certificate chain of the server
+	CertFile string `json:"cert_file,omitempty" yaml:"cert_file,omitempty"`
+
+	// KeyFile is the path to the PEM encoded private key of the server
+	KeyFile string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+
+	// ClientCAFile is the path to the PEM encoded CA certificates used to verify client certificates (mTLS)
+	ClientCAFile string `json:"client_ca_file,omitempty" yaml:"client_ca_file,omitempty"`
+
+	// ClientAuth is the client certificate policy:
+	//  - `none`:            client certificates are not requested.
+	//  - `request`:         client certificates are requested but not required nor verified.
+	//  - `require_any`:     a client certificate is required but not verified.
+	//  - `verify_if_given`: client certificates are verified against the client CA if sent.
+	//  - `require`:         a client certificate verified against the client CA is required.
+	// Default is `require` if a client CA is configured, otherwise `none`
+	ClientAuth string `json:"client_auth,omitempty" yaml:"client_auth,omitempty"`
+
+	// MinVersion is the minimum TLS version accepted: `1.0`, `1.1`, `1.2` or `1.3`. Default is `1.2`
+	MinVersion string `json:"min_version,omitempty" yaml:"min_version,omitempty"`
+
+	// CipherSuites restricts the cipher suites for TLS 1.2 and lower, by their standard names, e.g.
+	// `TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256`. TLS 1.3 suites are not configurable. Default is the Go
+	// secure defaults
+	CipherSuites []string `json:"cipher_suites,omitempty" yaml:"cipher_suites,omitempty"`
+
+	// ReloadInterval is the time in milliseconds between checks for changes of the certificate, key and
+	// client CA files, which are reloaded without restarting the listener. Negative disables the reload.
+	// Default is 10 seconds
+	ReloadInterval int64 `json:"reload_interval" yaml:"reload_interval" default:"10000"`
+}
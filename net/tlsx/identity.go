@@ -0,0 +1,66 @@
+package tlsx
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+)
+
+// Identity is the identity of a peer given by its verified certificate
+type Identity struct {
+	// Subject identifies the peer: the first URI SAN (e.g. a SPIFFE ID) if any, otherwise the common name,
+	// otherwise the first DNS SAN
+	Subject string `json:"subject"`
+
+	CommonName   string   `json:"common_name,omitempty"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	URIs         []string `json:"uris,omitempty"`
+	Emails       []string `json:"emails,omitempty"`
+	Issuer       string   `json:"issuer,omitempty"`
+	SerialNumber string   `json:"serial_number,omitempty"`
+
+	// Fingerprint is the hex encoded SHA-256 hash of the certificate
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Certificate is the peer certificate
+	Certificate *x509.Certificate `json:"-"`
+}
+
+// PeerIdentity returns the identity of the verified peer certificate of a connection, or nil if the peer
+// did not present a certificate or it was not verified
+func PeerIdentity(state *tls.ConnectionState) *Identity {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return IdentityOf(state.VerifiedChains[0][0])
+}
+
+// IdentityOf returns the identity of the provided certificate
+func IdentityOf(cert *x509.Certificate) *Identity {
+	sum := sha256.Sum256(cert.Raw)
+	ret := &Identity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		Emails:       cert.EmailAddresses,
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+		Certificate:  cert,
+	}
+	for _, u := range cert.URIs {
+		ret.URIs = append(ret.URIs, u.String())
+	}
+
+	switch {
+	case len(ret.URIs) > 0:
+		ret.Subject = ret.URIs[0]
+	case ret.CommonName != "":
+		ret.Subject = ret.CommonName
+	case len(ret.DNSNames) > 0:
+		ret.Subject = ret.DNSNames[0]
+	}
+	return ret
+}
@@ -0,0 +1,221 @@
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jucardi/go-titan/logx"
+)
+
+const defaultReloadInterval = 10 * time.Second
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Loader loads the certificates of a TLS configuration and reloads them when the files change on disk
+type Loader struct {
+	cfg        Config
+	minVersion uint16
+	ciphers    []uint16
+	clientAuth tls.ClientAuthType
+	interval   time.Duration
+
+	current   *tls.Config
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+	mux       sync.Mutex
+}
+
+// New creates a Loader for the provided configuration, loading the certificates immediately
+func New(cfg Config) (*Loader, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("tls requires both a certificate and a key file")
+	}
+
+	ret := &Loader{cfg: cfg, interval: time.Duration(cfg.ReloadInterval) * time.Millisecond}
+	if cfg.ReloadInterval == 0 {
+		ret.interval = defaultReloadInterval
+	}
+
+	var err error
+	if ret.minVersion, err = parseVersion(cfg.MinVersion); err != nil {
+		return nil, err
+	}
+	if ret.ciphers, err = parseCiphers(cfg.CipherSuites); err != nil {
+		return nil, err
+	}
+	if ret.clientAuth, err = parseClientAuth(cfg.ClientAuth, cfg.ClientCAFile != ""); err != nil {
+		return nil, err
+	}
+	if err := ret.load(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// TLSConfig returns the TLS configuration to be used by a server. The certificates of new connections
+// are the most recently loaded ones.
+func (l *Loader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: l.minVersion,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &l.config().Certificates[0], nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return l.config(), nil
+		},
+	}
+}
+
+// config returns the current configuration, reloading the files first if the reload interval elapsed
+func (l *Loader) config() *tls.Config {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.interval > 0 && time.Since(l.lastCheck) >= l.interval {
+		l.lastCheck = time.Now()
+		if l.changed() {
+			if err := l.reload(); err != nil {
+				logx.Error("failed to reload the TLS certificates, keeping the previous ones, ", err.Error())
+			} else {
+				logx.Info("TLS certificates reloaded")
+			}
+		}
+	}
+	return l.current
+}
+
+func (l *Loader) load() error {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.lastCheck = time.Now()
+	return l.reload()
+}
+
+func (l *Loader) reload() error {
+	modTimes := map[string]time.Time{}
+	for _, f := range l.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	cert, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load the certificate, %v", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   l.minVersion,
+		CipherSuites: l.ciphers,
+		ClientAuth:   l.clientAuth,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	if l.cfg.ClientCAFile != "" {
+		data, err := ioutil.ReadFile(l.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the client CA file, %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no valid certificates found in the client CA file %s", l.cfg.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+	}
+
+	l.current = cfg
+	l.modTimes = modTimes
+	return nil
+}
+
+// changed indicates whether any of the files was modified since they were loaded
+func (l *Loader) changed() bool {
+	for _, f := range l.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(l.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Loader) files() []string {
+	ret := []string{l.cfg.CertFile, l.cfg.KeyFile}
+	if l.cfg.ClientCAFile != "" {
+		ret = append(ret, l.cfg.ClientCAFile)
+	}
+	return ret
+}
+
+func parseVersion(v string) (uint16, error) {
+	if v == "" {
+		return tls.VersionTLS12, nil
+	}
+	if ret, ok := versions[strings.TrimPrefix(strings.ToLower(v), "tls")]; ok {
+		return ret, nil
+	}
+	return 0, fmt.Errorf("unsupported tls version '%s'", v)
+}
+
+func parseCiphers(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	suites := map[string]uint16{}
+	for _, s := range tls.CipherSuites() {
+		suites[s.Name] = s.ID
+	}
+	var ret []uint16
+	for _, name := range names {
+		id, ok := suites[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported or insecure cipher suite '%s'", name)
+		}
+		ret = append(ret, id)
+	}
+	return ret, nil
+}
+
+func parseClientAuth(v string, hasCA bool) (tls.ClientAuthType, error) {
+	switch v {
+	case "":
+		if hasCA {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case ClientAuthRequireAny:
+		return tls.RequireAnyClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		if !hasCA {
+			return 0, fmt.Errorf("client auth '%s' requires a client CA file", v)
+		}
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequire:
+		if !hasCA {
+			return 0, fmt.Errorf("client auth '%s' requires a client CA file", v)
+		}
+		return tls.RequireAndVerifyClientCert, nil
+	}
+	return 0, fmt.Errorf("unsupported client auth '%s'", v)
+}
@@ -0,0 +1,142 @@
+package tlsx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jucardi/go-testx/assert"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newCert(t *testing.T, serial int64, subject string, parent *testCert, client bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: subject},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		if client {
+			spiffe, _ := url.Parse("spiffe://example.org/" + subject)
+			tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+			tmpl.URIs = []*url.URL{spiffe}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	assert.NoError(t, err)
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	assert.NoError(t, ioutil.WriteFile(certFile, c.pem, 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+func TestMutualTLSAndReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tlsx")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newCert(t, 1, "test-ca", nil, false)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newCert(t, 2, "server", ca, false).write(t, dir, "server")
+	client := newCert(t, 3, "orders", ca, true)
+
+	loader, err := New(Config{
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ClientCAFile:   caFile,
+		MinVersion:     "1.2",
+		ReloadInterval: 1,
+	})
+	assert.NoError(t, err)
+
+	var identity *Identity
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity = PeerIdentity(r.TLS)
+	}))
+	srv.TLS = loader.TLSConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	httpClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certs}}}
+	}
+
+	res, err := httpClient(client.tlsCert()).Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "spiffe://example.org/orders", identity.Subject)
+	assert.Equal(t, "orders", identity.CommonName)
+	assert.Equal(t, 2, int(res.TLS.PeerCertificates[0].SerialNumber.Int64()))
+
+	// A client certificate is required
+	_, err = httpClient().Get(srv.URL)
+	assert.Error(t, err)
+
+	// The rotated certificate is served without restarting the listener
+	time.Sleep(10 * time.Millisecond)
+	newCert(t, 4, "server", ca, false).write(t, dir, "server")
+	future := time.Now().Add(time.Second)
+	assert.NoError(t, os.Chtimes(certFile, future, future))
+
+	res, err = httpClient(client.tlsCert()).Get(srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, int(res.TLS.PeerCertificates[0].SerialNumber.Int64()))
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+
+	_, err = parseVersion("1.4")
+	assert.Error(t, err)
+
+	_, err = parseCiphers([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.Error(t, err)
+
+	_, err = parseClientAuth(ClientAuthRequire, false)
+	assert.Error(t, err)
+}
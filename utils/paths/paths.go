@@ -68,3 +68,20 @@ func addDir(dir string) {
 	}
 	created = append(created, dir)
 }
+
+// MatchPrefix returns the longest of the provided prefixes that matches the beginning of the provided
+// path on a segment boundary, so "/v1/users" matches "/v1/users/123" but not "/v1/usersx". Returns
+// false if none of the prefixes matches.
+func MatchPrefix(p string, prefixes ...string) (string, bool) {
+	match, found := "", false
+	for _, prefix := range prefixes {
+		trimmed := strings.TrimSuffix(prefix, "/")
+		if trimmed != "" && p != trimmed && !strings.HasPrefix(p, trimmed+"/") {
+			continue
+		}
+		if !found || len(prefix) > len(match) {
+			match, found = prefix, true
+		}
+	}
+	return match, found
+}
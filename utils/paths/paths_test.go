@@ -29,3 +29,18 @@ func TestDoesNotExists(t *testing.T) {
 	assert.Nil(t, err)
 	assert.False(t, exists)
 }
+
+func TestMatchPrefix(t *testing.T) {
+	prefixes := []string{"/", "/v1", "/v1/users/"}
+
+	match, ok := MatchPrefix("/v1/users/123", prefixes...)
+	assert.True(t, ok)
+	assert.Equal(t, "/v1/users/", match)
+
+	match, ok = MatchPrefix("/v1/usersx", prefixes...)
+	assert.True(t, ok)
+	assert.Equal(t, "/v1", match)
+
+	_, ok = MatchPrefix("/v2/users", "/v1")
+	assert.False(t, ok)
+}